@@ -38,6 +38,16 @@ type CertManagerDeploymentSpec struct {
 	// for by the operator, but can be configured in edge cases if needed.
 	// +optional
 	DangerZone DangerZone `json:"dangerZone,omitempty"`
+
+	// PodRefresher configures the pod refresher, which refreshes workloads that have opted in when
+	// the certificates they use change. The pod refresher is stopped if omitted, unless the operator
+	// was started with it enabled.
+	// +optional
+	PodRefresher *PodRefresherConfig `json:"podRefresher,omitempty"`
+
+	// CertificateHealth configures the summary of the health of cert-manager resources in the status.
+	// +optional
+	CertificateHealth CertificateHealthConfig `json:"certificateHealth,omitempty"`
 }
 
 // CertManagerDeploymentStatus defines the observed state of CertManagerDeployment
@@ -54,6 +64,19 @@ type CertManagerDeploymentStatus struct {
 	DeploymentConditions []ManagedDeploymentWithConditions `json:"deploymentConditions,omitEmpty"`
 	// CRDConditions is a report of conditions on owned CRDs by this CertManagerDeployment.
 	CRDConditions []ManagedCRDWithConditions `json:"crdConditions,omitEmpty"`
+	// OverrideNotices lists container argument overrides that were rewritten, ignored, or that
+	// use deprecated flags for the requested version of cert-manager.
+	// +optional
+	OverrideNotices []ContainerArgOverrideNotice `json:"overrideNotices,omitempty"`
+	// PatchStatuses reports the result of each patch in spec.dangerZone.patches.
+	// +optional
+	PatchStatuses []ObjectPatchStatus `json:"patchStatuses,omitempty"`
+
+	// CertificateHealth summarizes the health of the Certificates, Issuers, ClusterIssuers and
+	// CertificateRequests across the cluster. It is omitted if they could not be read, such as
+	// before the CRDs are established. It is refreshed every five minutes.
+	// +optional
+	CertificateHealth *CertificateHealth `json:"certificateHealth,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -62,6 +85,12 @@ type CertManagerDeploymentStatus struct {
 // +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.version`
 // +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Certs Ready",type=integer,JSONPath=`.status.certificateHealth.certificatesReady`
+// +kubebuilder:printcolumn:name="Certs Not Ready",type=integer,JSONPath=`.status.certificateHealth.certificatesNotReady`
+// +kubebuilder:printcolumn:name="Certs Expiring",type=integer,JSONPath=`.status.certificateHealth.certificatesExpiring`
+// +kubebuilder:printcolumn:name="Failed Requests",type=integer,JSONPath=`.status.certificateHealth.certificateRequestsFailed`
+// +kubebuilder:printcolumn:name="Issuers Not Ready",type=integer,JSONPath=`.status.certificateHealth.issuersNotReady`,priority=1
+// +kubebuilder:printcolumn:name="ClusterIssuers Not Ready",type=integer,JSONPath=`.status.certificateHealth.clusterIssuersNotReady`,priority=1
 // +operator-sdk:csv:customresourcedefinitions:displayName="Cert-Manager Deployment"
 // +operator-sdk:csv:customresourcedefinitions:resources={{CustomResourceDefinition,v1,certificates.cert-manager.io},{CustomResourceDefinition,v1,clusterissuers.cert-manager.io},{CustomResourceDefinition,v1,issuers.cert-manager.io},{CustomResourceDefinition,v1,certificaterequests.cert-manager.io},{CustomResourceDefinition,v1,orders.acme.cert-manager.io},{CustomResourceDefinition,v1,challenges.acme.cert-manager.io}}
 
@@ -125,6 +154,49 @@ const (
 	ConditionDeploymentsAreReady CertManagerDeploymentConditionType = "DeploymentsAreReady"
 )
 
+// CertificateHealthConfig configures the summary of the health of cert-manager resources.
+type CertificateHealthConfig struct {
+	// ExpiringWithinDays is how many days before they expire Certificates are counted as expiring.
+	// Defaults to 14.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ExpiringWithinDays int `json:"expiringWithinDays,omitempty"`
+}
+
+// CertificateHealth counts the cert-manager resources in the cluster by their health.
+type CertificateHealth struct {
+	// CertificatesReady is the number of Certificates whose Ready condition is True.
+	CertificatesReady int `json:"certificatesReady"`
+	// CertificatesNotReady is the number of Certificates whose Ready condition is not True.
+	CertificatesNotReady int `json:"certificatesNotReady"`
+	// CertificatesExpiring is the number of Certificates that expire within spec.certificateHealth.expiringWithinDays,
+	// including those that have expired.
+	CertificatesExpiring int `json:"certificatesExpiring"`
+	// IssuersNotReady is the number of Issuers whose Ready condition is not True.
+	IssuersNotReady int `json:"issuersNotReady"`
+	// ClusterIssuersNotReady is the number of ClusterIssuers whose Ready condition is not True.
+	ClusterIssuersNotReady int `json:"clusterIssuersNotReady"`
+	// CertificateRequestsFailed is the number of CertificateRequests that failed or were denied.
+	CertificateRequestsFailed int `json:"certificateRequestsFailed"`
+	// LastUpdateTime is when the counts last changed.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+}
+
+// PodRefresherConfig configures the pod refresher run by the operator.
+type PodRefresherConfig struct {
+	// Enabled runs the pod refresher.
+	Enabled bool `json:"enabled"`
+	// OptInAnnotation is an annotation that opts workloads and namespaces in to refreshes when set
+	// to true, in addition to certmanagerdeployment.redhat.io/allow-restart. It takes precedence
+	// over certmanagerdeployment.redhat.io/allow-restart when a workload has both.
+	// +optional
+	OptInAnnotation string `json:"optInAnnotation,omitempty"`
+	// Strategy is how workloads are refreshed, unless set by the workload, the PodRefreshPolicy
+	// selecting it, or its namespace. Defaults to Restart.
+	// +optional
+	Strategy PodRefreshStrategyType `json:"strategy,omitempty"`
+}
+
 // DangerZone is a set of configuration options that may cause the stability
 // or reliability of the controller to break, but are exposed in case they
 // need to be tweaked.
@@ -143,6 +215,50 @@ type DangerZone struct {
 	// configured for each component.
 	// +optional
 	ContainerArgOverrides ContainerArgOverrides `json:"containerArgOverrides,omitempty"`
+	// Patches is a list of patches applied to the objects managed by the operator after they
+	// have been rendered, and before they are compared with what exists in the cluster. Patches
+	// are applied in order. A patch that cannot be applied to an object is skipped for that object
+	// and reported in the status.
+	// +optional
+	Patches []ObjectPatch `json:"patches,omitempty"`
+}
+
+// ObjectPatchType is the format of an ObjectPatch.
+// +kubebuilder:validation:Enum=StrategicMerge;JSON6902
+type ObjectPatchType string
+
+const (
+	// StrategicMergePatchType is a Kubernetes strategic merge patch.
+	StrategicMergePatchType ObjectPatchType = "StrategicMerge"
+	// JSON6902PatchType is a list of JSON patch operations as defined by RFC 6902.
+	JSON6902PatchType ObjectPatchType = "JSON6902"
+)
+
+// ObjectPatch is a patch applied to the managed objects matching its target.
+type ObjectPatch struct {
+	// Name identifies this patch in the status of the CertManagerDeployment.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Target selects the managed objects this patch is applied to.
+	Target ObjectPatchTarget `json:"target"`
+	// Type is the format of the patch, either StrategicMerge or JSON6902.
+	Type ObjectPatchType `json:"type"`
+	// Patch is the patch content, in YAML or JSON. Strategic merge patches are a partial object
+	// and JSON6902 patches are a list of operations.
+	Patch string `json:"patch"`
+}
+
+// ObjectPatchTarget selects managed objects by kind, and optionally by name and namespace.
+type ObjectPatchTarget struct {
+	// Kind is the kind of the managed objects to patch, e.g. Deployment or Service.
+	Kind string `json:"kind"`
+	// Name is the name of the managed object to patch. All objects of the kind are patched if omitted.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Namespace is the namespace of the managed objects to patch. Objects in any namespace are patched
+	// if omitted.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 type ContainerArgOverrides struct {
@@ -191,3 +307,32 @@ type ManagedDeploymentWithConditions struct {
 	// Conditions is the DeploymentConditions associated with that deployment.
 	Conditions []appsv1.DeploymentCondition `json:"conditions"`
 }
+
+// ContainerArgOverrideNotice describes a container argument override that was affected
+// by a flag being renamed, deprecated, or removed in the requested version of cert-manager.
+type ContainerArgOverrideNotice struct {
+	// Component is the name of the component whose override was affected.
+	Component string `json:"component"`
+	// Flag is the name of the overridden flag as it was provided.
+	Flag string `json:"flag"`
+	// Type is the type of change to the flag, one of Renamed, Deprecated, Removed, or RenameConflict.
+	// RenameConflict means both the old and the new name of a renamed flag were overridden.
+	Type string `json:"type"`
+	// Message is a human readable explanation of how the override was handled.
+	Message string `json:"message"`
+}
+
+// ObjectPatchStatus is the result of applying an ObjectPatch to the managed objects.
+type ObjectPatchStatus struct {
+	// Name is the name of the patch.
+	Name string `json:"name"`
+	// Applied is true if the patch matched at least one object and was applied to all
+	// matching objects without error.
+	Applied bool `json:"applied"`
+	// PatchedObjects lists the objects the patch was applied to, in the format Kind:namespace/name.
+	// +optional
+	PatchedObjects []string `json:"patchedObjects,omitempty"`
+	// Message is a human readable explanation of why the patch was not applied.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
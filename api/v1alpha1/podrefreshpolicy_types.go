@@ -0,0 +1,155 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PodRefreshPolicySpec defines the desired state of PodRefreshPolicy
+type PodRefreshPolicySpec struct {
+	// WorkloadSelector selects the workloads in the namespace that are refreshed when a
+	// selected secret changes. An empty selector selects all workloads in the namespace.
+	// +optional
+	WorkloadSelector metav1.LabelSelector `json:"workloadSelector,omitempty"`
+	// SecretSelector selects the secrets in the namespace that cause selected workloads to be
+	// refreshed when they change. An empty selector selects all secrets issued by cert-manager.
+	// A selector that is not empty also selects secrets that are not issued by cert-manager.
+	// +optional
+	SecretSelector metav1.LabelSelector `json:"secretSelector,omitempty"`
+	// ConfigMapSelector selects the ConfigMaps in the namespace, such as CA trust bundles, that
+	// cause selected workloads to be refreshed when they change. No ConfigMaps are selected if omitted.
+	// +optional
+	ConfigMapSelector *metav1.LabelSelector `json:"configMapSelector,omitempty"`
+	// Strategy is how selected workloads are refreshed.
+	// +optional
+	Strategy PodRefreshStrategy `json:"strategy,omitempty"`
+	// Window limits refreshes to recurring windows of time. Workloads can be refreshed at any
+	// time if omitted.
+	// +optional
+	Window *PodRefreshWindow `json:"window,omitempty"`
+	// Debounce is how long to wait after a secret changes before refreshing the workloads
+	// using it, so that secrets changing together cause a single refresh.
+	// +optional
+	Debounce *metav1.Duration `json:"debounce,omitempty"`
+	// MaxConcurrentRestarts is the maximum number of rollouts of workloads in the namespace
+	// that can be in progress at once. The operator's limits are used if omitted.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentRestarts *int32 `json:"maxConcurrentRestarts,omitempty"`
+	// Audit reports the workloads that would be refreshed with events and metrics, without
+	// refreshing them.
+	// +optional
+	Audit bool `json:"audit,omitempty"`
+}
+
+// PodRefreshStrategyType is how workloads are refreshed.
+// +kubebuilder:validation:Enum=Restart;HTTPReload;PodAnnotation
+type PodRefreshStrategyType string
+
+const (
+	// RestartPodRefreshStrategyType rolls out new pods for the workload.
+	RestartPodRefreshStrategyType PodRefreshStrategyType = "Restart"
+	// HTTPReloadPodRefreshStrategyType sends an HTTP POST to the reload endpoint annotated on each
+	// pod of the workload, and restarts the workload if any pod fails to reload.
+	HTTPReloadPodRefreshStrategyType PodRefreshStrategyType = "HTTPReload"
+	// PodAnnotationPodRefreshStrategyType updates an annotation on each pod of the workload so that
+	// reloaders watching the pod pick up the change, and restarts the workload if any pod fails
+	// to be updated.
+	PodAnnotationPodRefreshStrategyType PodRefreshStrategyType = "PodAnnotation"
+)
+
+// PodRefreshTrigger selects which certificate changes refresh a workload.
+// +kubebuilder:validation:Enum=Any;Leaf;CA
+type PodRefreshTrigger string
+
+const (
+	// AnyPodRefreshTrigger refreshes workloads when the certificate, its key, or the CA certificate changes.
+	AnyPodRefreshTrigger PodRefreshTrigger = "Any"
+	// LeafPodRefreshTrigger refreshes workloads when the certificate or its key changes.
+	LeafPodRefreshTrigger PodRefreshTrigger = "Leaf"
+	// CAPodRefreshTrigger refreshes workloads when the CA certificate changes. Secrets without a
+	// CA certificate never refresh them.
+	CAPodRefreshTrigger PodRefreshTrigger = "CA"
+)
+
+// PodRefreshStrategy is how and when workloads are refreshed.
+type PodRefreshStrategy struct {
+	// Type is how workloads are refreshed. Defaults to Restart.
+	// +optional
+	Type PodRefreshStrategyType `json:"type,omitempty"`
+	// RefreshOn selects which certificate changes refresh workloads. Defaults to Any.
+	// +optional
+	RefreshOn PodRefreshTrigger `json:"refreshOn,omitempty"`
+}
+
+// PodRefreshWindow is a recurring window of time in which workloads can be refreshed.
+type PodRefreshWindow struct {
+	// Schedule is a cron expression for the start of each window, e.g. "0 2 * * *".
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+	// Duration is how long each window lasts. Defaults to 1h.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+}
+
+// PodRefreshPolicyStatus defines the observed state of PodRefreshPolicy
+type PodRefreshPolicyStatus struct {
+	// ObservedGeneration is the generation of the policy the status was computed for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Workloads lists the workloads selected by the policy.
+	// +optional
+	Workloads []PodRefreshPolicyWorkload `json:"workloads,omitempty"`
+}
+
+// PodRefreshPolicyWorkload is a workload selected by a PodRefreshPolicy.
+type PodRefreshPolicyWorkload struct {
+	// Kind is the kind of the workload.
+	Kind string `json:"kind"`
+	// Name is the name of the workload.
+	Name string `json:"name"`
+	// LastRefreshed is when the workload was last refreshed by the operator.
+	// +optional
+	LastRefreshed *metav1.Time `json:"lastRefreshed,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +operator-sdk:csv:customresourcedefinitions:displayName="Pod Refresh Policy"
+
+// PodRefreshPolicy is the Schema for the podrefreshpolicies API
+type PodRefreshPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PodRefreshPolicySpec   `json:"spec,omitempty"`
+	Status PodRefreshPolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PodRefreshPolicyList contains a list of PodRefreshPolicy
+type PodRefreshPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PodRefreshPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PodRefreshPolicy{}, &PodRefreshPolicyList{})
+}
@@ -23,6 +23,7 @@ package v1alpha1
 import (
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -110,6 +111,12 @@ func (in *CertManagerDeploymentSpec) DeepCopyInto(out *CertManagerDeploymentSpec
 		**out = **in
 	}
 	in.DangerZone.DeepCopyInto(&out.DangerZone)
+	if in.PodRefresher != nil {
+		in, out := &in.PodRefresher, &out.PodRefresher
+		*out = new(PodRefresherConfig)
+		**out = **in
+	}
+	out.CertificateHealth = in.CertificateHealth
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerDeploymentSpec.
@@ -146,6 +153,23 @@ func (in *CertManagerDeploymentStatus) DeepCopyInto(out *CertManagerDeploymentSt
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.OverrideNotices != nil {
+		in, out := &in.OverrideNotices, &out.OverrideNotices
+		*out = make([]ContainerArgOverrideNotice, len(*in))
+		copy(*out, *in)
+	}
+	if in.PatchStatuses != nil {
+		in, out := &in.PatchStatuses, &out.PatchStatuses
+		*out = make([]ObjectPatchStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CertificateHealth != nil {
+		in, out := &in.CertificateHealth, &out.CertificateHealth
+		*out = new(CertificateHealth)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerDeploymentStatus.
@@ -158,6 +182,52 @@ func (in *CertManagerDeploymentStatus) DeepCopy() *CertManagerDeploymentStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateHealth) DeepCopyInto(out *CertificateHealth) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateHealth.
+func (in *CertificateHealth) DeepCopy() *CertificateHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateHealthConfig) DeepCopyInto(out *CertificateHealthConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateHealthConfig.
+func (in *CertificateHealthConfig) DeepCopy() *CertificateHealthConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateHealthConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerArgOverrideNotice) DeepCopyInto(out *ContainerArgOverrideNotice) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerArgOverrideNotice.
+func (in *ContainerArgOverrideNotice) DeepCopy() *ContainerArgOverrideNotice {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerArgOverrideNotice)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContainerArgOverrides) DeepCopyInto(out *ContainerArgOverrides) {
 	*out = *in
@@ -187,6 +257,11 @@ func (in *DangerZone) DeepCopyInto(out *DangerZone) {
 		}
 	}
 	in.ContainerArgOverrides.DeepCopyInto(&out.ContainerArgOverrides)
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]ObjectPatch, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DangerZone.
@@ -242,3 +317,242 @@ func (in *ManagedDeploymentWithConditions) DeepCopy() *ManagedDeploymentWithCond
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectPatch) DeepCopyInto(out *ObjectPatch) {
+	*out = *in
+	out.Target = in.Target
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectPatch.
+func (in *ObjectPatch) DeepCopy() *ObjectPatch {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectPatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectPatchStatus) DeepCopyInto(out *ObjectPatchStatus) {
+	*out = *in
+	if in.PatchedObjects != nil {
+		in, out := &in.PatchedObjects, &out.PatchedObjects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectPatchStatus.
+func (in *ObjectPatchStatus) DeepCopy() *ObjectPatchStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectPatchStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectPatchTarget) DeepCopyInto(out *ObjectPatchTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectPatchTarget.
+func (in *ObjectPatchTarget) DeepCopy() *ObjectPatchTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectPatchTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodRefreshPolicy) DeepCopyInto(out *PodRefreshPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodRefreshPolicy.
+func (in *PodRefreshPolicy) DeepCopy() *PodRefreshPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PodRefreshPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PodRefreshPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodRefreshPolicyList) DeepCopyInto(out *PodRefreshPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PodRefreshPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodRefreshPolicyList.
+func (in *PodRefreshPolicyList) DeepCopy() *PodRefreshPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(PodRefreshPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PodRefreshPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodRefreshPolicySpec) DeepCopyInto(out *PodRefreshPolicySpec) {
+	*out = *in
+	in.WorkloadSelector.DeepCopyInto(&out.WorkloadSelector)
+	in.SecretSelector.DeepCopyInto(&out.SecretSelector)
+	if in.ConfigMapSelector != nil {
+		in, out := &in.ConfigMapSelector, &out.ConfigMapSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Strategy = in.Strategy
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(PodRefreshWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Debounce != nil {
+		in, out := &in.Debounce, &out.Debounce
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxConcurrentRestarts != nil {
+		in, out := &in.MaxConcurrentRestarts, &out.MaxConcurrentRestarts
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodRefreshPolicySpec.
+func (in *PodRefreshPolicySpec) DeepCopy() *PodRefreshPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodRefreshPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodRefreshPolicyStatus) DeepCopyInto(out *PodRefreshPolicyStatus) {
+	*out = *in
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]PodRefreshPolicyWorkload, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodRefreshPolicyStatus.
+func (in *PodRefreshPolicyStatus) DeepCopy() *PodRefreshPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PodRefreshPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodRefreshPolicyWorkload) DeepCopyInto(out *PodRefreshPolicyWorkload) {
+	*out = *in
+	if in.LastRefreshed != nil {
+		in, out := &in.LastRefreshed, &out.LastRefreshed
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodRefreshPolicyWorkload.
+func (in *PodRefreshPolicyWorkload) DeepCopy() *PodRefreshPolicyWorkload {
+	if in == nil {
+		return nil
+	}
+	out := new(PodRefreshPolicyWorkload)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodRefreshStrategy) DeepCopyInto(out *PodRefreshStrategy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodRefreshStrategy.
+func (in *PodRefreshStrategy) DeepCopy() *PodRefreshStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(PodRefreshStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodRefreshWindow) DeepCopyInto(out *PodRefreshWindow) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodRefreshWindow.
+func (in *PodRefreshWindow) DeepCopy() *PodRefreshWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(PodRefreshWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodRefresherConfig) DeepCopyInto(out *PodRefresherConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodRefresherConfig.
+func (in *PodRefresherConfig) DeepCopy() *PodRefresherConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PodRefresherConfig)
+	in.DeepCopyInto(out)
+	return out
+}
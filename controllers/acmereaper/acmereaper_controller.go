@@ -0,0 +1,149 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acmereaper
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// acmeKinds are the kinds of ACME resources reaped, as installed by the operator.
+	acmeKinds = []schema.GroupVersionKind{
+		{Group: "acme.cert-manager.io", Version: "v1", Kind: "Order"},
+		{Group: "acme.cert-manager.io", Version: "v1", Kind: "Challenge"},
+	}
+
+	// Eventing helpers
+	staleResource  = reaperEvent{reason: "StaleACMEResource", message: "The object has not succeeded and is older than the reaper's maximum age:"}
+	reapedResource = reaperEvent{reason: "ReapedACMEResource", message: "The object was deleted as it has not succeeded and is older than the reaper's maximum age:"}
+)
+
+// ACMEReaperReconciler reaps ACME Orders and Challenges that are older than MaxAge and have failed,
+// or are retrying after errors, such as those retrying against ACME rate limits. Reaped objects are
+// reported with events and metrics, and those that are invalid or errored are deleted if Delete is set.
+type ACMEReaperReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	record.EventRecorder
+	// MaxAge is how old an Order or Challenge that has not succeeded must be to be reaped.
+	MaxAge time.Duration
+	// Delete deletes reaped Orders and Challenges that are invalid or errored. Otherwise, and for those
+	// that are still retrying, they are only reported.
+	Delete bool
+
+	stale *staleTracker
+}
+
+// +kubebuilder:rbac:groups=acme.cert-manager.io,resources=orders;challenges,verbs=get;list;watch;delete;
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;
+
+// kindReaper reconciles ACME resources of a single kind.
+type kindReaper struct {
+	*ACMEReaperReconciler
+	gvk schema.GroupVersionKind
+}
+
+// Reconcile reaps the Order or Challenge if it is older than MaxAge and has not succeeded. Objects that
+// have not succeeded but are not yet old enough are requeued for when they are.
+func (r *kindReaper) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(r.gvk)
+	if err := r.Get(context.TODO(), req.NamespacedName, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.stale.forget(r.gvk.Kind, req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	failed, terminal, reason := notSucceeded(obj)
+	if !failed {
+		r.stale.forget(r.gvk.Kind, req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	if age := time.Since(obj.GetCreationTimestamp().Time); age < r.MaxAge {
+		return ctrl.Result{RequeueAfter: r.MaxAge - age}, nil
+	}
+
+	// objects that are still retrying may yet succeed, so they are never deleted.
+	if !r.Delete || !terminal {
+		// objects are reported once when they become stale, rather than on each resync.
+		if r.stale.add(r.gvk.Kind, req.NamespacedName) {
+			r.Log.Info("Reporting stale ACME resource", "Kind", r.gvk.Kind, "Name", req.Name, "Namespace", req.Namespace, "Reason", reason)
+			r.Eventf(obj, corev1.EventTypeWarning, staleResource.reason, "%s %s", staleResource.message, reason)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	r.Log.Info("Deleting stale ACME resource", "Kind", r.gvk.Kind, "Name", req.Name, "Namespace", req.Namespace, "Reason", reason)
+	if err := r.Client.Delete(context.TODO(), obj); err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	r.Eventf(obj, corev1.EventTypeNormal, reapedResource.reason, "%s %s", reapedResource.message, reason)
+	reapedResources.WithLabelValues(req.Namespace, r.gvk.Kind).Inc()
+	r.stale.forget(r.gvk.Kind, req.NamespacedName)
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager configures a controller owned by the manager mgr for each ACME kind. Kinds that are
+// not served by the cluster are skipped, so the operator must be restarted to reap them once their CRDs
+// are installed.
+func (r *ACMEReaperReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.stale = newStaleTracker()
+
+	for _, gvk := range acmeKinds {
+		if _, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+			if meta.IsNoMatchError(err) {
+				r.Log.Info("ACME kind is not served by the cluster and will not be reaped", "GroupVersionKind", gvk.String())
+				continue
+			}
+			return err
+		}
+
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		if err := ctrl.NewControllerManagedBy(mgr).
+			Named("acme-reaper-" + strings.ToLower(gvk.Kind)).
+			For(obj).
+			Complete(&kindReaper{ACMEReaperReconciler: r, gvk: gvk}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type reaperEvent struct {
+	reason  string
+	message string
+}
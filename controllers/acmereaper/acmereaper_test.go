@@ -0,0 +1,140 @@
+package acmereaper
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// order returns an unstructured ACME Order created age ago with the state and reason.
+func order(name string, age time.Duration, state, reason string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetGroupVersionKind(acmeKinds[0])
+	obj.SetNamespace("ns")
+	obj.SetName(name)
+	obj.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-age)))
+	status := map[string]interface{}{}
+	if state != "" {
+		status["state"] = state
+	}
+	if reason != "" {
+		status["reason"] = reason
+	}
+	obj.Object["status"] = status
+
+	return obj
+}
+
+var _ = Describe("ACME reaper", func() {
+	Context("When checking the state of an ACME resource", func() {
+		It("Should reap failed resources", func() {
+			for state, terminal := range map[string]bool{"invalid": true, "errored": true, "expired": false} {
+				failed, isTerminal, reason := notSucceeded(order("order", 0, state, "Failed to finalize Order"))
+				Expect(failed).To(BeTrue())
+				Expect(isTerminal).To(Equal(terminal))
+				Expect(reason).To(Equal("state is " + state + ": Failed to finalize Order"))
+			}
+		})
+
+		It("Should reap resources retrying after errors without treating them as terminal", func() {
+			for _, state := range []string{"", "pending"} {
+				failed, terminal, reason := notSucceeded(order("order", 0, state, "429 urn:ietf:params:acme:error:rateLimited"))
+				Expect(failed).To(BeTrue())
+				Expect(terminal).To(BeFalse())
+				Expect(reason).To(ContainSubstring("rateLimited"))
+			}
+		})
+
+		It("Should not reap succeeded or progressing resources", func() {
+			for _, o := range []*unstructured.Unstructured{order("valid", 0, "valid", ""), order("ready", 0, "ready", ""), order("pending", 0, "pending", "")} {
+				failed, _, _ := notSucceeded(o)
+				Expect(failed).To(BeFalse())
+			}
+		})
+	})
+
+	Context("When reconciling an ACME resource", func() {
+		var (
+			c        client.Client
+			recorder *record.FakeRecorder
+			r        *kindReaper
+		)
+
+		BeforeEach(func() {
+			s := runtime.NewScheme()
+			for _, gvk := range acmeKinds {
+				s.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+				s.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+			}
+			c = fake.NewFakeClientWithScheme(s,
+				order("stale", 48*time.Hour, "errored", "rate limited"),
+				order("young", time.Hour, "errored", "rate limited"),
+				order("retrying", 48*time.Hour, "pending", "rate limited"),
+				order("valid", 48*time.Hour, "valid", ""),
+			)
+			recorder = record.NewFakeRecorder(10)
+			r = &kindReaper{
+				ACMEReaperReconciler: &ACMEReaperReconciler{Client: c, Log: logf.Log, EventRecorder: recorder, MaxAge: 24 * time.Hour, stale: newStaleTracker()},
+				gvk:                  acmeKinds[0],
+			}
+		})
+
+		request := func(name string) ctrl.Request {
+			return ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: name}}
+		}
+
+		It("Should report stale resources once", func() {
+			Expect(r.Reconcile(request("stale"))).To(Equal(ctrl.Result{}))
+			Expect(recorder.Events).To(Receive(ContainSubstring(staleResource.reason)))
+			Expect(r.stale.objects["Order"]).To(HaveLen(1))
+
+			Expect(r.Reconcile(request("stale"))).To(Equal(ctrl.Result{}))
+			Expect(recorder.Events).ToNot(Receive())
+			Expect(c.Get(context.TODO(), request("stale").NamespacedName, order("stale", 0, "", ""))).To(Succeed())
+		})
+
+		It("Should delete stale resources when configured to", func() {
+			r.Delete = true
+			Expect(r.Reconcile(request("stale"))).To(Equal(ctrl.Result{}))
+			Expect(recorder.Events).To(Receive(ContainSubstring(reapedResource.reason)))
+
+			err := c.Get(context.TODO(), request("stale").NamespacedName, order("stale", 0, "", ""))
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Should only report stale resources that are still retrying when configured to delete", func() {
+			r.Delete = true
+			Expect(r.Reconcile(request("retrying"))).To(Equal(ctrl.Result{}))
+			Expect(recorder.Events).To(Receive(ContainSubstring(staleResource.reason)))
+			Expect(c.Get(context.TODO(), request("retrying").NamespacedName, order("retrying", 0, "", ""))).To(Succeed())
+		})
+
+		It("Should requeue resources that are not old enough", func() {
+			result, err := r.Reconcile(request("young"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", 23*time.Hour, time.Minute))
+			Expect(recorder.Events).ToNot(Receive())
+		})
+
+		It("Should forget resources that succeed or are deleted", func() {
+			r.stale.add("Order", request("valid").NamespacedName)
+			r.stale.add("Order", request("gone").NamespacedName)
+			Expect(r.Reconcile(request("valid"))).To(Equal(ctrl.Result{}))
+			Expect(r.Reconcile(request("gone"))).To(Equal(ctrl.Result{}))
+			Expect(r.stale.objects["Order"]).To(BeEmpty())
+			Expect(recorder.Events).ToNot(Receive())
+		})
+	})
+})
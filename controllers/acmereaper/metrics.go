@@ -0,0 +1,30 @@
+package acmereaper
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// staleResources tracks the number of stale ACME resources reported and not deleted.
+	staleResources = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "acmereaper_stale_resources",
+			Help: "Number of ACME resources older than the maximum age that have not succeeded and were reported but not deleted, by kind.",
+		},
+		[]string{"kind"},
+	)
+
+	// reapedResources counts the stale ACME resources deleted by the reaper.
+	reapedResources = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "acmereaper_deleted_resources_total",
+			Help: "Number of ACME resources older than the maximum age that had not succeeded and were deleted, by namespace and kind.",
+		},
+		[]string{"namespace", "kind"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(staleResources, reapedResources)
+}
@@ -0,0 +1,75 @@
+package acmereaper
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// notSucceeded returns true if the ACME Order or Challenge has failed, or has not reached a final state
+// and is retrying after an error, along with a description of its state. Objects are retrying if they
+// have a reason but no final state. terminal is true only if the object is invalid or errored, as only
+// those will not go on to succeed.
+func notSucceeded(obj *unstructured.Unstructured) (failed, terminal bool, description string) {
+	state, _, _ := unstructured.NestedString(obj.Object, "status", "state")
+	reason, _, _ := unstructured.NestedString(obj.Object, "status", "reason")
+
+	switch state {
+	case "valid", "ready":
+		return false, false, ""
+	case "invalid", "errored":
+		return true, true, fmt.Sprintf("state is %s: %s", state, reason)
+	case "expired":
+		return true, false, fmt.Sprintf("state is %s: %s", state, reason)
+	}
+
+	if reason != "" {
+		return true, false, fmt.Sprintf("retrying after error: %s", reason)
+	}
+
+	return false, false, ""
+}
+
+// staleTracker tracks the stale ACME resources that have been reported, so that each is reported once,
+// and so that the number of stale resources of each kind can be exported.
+type staleTracker struct {
+	mu      sync.Mutex
+	objects map[string]map[types.NamespacedName]struct{}
+}
+
+// newStaleTracker returns an empty staleTracker.
+func newStaleTracker() *staleTracker {
+	return &staleTracker{objects: make(map[string]map[types.NamespacedName]struct{})}
+}
+
+// add records the object of the kind as stale, returning false if it already was.
+func (t *staleTracker) add(kind string, key types.NamespacedName) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.objects[kind] == nil {
+		t.objects[kind] = make(map[types.NamespacedName]struct{})
+	}
+	if _, ok := t.objects[kind][key]; ok {
+		return false
+	}
+
+	t.objects[kind][key] = struct{}{}
+	staleResources.WithLabelValues(kind).Set(float64(len(t.objects[kind])))
+	return true
+}
+
+// forget stops tracking the object of the kind, as it was deleted or is no longer stale.
+func (t *staleTracker) forget(kind string, key types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.objects[kind][key]; !ok {
+		return
+	}
+
+	delete(t.objects[kind], key)
+	staleResources.WithLabelValues(kind).Set(float64(len(t.objects[kind])))
+}
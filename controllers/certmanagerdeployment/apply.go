@@ -0,0 +1,84 @@
+package certmanagerdeployment
+
+import (
+	"context"
+	"strings"
+
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// fieldManager is the field manager used for all server-side apply requests made by the operator.
+// It must not change between releases, or ownership of previously applied fields is lost.
+const fieldManager = "cmd-operator"
+
+// applyManagedObject server-side applies obj using the operator's field manager. Only the fields set
+// on obj become owned by the operator, so fields set by other controllers are left alone. Fields the
+// operator sets that are owned by another manager, such as those written by earlier releases of the
+// operator or edited with kubectl, are taken over so that drift is corrected. The fields taken over
+// are reported as an event on the instance.
+func (r *CertManagerDeploymentReconciler) applyManagedObject(instance *operatorsv1alpha1.CertManagerDeployment, obj managedObject) error {
+	// apply requests must include the type information, and must not include
+	// a resourceVersion or managedFields.
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+
+	// apply without forcing first, so that the conflicting fields are known before they are taken over.
+	err = r.Patch(context.TODO(), obj, client.Apply, client.FieldOwner(fieldManager))
+	if err == nil || !apierrors.IsConflict(err) {
+		return err
+	}
+
+	r.Eventf(instance,
+		fieldsTakenOverManagedObject.etype,
+		fieldsTakenOverManagedObject.reason,
+		"%s: %s %s: %s",
+		fieldsTakenOverManagedObject.message,
+		gvk.Kind,
+		objectName(obj),
+		strings.Join(conflictingFields(err), ", "))
+
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	return r.Patch(context.TODO(), obj, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+}
+
+// conflictingFields returns the fields, and the managers owning them, that caused the apply conflict
+// err. The error message is returned if it does not list them.
+func conflictingFields(err error) []string {
+	status, ok := err.(apierrors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return []string{err.Error()}
+	}
+
+	var fields []string
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		fields = append(fields, cause.Field+" ("+cause.Message+")")
+	}
+
+	if len(fields) == 0 {
+		return []string{err.Error()}
+	}
+
+	return fields
+}
+
+// objectName returns the namespaced name of a namespaced object,
+// or the name of a cluster-scoped object.
+func objectName(obj managedObject) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
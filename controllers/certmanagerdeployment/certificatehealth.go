@@ -0,0 +1,207 @@
+package certmanagerdeployment
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// defaultExpiringWithinDays is how many days before they expire Certificates are counted as expiring
+	// if spec.certificateHealth.expiringWithinDays is not set.
+	defaultExpiringWithinDays = 14
+
+	// certificateHealthInterval is how often the certificate health in the status is refreshed.
+	certificateHealthInterval = 5 * time.Minute
+
+	certificateKind        = "Certificate"
+	certificateRequestKind = "CertificateRequest"
+	issuerKind             = "Issuer"
+	clusterIssuerKind      = "ClusterIssuer"
+)
+
+// certManagerKinds returns the group version kinds of the cert-manager resources summarized in the
+// status, keyed by kind, from the storage versions of the CRDs installed by the operator.
+func certManagerKinds(crds []*apiextv1.CustomResourceDefinition) map[string]schema.GroupVersionKind {
+	kinds := make(map[string]schema.GroupVersionKind)
+	for _, crd := range crds {
+		switch crd.Spec.Names.Kind {
+		case certificateKind, certificateRequestKind, issuerKind, clusterIssuerKind:
+		default:
+			continue
+		}
+
+		for _, version := range crd.Spec.Versions {
+			if version.Storage {
+				kinds[crd.Spec.Names.Kind] = schema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind}
+			}
+		}
+	}
+
+	return kinds
+}
+
+// listCertManagerResources lists the cert-manager resources of the gvk in all namespaces.
+func listCertManagerResources(reader client.Reader, gvk schema.GroupVersionKind) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := reader.List(context.TODO(), list); err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
+
+// readyCondition returns the status and reason of the Ready condition of a cert-manager resource,
+// and false if it has none.
+func readyCondition(obj unstructured.Unstructured) (string, string, bool) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+
+		status, _ := condition["status"].(string)
+		reason, _ := condition["reason"].(string)
+		return status, reason, true
+	}
+
+	return "", "", false
+}
+
+// isReady returns true if the Ready condition of a cert-manager resource is True.
+func isReady(obj unstructured.Unstructured) bool {
+	status, _, _ := readyCondition(obj)
+	return status == string(corev1.ConditionTrue)
+}
+
+// summarizeCertificateHealth counts the cert-manager resources of the kinds by their health, reading
+// them with the reader. Certificates whose status.notAfter is within window of now are counted as expiring.
+func summarizeCertificateHealth(reader client.Reader, kinds map[string]schema.GroupVersionKind, window time.Duration, now time.Time) (*operatorsv1alpha1.CertificateHealth, error) {
+	health := &operatorsv1alpha1.CertificateHealth{LastUpdateTime: metav1.NewTime(now)}
+	for _, kind := range []string{certificateKind, certificateRequestKind, issuerKind, clusterIssuerKind} {
+		gvk, ok := kinds[kind]
+		if !ok {
+			return nil, &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "cert-manager.io", Kind: kind}}
+		}
+
+		objs, err := listCertManagerResources(reader, gvk)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range objs {
+			switch kind {
+			case certificateKind:
+				if isReady(obj) {
+					health.CertificatesReady++
+				} else {
+					health.CertificatesNotReady++
+				}
+
+				notAfter, _, _ := unstructured.NestedString(obj.Object, "status", "notAfter")
+				if expiry, err := time.Parse(time.RFC3339, notAfter); err == nil && expiry.Sub(now) < window {
+					health.CertificatesExpiring++
+				}
+			case certificateRequestKind:
+				if status, reason, _ := readyCondition(obj); status == string(corev1.ConditionFalse) && (reason == "Failed" || reason == "Denied") {
+					health.CertificateRequestsFailed++
+				}
+			case issuerKind:
+				if !isReady(obj) {
+					health.IssuersNotReady++
+				}
+			case clusterIssuerKind:
+				if !isReady(obj) {
+					health.ClusterIssuersNotReady++
+				}
+			}
+		}
+	}
+
+	return health, nil
+}
+
+// certificateHealthReporter summarizes the health of cert-manager resources across the cluster in the
+// status of each CertManagerDeployment every interval. Changes to cert-manager resources do not trigger
+// reconciliation, and refreshing the summary from the reconciler would apply every managed object as
+// often, so it runs on its own and only patches the summary into the status when it changes.
+type certificateHealthReporter struct {
+	client.Client
+	Log      logr.Logger
+	interval time.Duration
+}
+
+// Start implements manager.Runnable. The summary is refreshed until stop is closed.
+func (r *certificateHealthReporter) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.report(time.Now())
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// report refreshes the summary in the status of each CertManagerDeployment at now.
+func (r *certificateHealthReporter) report(now time.Time) {
+	instances := operatorsv1alpha1.CertManagerDeploymentList{}
+	if err := r.List(context.TODO(), &instances); err != nil {
+		r.Log.Error(err, "Unable to list CertManagerDeployments to summarize certificate health")
+		return
+	}
+
+	for i := range instances.Items {
+		instance := &instances.Items[i]
+		rg := ResourceGetter{CustomResource: *instance}
+		crds, err := rg.GetCRDs()
+		if err != nil {
+			r.Log.Info("unable to determine the installed cert-manager resources to summarize", "error", err.Error())
+			continue
+		}
+
+		if err := r.reportFor(instance, certManagerKinds(crds), now); err != nil {
+			r.Log.Info("unable to summarize the health of cert-manager resources", "CertManagerDeployment.Name", instance.GetName(), "error", err.Error())
+		}
+	}
+}
+
+// reportFor summarizes the health of the cert-manager resources of the kinds in the status of instance,
+// if it changed. The summary keeps its LastUpdateTime while the counts are unchanged.
+func (r *certificateHealthReporter) reportFor(instance *operatorsv1alpha1.CertManagerDeployment, kinds map[string]schema.GroupVersionKind, now time.Time) error {
+	days := instance.Spec.CertificateHealth.ExpiringWithinDays
+	if days <= 0 {
+		days = defaultExpiringWithinDays
+	}
+
+	health, err := summarizeCertificateHealth(r, kinds, time.Duration(days)*24*time.Hour, now)
+	if err != nil {
+		return err
+	}
+
+	if previous := instance.Status.CertificateHealth; previous != nil {
+		health.LastUpdateTime = previous.LastUpdateTime
+		if *health == *previous {
+			return nil
+		}
+		health.LastUpdateTime = metav1.NewTime(now)
+	}
+
+	patch := client.MergeFrom(instance.DeepCopy())
+	instance.Status.CertificateHealth = health
+	return r.Status().Patch(context.TODO(), instance, patch)
+}
@@ -0,0 +1,149 @@
+package certmanagerdeployment
+
+import (
+	"context"
+	"time"
+
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// certManagerCRD returns a CRD for the cert-manager kind stored as version v1.
+func certManagerCRD(group, kind string) *apiextv1.CustomResourceDefinition {
+	return &apiextv1.CustomResourceDefinition{Spec: apiextv1.CustomResourceDefinitionSpec{
+		Group: group,
+		Names: apiextv1.CustomResourceDefinitionNames{Kind: kind},
+		Versions: []apiextv1.CustomResourceDefinitionVersion{
+			{Name: "v1alpha2", Served: true},
+			{Name: "v1", Served: true, Storage: true},
+		},
+	}}
+}
+
+// certManagerResource returns an unstructured cert-manager resource of the kind with a Ready condition,
+// or none if ready is empty.
+func certManagerResource(kind, name, ready, reason string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: kind})
+	obj.SetNamespace("ns")
+	obj.SetName(name)
+	if ready != "" {
+		_ = unstructured.SetNestedSlice(obj.Object, []interface{}{
+			map[string]interface{}{"type": "Ready", "status": ready, "reason": reason},
+		}, "status", "conditions")
+	}
+
+	return obj
+}
+
+var _ = Describe("Certificate health", func() {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	crds := []*apiextv1.CustomResourceDefinition{
+		certManagerCRD("cert-manager.io", certificateKind),
+		certManagerCRD("cert-manager.io", certificateRequestKind),
+		certManagerCRD("cert-manager.io", issuerKind),
+		certManagerCRD("cert-manager.io", clusterIssuerKind),
+		certManagerCRD("acme.cert-manager.io", "Order"),
+	}
+
+	var c client.Client
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(operatorsv1alpha1.AddToScheme(s)).To(Succeed())
+		for _, kind := range []string{certificateKind, certificateRequestKind, issuerKind, clusterIssuerKind} {
+			gv := schema.GroupVersion{Group: "cert-manager.io", Version: "v1"}
+			s.AddKnownTypeWithName(gv.WithKind(kind), &unstructured.Unstructured{})
+			s.AddKnownTypeWithName(gv.WithKind(kind+"List"), &unstructured.UnstructuredList{})
+		}
+
+		expiring := certManagerResource(certificateKind, "expiring", "True", "Ready")
+		_ = unstructured.SetNestedField(expiring.Object, now.Add(24*time.Hour).Format(time.RFC3339), "status", "notAfter")
+		renewed := certManagerResource(certificateKind, "renewed", "True", "Ready")
+		_ = unstructured.SetNestedField(renewed.Object, now.Add(60*24*time.Hour).Format(time.RFC3339), "status", "notAfter")
+		expired := certManagerResource(certificateKind, "expired", "False", "Expired")
+		_ = unstructured.SetNestedField(expired.Object, now.Add(-time.Hour).Format(time.RFC3339), "status", "notAfter")
+
+		c = fake.NewFakeClientWithScheme(s,
+			expiring, renewed, expired,
+			certManagerResource(certificateKind, "issuing", "", ""),
+			certManagerResource(certificateRequestKind, "failed", "False", "Failed"),
+			certManagerResource(certificateRequestKind, "denied", "False", "Denied"),
+			certManagerResource(certificateRequestKind, "pending", "False", "Pending"),
+			certManagerResource(issuerKind, "ready", "True", "KeyPairVerified"),
+			certManagerResource(issuerKind, "broken", "False", "ErrInitIssuer"),
+			certManagerResource(clusterIssuerKind, "unknown", "", ""),
+		)
+	})
+
+	It("Should find the stored versions of the installed kinds", func() {
+		kinds := certManagerKinds(crds)
+		Expect(kinds).To(HaveLen(4))
+		Expect(kinds[certificateKind]).To(Equal(schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: certificateKind}))
+	})
+
+	It("Should count resources by their health", func() {
+		health, err := summarizeCertificateHealth(c, certManagerKinds(crds), 14*24*time.Hour, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(health.CertificatesReady).To(Equal(2))
+		Expect(health.CertificatesNotReady).To(Equal(2))
+		Expect(health.CertificatesExpiring).To(Equal(2))
+		Expect(health.CertificateRequestsFailed).To(Equal(2))
+		Expect(health.IssuersNotReady).To(Equal(1))
+		Expect(health.ClusterIssuersNotReady).To(Equal(1))
+		Expect(health.LastUpdateTime.Time).To(Equal(now))
+	})
+
+	Context("When reporting the summary in the status", func() {
+		var (
+			counting *statusWriteCountingClient
+			reporter *certificateHealthReporter
+		)
+		key := types.NamespacedName{Name: "cluster"}
+
+		BeforeEach(func() {
+			Expect(c.Create(context.TODO(), &operatorsv1alpha1.CertManagerDeployment{ObjectMeta: metav1.ObjectMeta{Name: key.Name}})).To(Succeed())
+			counting = &statusWriteCountingClient{Client: c}
+			reporter = &certificateHealthReporter{Client: counting, Log: logf.Log, interval: time.Minute}
+		})
+
+		getInstance := func() *operatorsv1alpha1.CertManagerDeployment {
+			instance := &operatorsv1alpha1.CertManagerDeployment{}
+			Expect(c.Get(context.TODO(), key, instance)).To(Succeed())
+			return instance
+		}
+
+		It("Should only patch the status when the counts change", func() {
+			Expect(reporter.reportFor(getInstance(), certManagerKinds(crds), now)).To(Succeed())
+			Expect(counting.statusUpdates).To(Equal(1))
+			health := getInstance().Status.CertificateHealth
+			Expect(health.CertificatesReady).To(Equal(2))
+
+			Expect(reporter.reportFor(getInstance(), certManagerKinds(crds), now.Add(time.Minute))).To(Succeed())
+			Expect(counting.statusUpdates).To(Equal(1))
+
+			Expect(c.Create(context.TODO(), certManagerResource(certificateKind, "new", "True", "Ready"))).To(Succeed())
+			Expect(reporter.reportFor(getInstance(), certManagerKinds(crds), now.Add(2*time.Minute))).To(Succeed())
+			Expect(counting.statusUpdates).To(Equal(2))
+			health = getInstance().Status.CertificateHealth
+			Expect(health.CertificatesReady).To(Equal(3))
+			Expect(health.LastUpdateTime.Time).To(BeTemporally("==", now.Add(2*time.Minute)))
+		})
+	})
+
+	It("Should fail if a kind is not installed", func() {
+		_, err := summarizeCertificateHealth(c, certManagerKinds(crds[:3]), 14*24*time.Hour, now)
+		Expect(meta.IsNoMatchError(err)).To(BeTrue())
+	})
+})
@@ -44,6 +44,9 @@ type CertManagerDeploymentReconciler struct {
 	Log    logr.Logger
 	Scheme *runtime.Scheme
 	record.EventRecorder
+	// PodRefresher starts and stops the pod refresher as configured by the CertManagerDeployment. It
+	// is nil if the pod refresher was enabled by the operator's flags, which take precedence.
+	PodRefresher PodRefresherRunner
 }
 
 // +kubebuilder:rbac:groups=operators.redhat.io,resources=certmanagerdeployments,verbs=get;list;watch;create;update;patch;delete
@@ -59,6 +62,7 @@ type CertManagerDeploymentReconciler struct {
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations/finalizers;validatingwebhookconfigurations/finalizers,verbs=update;
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles/finalizers;rolebindings/finalizers;clusterroles/finalizers;clusterrolebindings/finalizers,verbs=update;
 // +kubebuilder:rbac:groups=apps,resources=deployments/finalizers,verbs=update;
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates;certificaterequests;issuers;clusterissuers,verbs=get;list;
 
 // Reconcile compares the desired state of CertManagerDeployment custom resources and works to get
 // the existing state to match the desired state.
@@ -80,11 +84,17 @@ func (r *CertManagerDeploymentReconciler) Reconcile(req ctrl.Request) (ctrl.Resu
 	err := r.Get(context.TODO(), instanceKey, instance)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			return ctrl.Result{}, nil
+			// the pod refresher is stopped along with the instance configuring it.
+			return ctrl.Result{}, r.reconcilePodRefresher(nil)
 		}
 		return ctrl.Result{}, err
 	}
 
+	if err = r.reconcilePodRefresher(instance); err != nil {
+		r.Log.Error(err, "Encountered error configuring the pod refresher")
+		return ctrl.Result{}, err
+	}
+
 	// halt of the requested resource's version is unsupported
 	if !cmdoputils.CertManagerVersionIsSupported(instance, componentry.SupportedVersions) {
 		r.Log.Error(e.New("UnsupportedOperandVersion"),
@@ -109,54 +119,11 @@ func (r *CertManagerDeploymentReconciler) Reconcile(req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, err
 	}
 
-	if err = r.reconcileCRDs(instance, r.Log.WithValues("Reconciling", "CustomResourceDefinitions")); err != nil {
-		r.Log.Error(err, "Encountered error reconciling Custom Resource Definitions.")
-		return ctrl.Result{}, err
-	}
-
-	if err = r.reconcileNamespace(instance, r.Log.WithValues("Reconciling", "Namespaces")); err != nil {
-		r.Log.Error(err, "Encountered error reconciling Namespace")
-		return ctrl.Result{}, err
-	}
-
-	if err = r.reconcileServiceAccounts(instance, r.Log.WithValues("Reconciling", "ServiceAccounts")); err != nil {
-		r.Log.Error(err, "Encountered error reconciling ServiceAccounts")
-		return ctrl.Result{}, err
-	}
-
-	if err = r.reconcileRoles(instance, r.Log.WithValues("Reconciling", "Roles")); err != nil {
-		r.Log.Error(err, "Encountered error reconciliing Roles")
-		return ctrl.Result{}, err
-	}
-
-	if err = r.reconcileRoleBindings(instance, r.Log.WithValues("Reconciling", "RoleBindings")); err != nil {
-		r.Log.Error(err, "Encountered error reconciling RoleBindings")
-		return ctrl.Result{}, err
-	}
-
-	if err = r.reconcileClusterRoles(instance, r.Log.WithValues("Reconciling", "ClusterRoles")); err != nil {
-		r.Log.Error(err, "Encountered error reconciling ClusterRoles")
-		return ctrl.Result{}, err
-	}
-
-	if err = r.reconcileClusterRoleBindings(instance, r.Log.WithValues("Reconciling", "ClusterRoleBindings")); err != nil {
-		r.Log.Error(err, "Encountered error reconciling ClusterRoleBindings")
-		return ctrl.Result{}, err
-	}
-
-	if err = r.reconcileDeployments(instance, r.Log.WithValues("Reconciling", "Deployments")); err != nil {
-		r.Log.Error(err, "Encountered error reconciling Deployments")
-		return ctrl.Result{}, err
-	}
-
-	if err = r.reconcileServices(instance, r.Log.WithValues("Reconciling", "Services")); err != nil {
-		r.Log.Error(err, "Encountered error reconciling Services")
-		return ctrl.Result{}, err
-	}
-
-	if err = r.reconcileWebhooks(instance, r.Log.WithValues("Reconciling", "Webhooks")); err != nil {
-		r.Log.Error(err, "Encountered error reconciling Webhooks")
-		return ctrl.Result{}, err
+	for _, mk := range managedKinds {
+		if err = r.reconcileManagedKind(instance, mk, r.Log.WithValues("Reconciling", mk.kind)); err != nil {
+			r.Log.Error(err, "Encountered error reconciling "+mk.plural)
+			return ctrl.Result{}, err
+		}
 	}
 
 	// We had no error in reconciliation so we do not requeue.
@@ -165,6 +132,14 @@ func (r *CertManagerDeploymentReconciler) Reconcile(req ctrl.Request) (ctrl.Resu
 
 // SetupWithManager configures a controller owned by the manager mgr.
 func (r *CertManagerDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.Add(&certificateHealthReporter{
+		Client:   mgr.GetClient(),
+		Log:      r.Log.WithName("certificate-health"),
+		interval: certificateHealthInterval,
+	}); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&operatorsv1alpha1.CertManagerDeployment{}).
 		Owns(&corev1.ServiceAccount{}).
@@ -1,117 +1,32 @@
 package certmanagerdeployment
 
 import (
-	"context"
-
-	"github.com/go-logr/logr"
 	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
 	"github.com/komish/cmd-operator-dev/cmdoputils"
 	"github.com/komish/cmd-operator-dev/controllers/componentry"
 	rbacv1 "k8s.io/api/rbac/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-// reconcileClusterRoles will reconcile the Cluster Role resources for a given CertManagerDeployment resource.
-func (r *CertManagerDeploymentReconciler) reconcileClusterRoles(instance *operatorsv1alpha1.CertManagerDeployment, reqLogger logr.Logger) error {
-	reqLogger.Info("Starting reconciliation: cluster roles")
-	defer reqLogger.Info("Ending reconciliation: cluster roles")
-
-	getter := ResourceGetter{CustomResource: *instance}
-	crls := getter.GetClusterRoles()
-
-	for _, clusterRole := range crls {
-		if err := controllerutil.SetControllerReference(instance, clusterRole, r.Scheme); err != nil {
-			return err
-		}
-
-		found := &rbacv1.ClusterRole{}
-		err := r.Get(context.TODO(), types.NamespacedName{
-			Name:      clusterRole.GetName(),
-			Namespace: clusterRole.Namespace, // this should be empty
-		}, found)
-
-		if err != nil && apierrors.IsNotFound(err) {
-			reqLogger.Info("Creating Cluster Role", "ClusterRole.Name", clusterRole.GetName())
-			r.Eventf(instance,
-				createManagedClusterRole.etype,
-				createManagedClusterRole.reason,
-				"%s: %s",
-				createManagedClusterRole.message,
-				clusterRole.GetName())
-			if err := r.Create(context.TODO(), clusterRole); err != nil {
-				return err
-			}
-
-			// successful create. Move onto next iteration.
-			continue
-		} else if err != nil {
-			return err
-		}
-
-		// cluster role exists, check if it needs an update and update it.
-		genRulesInterface, err := cmdoputils.Interfacer{Data: clusterRole.Rules}.ToJSONInterface()
-		if err != nil { // err indicates a marshaling error
-			return err
-		}
-		foundRulesInterface, err := cmdoputils.Interfacer{Data: found.Rules}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		genLabelsInterface, err := cmdoputils.Interfacer{Data: clusterRole.Labels}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-		foundLabelsInterface, err := cmdoputils.Interfacer{Data: clusterRole.Labels}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		rulesMatch := cmdoputils.ObjectsMatch(genRulesInterface, foundRulesInterface)
-		labelsMatch := cmdoputils.ObjectsMatch(genLabelsInterface, foundLabelsInterface)
-
-		if !(rulesMatch && labelsMatch) {
-			reqLogger.Info("ClusterRoleBinding already exists, but needs an update.",
-				"ClusterRoleBinding.Name", clusterRole.GetName(),
-				"HasExpectedSubjects", rulesMatch,
-				"HasExpectedLabels", labelsMatch)
-			r.Eventf(instance,
-				updatingManagedClusterRole.etype,
-				updatingManagedClusterRole.reason,
-				"%s: %s",
-				updatingManagedClusterRole.message,
-				clusterRole.GetName())
-
-			updated := found.DeepCopy()
-
-			if !rulesMatch {
-				updated.Rules = clusterRole.Rules
-			}
-
-			if !labelsMatch {
-				// TODO(): should we avoid clobbering and instead just add our labels?
-				updated.ObjectMeta.Labels = clusterRole.GetLabels()
-			}
-
-			reqLogger.Info("Updating ClusterRole.",
-				"ClusterRole.Name", clusterRole.GetName())
-			if err := r.Update(context.TODO(), updated); err != nil {
-				return err
-			}
-
-			r.Eventf(instance,
-				updatedManagedClusterRoleBinding.etype,
-				updatedManagedClusterRoleBinding.reason,
-				"%s: %s",
-				updatedManagedClusterRoleBinding.message,
-				clusterRole.GetName())
-		}
-	}
-
-	return nil
+// managedClusterRoles reconciles the ClusterRole resources for a given CertManagerDeployment resource.
+var managedClusterRoles = managedKind{
+	kind:   "ClusterRole",
+	plural: "cluster roles",
+	desired: func(rg *ResourceGetter) ([]managedObject, error) {
+		var objs []managedObject
+		for _, clusterRole := range rg.GetClusterRoles() {
+			objs = append(objs, clusterRole)
+		}
+		return objs, nil
+	},
+	empty: func() managedObject { return &rbacv1.ClusterRole{} },
+	compare: func(obj managedObject) map[string]interface{} {
+		clusterRole := obj.(*rbacv1.ClusterRole)
+		return map[string]interface{}{"Rules": clusterRole.Rules, "AggregationRule": clusterRole.AggregationRule, "Labels": clusterRole.Labels, "Annotations": clusterRole.Annotations}
+	},
+	owned:    true,
+	creating: createManagedClusterRole,
+	updated:  updatedManagedClusterRole,
 }
 
 // GetClusterRoles will return all ClusterRoles for CertManageComponents.
@@ -125,6 +40,11 @@ func (r *ResourceGetter) GetClusterRoles() []*rbacv1.ClusterRole {
 			result = append(result, newClusterRole(component, clusterRole, r.CustomResource))
 		}
 	}
+
+	for _, clusterRole := range result {
+		r.applyPatches(clusterRole)
+	}
+
 	return result
 }
 
@@ -1,130 +1,47 @@
 package certmanagerdeployment
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
 
-	"github.com/go-logr/logr"
-	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
 	"github.com/komish/cmd-operator-dev/cmdoputils"
 	"github.com/komish/cmd-operator-dev/controllers/componentry"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/scheme"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/types"
 )
 
 var (
 	crdMap = map[string][]string{}
 )
 
-// reconcileCRDs reconciles CustomResourceDefinition resource(s) for a given CertManagerDeployment resource.
-func (r *CertManagerDeploymentReconciler) reconcileCRDs(instance *operatorsv1alpha1.CertManagerDeployment, reqLogger logr.Logger) error {
-
-	reqLogger.Info("Starting reconciliation: CRDs")
-	defer reqLogger.Info("Ending reconciliation: CRDs")
-
-	getter := ResourceGetter{CustomResource: *instance}
-	crds, err := getter.GetCRDs()
-	if err != nil {
-		reqLogger.Error(err, "Failed to get CRDs")
-		// Something happened when trying to get CRDs for this reconciliation
-		return err
-	}
-
-	for _, crd := range crds {
-		found := &apiextv1.CustomResourceDefinition{}
-		err := r.Get(context.TODO(), types.NamespacedName{Name: crd.GetName()}, found)
-		if err != nil && apierrors.IsNotFound(err) {
-			reqLogger.Info("Creating CustomResourceDefinition", "CustomResourceDefinition.Name", crd.GetName())
-			r.Eventf(instance, createManagedCRD.etype, createManagedCRD.reason, "%s: %s", createManagedCRD.message, crd.GetName())
-
-			if err := r.Create(context.TODO(), crd); err != nil {
-				return err
-			}
-
-			// successful create. Move onto next iteration.
-			continue
-		} else if err != nil {
-			return err
-		}
-
-		// If needed, update CRD.
-		genSpecInterface, err := cmdoputils.Interfacer{Data: crd.Spec}.ToJSONInterface()
-		if err != nil { // error indicates marshaling problems
-			return err
-		}
-		foundSpecInterface, err := cmdoputils.Interfacer{Data: found.Spec}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		genLabelsInterface, err := cmdoputils.Interfacer{Data: crd.Labels}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		foundLabelsInterface, err := cmdoputils.Interfacer{Data: found.Labels}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		genAnnotsInterface, err := cmdoputils.Interfacer{Data: crd.Annotations}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		foundAnnotsInterface, err := cmdoputils.Interfacer{Data: found.Annotations}.ToJSONInterface()
+// managedCRDs reconciles the CustomResourceDefinition resources for a given CertManagerDeployment resource.
+// CRDs are not owned by the CertManagerDeployment so that custom resources are not removed along with it.
+var managedCRDs = managedKind{
+	kind:   "CustomResourceDefinition",
+	plural: "CRDs",
+	desired: func(rg *ResourceGetter) ([]managedObject, error) {
+		crds, err := rg.GetCRDs()
 		if err != nil {
-			return err
+			// Something happened when trying to get CRDs for this reconciliation
+			return nil, err
 		}
 
-		// Check for equality
-		specsMatch := cmdoputils.ObjectsMatch(genSpecInterface, foundSpecInterface)
-		labelsMatch := cmdoputils.ObjectsMatch(genLabelsInterface, foundLabelsInterface)
-		annotsMatch := cmdoputils.ObjectsMatch(genAnnotsInterface, foundAnnotsInterface)
-
-		// If not equal, update.
-		if !(specsMatch && labelsMatch && annotsMatch) {
-			reqLogger.Info("CustomResourceDefinition already exists, but needs an update. Updating.",
-				"CustomResourceDefinition.Name", crd.GetName(),
-				"HasExpectedLabels", labelsMatch,
-				"HasExpectedAnnotations", annotsMatch,
-				"HasExpectedSpec", specsMatch)
-			r.Eventf(instance, updatingManagedCRD.etype, updatingManagedCRD.reason, "%s: %s", updatingManagedCRD.message, crd.GetName())
-
-			// modify the state of the old object to post to API
-			updated := found.DeepCopy()
-
-			if !specsMatch {
-				updated.Spec = crd.Spec
-			}
-
-			// TODO(): should we merge instead of
-			// clobbering these updates?
-			if !labelsMatch {
-				updated.ObjectMeta.Labels = crd.GetLabels()
-			}
-
-			if !annotsMatch {
-				updated.ObjectMeta.Annotations = crd.GetAnnotations()
-			}
-
-			reqLogger.Info("Updating CustomResourceDefinition.", "CustomResourceDefinition.Name", crd.GetName())
-			if err := r.Update(context.TODO(), updated); err != nil {
-				// some issue performing the update.
-				return err
-			}
-
-			r.Eventf(instance, updatedManagedCRD.etype, updatedManagedCRD.reason, "%s: %s", updatedManagedCRD.message, crd.GetName())
+		var objs []managedObject
+		for _, crd := range crds {
+			objs = append(objs, crd)
 		}
-	}
-
-	return nil
+		return objs, nil
+	},
+	empty: func() managedObject { return &apiextv1.CustomResourceDefinition{} },
+	compare: func(obj managedObject) map[string]interface{} {
+		crd := obj.(*apiextv1.CustomResourceDefinition)
+		return map[string]interface{}{"Spec": crd.Spec, "Labels": crd.Labels, "Annotations": crd.Annotations}
+	},
+	creating: createManagedCRD,
+	updated:  updatedManagedCRD,
 }
 
 // GetCRDs returns CustomResourceDefinitions for a given CertManagerDeployment.
@@ -165,6 +82,10 @@ func (r *ResourceGetter) GetCRDs() ([]*apiextv1.CustomResourceDefinition, error)
 		res = append(res, c)
 	}
 
+	for _, crd := range res {
+		r.applyPatches(crd)
+	}
+
 	return res, nil
 }
 
@@ -1,133 +1,43 @@
 package certmanagerdeployment
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 
 	"reflect"
+	"sort"
 	"strings"
 
-	"github.com/go-logr/logr"
-	"github.com/imdario/mergo"
 	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
 	"github.com/komish/cmd-operator-dev/cmdoputils"
 	"github.com/komish/cmd-operator-dev/controllers/componentry"
 	certmanagerconfigs "github.com/komish/cmd-operator-dev/controllers/configs"
 	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	appsv1 "k8s.io/api/apps/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 )
 
-// reconcileDeployments will reconcile the Deployment resources for a given CertManagerDeployment resource.
-func (r *CertManagerDeploymentReconciler) reconcileDeployments(instance *operatorsv1alpha1.CertManagerDeployment, reqLogger logr.Logger) error {
-	reqLogger.Info("Starting reconciliation: deployments")
-	defer reqLogger.Info("Ending reconciliation: deployments")
-
-	getter := ResourceGetter{CustomResource: *instance}
-	deps := getter.GetDeployments()
-
-	for _, dep := range deps {
-		if err := controllerutil.SetControllerReference(instance, dep, r.Scheme); err != nil {
-			return err
-		}
-		found := &appsv1.Deployment{}
-		err := r.Get(context.TODO(), types.NamespacedName{Namespace: dep.GetNamespace(), Name: dep.GetName()}, found)
-		if err != nil && apierrors.IsNotFound(err) {
-			reqLogger.Info("Creating Deployment", "Deployment.Namespace", dep.GetNamespace(), "Deployment.Name", dep.GetName())
-			r.Eventf(instance,
-				createManagedDeployment.etype,
-				createManagedDeployment.reason,
-				"%s: %s/%s",
-				createManagedDeployment.message,
-				dep.GetNamespace(), dep.GetName())
-			if err := r.Create(context.TODO(), dep); err != nil {
-				return err
-			}
-
-			// successful create. Move onto next iteration.
-			continue
-		} else if err != nil {
-			return err
-		}
-
-		// A deployment exists. Update if necessary.
-		genSpecInterface, err := cmdoputils.Interfacer{Data: dep.Spec}.ToJSONInterface()
-		if err != nil { // err indicates a marshaling problem
-			return err
-		}
-		foundSpecInterface, err := cmdoputils.Interfacer{Data: found.Spec}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		genLabelsInterface, err := cmdoputils.Interfacer{Data: dep.Labels}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-		foundLabelsInterface, err := cmdoputils.Interfacer{Data: found.Labels}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		genAnnotsInterface, err := cmdoputils.Interfacer{Data: dep.Annotations}.ToJSONInterface()
-		if err != nil {
-			return err
+// managedDeployments reconciles the Deployment resources for a given CertManagerDeployment resource.
+var managedDeployments = managedKind{
+	kind:   "Deployment",
+	plural: "deployments",
+	desired: func(rg *ResourceGetter) ([]managedObject, error) {
+		var objs []managedObject
+		for _, dep := range rg.GetDeployments() {
+			objs = append(objs, dep)
 		}
-		foundAnnotsInterface, err := cmdoputils.Interfacer{Data: found.Annotations}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		specsMatch := cmdoputils.ObjectsMatch(genSpecInterface, foundSpecInterface)
-		labelsMatch := cmdoputils.ObjectsMatch(genLabelsInterface, foundLabelsInterface)
-		annotsMatch := cmdoputils.ObjectsMatch(genAnnotsInterface, foundAnnotsInterface)
-
-		if !(specsMatch && labelsMatch && annotsMatch) {
-			reqLogger.Info("Deployment already exists, but needs an update.",
-				"Deployment.Name", dep.GetName(),
-				"Deployment.Namespace", dep.GetNamespace(),
-				"HasExpectedLabels", labelsMatch,
-				"HasExpectedAnnotation", annotsMatch,
-				"HasExpectedSpec", specsMatch)
-			r.Eventf(instance, updatingManagedDeployment.etype, updatingManagedDeployment.reason, "%s: %s/%s", updatingManagedDeployment.message, dep.GetNamespace(), dep.GetName()) // BOOKMARK
-
-			updated := found.DeepCopy()
-
-			if !specsMatch {
-				// update our local copy with values to keys as defined in our generated spec.
-				err := mergo.Merge(&updated.Spec, dep.Spec, mergo.WithOverride)
-				if err != nil {
-					// Some problem merging the specs
-					return err
-				}
-			}
-
-			if !labelsMatch {
-				// TODO(): should we avoid clobbering and instead just add our labels?
-				updated.ObjectMeta.Labels = dep.GetLabels()
-			}
-
-			if !annotsMatch {
-				// TODO(): should we avoid clobbering and instead just add our annotations?
-				updated.ObjectMeta.Annotations = dep.GetAnnotations()
-			}
-
-			reqLogger.Info("Updating Deployment.", "Deployment.Name", dep.GetName(), "Deployment.Namespace", dep.GetNamespace())
-			if err := r.Update(context.TODO(), updated); err != nil {
-				return err
-			}
-
-			r.Eventf(instance, updatedManagedDeployment.etype, updatedManagedDeployment.reason, "%s: %s/%s", updatedManagedDeployment.message, dep.GetNamespace(), dep.GetName())
-		}
-	}
-
-	return nil
+		return objs, nil
+	},
+	empty: func() managedObject { return &appsv1.Deployment{} },
+	compare: func(obj managedObject) map[string]interface{} {
+		dep := obj.(*appsv1.Deployment)
+		return map[string]interface{}{"Spec": dep.Spec, "Labels": dep.Labels, "Annotations": dep.Annotations}
+	},
+	owned:    true,
+	creating: createManagedDeployment,
+	updated:  updatedManagedDeployment,
 }
 
 // DeploymentCustomizations are the values from the CertManagerDeployment that can
@@ -151,6 +61,10 @@ func (r *ResourceGetter) GetDeployments() []*appsv1.Deployment {
 		deploys = append(deploys, newDeployment(component, r.CustomResource, r.GetDeploymentCustomizations(component)))
 	}
 
+	for _, deploy := range deploys {
+		r.applyPatches(deploy)
+	}
+
 	return deploys
 }
 
@@ -169,6 +83,15 @@ func (r *ResourceGetter) GetDeploymentCustomizations(comp componentry.CertManage
 	// check if the any container arguments are being overridden.
 	if argOverrides := r.CustomResource.Spec.DangerZone.ContainerArgOverrides.GetOverridesFor(comp.GetName()); argOverrides.Raw != nil {
 		dc.ContainerArgs = *argOverrides
+		// rewrite overrides that were written for a prior version of cert-manager. If the overrides
+		// can't be parsed they are passed along as-is and handled when merging with the defaults.
+		migrated, _, err := certmanagerconfigs.MigrateOverrides(
+			comp.GetName(),
+			cmdoputils.CRVersionOrDefaultVersion(r.CustomResource.Spec.Version, componentry.CertManagerDefaultVersion),
+			argOverrides.Raw)
+		if err == nil {
+			dc.ContainerArgs = runtime.RawExtension{Raw: migrated}
+		}
 	} else {
 		// if argOverrides.Raw is nil, that implies the user did not set the override for this component.
 		// If we pass a nil value to this, we end up setting our arguments to null which sets the container
@@ -180,6 +103,37 @@ func (r *ResourceGetter) GetDeploymentCustomizations(comp componentry.CertManage
 	return dc
 }
 
+// GetOverrideNotices returns a notice for each container argument override in the CertManagerDeployment
+// that was rewritten, ignored, or uses a deprecated flag for the requested version of cert-manager.
+func (r *ResourceGetter) GetOverrideNotices() []operatorsv1alpha1.ContainerArgOverrideNotice {
+	version := cmdoputils.CRVersionOrDefaultVersion(r.CustomResource.Spec.Version, componentry.CertManagerDefaultVersion)
+	res := make([]operatorsv1alpha1.ContainerArgOverrideNotice, 0)
+
+	for _, componentGetterFunc := range componentry.Components {
+		component := componentGetterFunc(version)
+		argOverrides := r.CustomResource.Spec.DangerZone.ContainerArgOverrides.GetOverridesFor(component.GetName())
+		if argOverrides == nil || argOverrides.Raw == nil {
+			continue
+		}
+
+		_, notices, err := certmanagerconfigs.MigrateOverrides(component.GetName(), version, argOverrides.Raw)
+		if err != nil {
+			continue
+		}
+
+		for _, notice := range notices {
+			res = append(res, operatorsv1alpha1.ContainerArgOverrideNotice{
+				Component: notice.Component,
+				Flag:      notice.Flag,
+				Type:      string(notice.Type),
+				Message:   notice.Message,
+			})
+		}
+	}
+
+	return res
+}
+
 // newDeployment returns a Deployment object for a given CertManagerComponent
 // and CertManagerDeployment CustomResource
 func newDeployment(comp componentry.CertManagerComponent, cr operatorsv1alpha1.CertManagerDeployment, cstm DeploymentCustomizations) *appsv1.Deployment {
@@ -249,12 +203,20 @@ func argSliceOf(data []byte, schema runtime.Object) []string {
 	var flagMap map[string]interface{}
 	json.Unmarshal(f, &flagMap) //unhandled error
 
+	// flags are sorted so that the resulting arguments, and so the pod template, are stable
+	// across reconciles. Otherwise every apply would roll out the deployment again.
+	flagNames := make([]string, 0, len(flagMap))
+	for k := range flagMap {
+		flagNames = append(flagNames, k)
+	}
+	sort.Strings(flagNames)
+
 	// TODO: consider enforcing a more-correct argument structure
 	// by allowing the config types to reference keys as custom types
 	// and then doing some type assertions against map[string]interface{}
 	// when they get marshaled as JSON objects.
-	for k, v := range flagMap {
-		switch z := v.(type) {
+	for _, k := range flagNames {
+		switch z := flagMap[k].(type) {
 		case string, bool, float64:
 			args = append(args, fmt.Sprintf("--%s=%v", k, z))
 		case []interface{}:
@@ -265,6 +227,20 @@ func argSliceOf(data []byte, schema runtime.Object) []string {
 			}
 			val := strings.Join(s, ",")
 			args = append(args, fmt.Sprintf("--%s=%v", k, val))
+		case map[string]interface{}:
+			// map flags such as feature-gates are passed as a comma-separated list of key=value
+			// pairs, also sorted by key.
+			keys := make([]string, 0, len(z))
+			for key := range z {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			s := make([]string, len(keys))
+			for i, key := range keys {
+				s[i] = fmt.Sprintf("%s=%v", key, z[key])
+			}
+			val := strings.Join(s, ",")
+			args = append(args, fmt.Sprintf("--%s=%v", k, val))
 		default:
 			// TODO implement some kind of logger here
 		}
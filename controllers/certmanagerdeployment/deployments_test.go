@@ -0,0 +1,50 @@
+package certmanagerdeployment
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+	"github.com/komish/cmd-operator-dev/cmdoputils"
+	"github.com/komish/cmd-operator-dev/controllers/componentry"
+	certmanagerconfigs "github.com/komish/cmd-operator-dev/controllers/configs"
+)
+
+var _ = Describe("Container arguments", func() {
+	schema := certmanagerconfigs.GetEmptyConfigFor("controller", componentry.CertManagerDefaultVersion)
+	config := []byte(`{"flags":{"v":2,"leader-elect":true,"cluster-resource-namespace":"cert-manager","dns01-recursive-nameservers":["1.1.1.1:53","8.8.8.8:53"],"feature-gates":{"b":true,"a":false,"c":true}}}`)
+
+	It("Should sort all flags and the keys of map flags", func() {
+		Expect(argSliceOf(config, schema)).To(Equal([]string{
+			"--cluster-resource-namespace=cert-manager",
+			"--dns01-recursive-nameservers=1.1.1.1:53,8.8.8.8:53",
+			"--feature-gates=a=false,b=true,c=true",
+			"--leader-elect=true",
+			"--v=2",
+		}))
+	})
+
+	It("Should render the same arguments every time", func() {
+		first := argSliceOf(config, schema)
+		for i := 0; i < 50; i++ {
+			Expect(argSliceOf(config, schema)).To(Equal(first))
+		}
+	})
+
+	It("Should render the same arguments for the managed deployments every time", func() {
+		getter := ResourceGetter{CustomResource: operatorsv1alpha1.CertManagerDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec: operatorsv1alpha1.CertManagerDeploymentSpec{
+				Version: cmdoputils.GetStringPointer(componentry.CertManagerDefaultVersion),
+			},
+		}}
+
+		first := getter.GetDeployments()
+		for i := 0; i < 50; i++ {
+			for j, deploy := range getter.GetDeployments() {
+				Expect(deploy.Spec.Template.Spec.Containers[0].Args).To(Equal(first[j].Spec.Template.Spec.Containers[0].Args))
+			}
+		}
+	})
+})
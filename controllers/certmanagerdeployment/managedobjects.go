@@ -0,0 +1,251 @@
+package certmanagerdeployment
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/go-logr/logr"
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+	"github.com/komish/cmd-operator-dev/cmdoputils"
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// managedKind describes how the objects of a single kind managed by a CertManagerDeployment
+// are reconciled by reconcileManagedKind.
+type managedKind struct {
+	// kind is the Kind of the managed objects, used in logs and metrics.
+	kind string
+	// plural is the plural form of kind, used in logs.
+	plural string
+	// desired returns the objects of this kind expected to exist for the CertManagerDeployment.
+	desired func(rg *ResourceGetter) ([]managedObject, error)
+	// empty returns an empty object of this kind, used to query the API for existing objects.
+	empty func() managedObject
+	// compare returns the fields of an object that are compared with an existing object, keyed by
+	// the name used to report them in logs. An existing object is only applied if one of them differs,
+	// following the merge rules of fieldsMatch. When it is applied, only the fields set by the operator are
+	// merged into it, and the API server decides whether anything changed.
+	compare func(obj managedObject) map[string]interface{}
+	// owned is true if the CertManagerDeployment is set as the controller of the objects.
+	owned bool
+	// creating and updated are the events emitted on the CertManagerDeployment when an object
+	// is created, and when applying it changed an existing object.
+	creating Event
+	updated  Event
+}
+
+// managedKinds are the kinds reconciled for a CertManagerDeployment in the order they are reconciled.
+var managedKinds = []managedKind{
+	managedCRDs,
+	managedNamespaces,
+	managedServiceAccounts,
+	managedRoles,
+	managedRoleBindings,
+	managedClusterRoles,
+	managedClusterRoleBindings,
+	managedDeployments,
+	managedServices,
+	managedMutatingWebhooks,
+	managedValidatingWebhooks,
+}
+
+// reconcileManagedKind reconciles all objects of the managed kind mk for a given CertManagerDeployment resource.
+// Every object is reconciled even if reconciling another object fails, and all errors are returned together.
+func (r *CertManagerDeploymentReconciler) reconcileManagedKind(instance *operatorsv1alpha1.CertManagerDeployment, mk managedKind, reqLogger logr.Logger) error {
+	reqLogger.Info("Starting reconciliation: " + mk.plural)
+	defer reqLogger.Info("Ending reconciliation: " + mk.plural)
+
+	timer := prometheus.NewTimer(managedObjectReconcileDuration.WithLabelValues(mk.kind))
+	defer timer.ObserveDuration()
+
+	getter := ResourceGetter{CustomResource: *instance}
+	objs, err := mk.desired(&getter)
+	if err != nil {
+		reqLogger.Error(err, "Failed to get desired objects", "Kind", mk.kind)
+		managedObjectErrors.WithLabelValues(mk.kind).Inc()
+		return err
+	}
+
+	var errs []error
+	for _, obj := range objs {
+		if err := r.reconcileManagedObject(instance, mk, obj, reqLogger); err != nil {
+			reqLogger.Error(err, "Failed to reconcile "+mk.kind, mk.kind+".Name", objectName(obj))
+			managedObjectErrors.WithLabelValues(mk.kind).Inc()
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// reconcileManagedObject creates obj if it does not exist, or applies it if the fields compared
+// for the managed kind do not match the existing object.
+func (r *CertManagerDeploymentReconciler) reconcileManagedObject(
+	instance *operatorsv1alpha1.CertManagerDeployment,
+	mk managedKind,
+	obj managedObject,
+	reqLogger logr.Logger) error {
+	name := objectName(obj)
+
+	if mk.owned {
+		if err := controllerutil.SetControllerReference(instance, obj, r.Scheme); err != nil {
+			return err
+		}
+	}
+
+	found := mk.empty()
+	err := r.Get(context.TODO(), types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, found)
+	if err != nil && apierrors.IsNotFound(err) {
+		reqLogger.Info("Creating "+mk.kind, mk.kind+".Name", name)
+		r.Eventf(instance, mk.creating.etype, mk.creating.reason, "%s: %s", mk.creating.message, name)
+		if err := r.applyManagedObject(instance, obj); err != nil {
+			return err
+		}
+
+		managedObjectOperations.WithLabelValues(mk.kind, operationCreate).Inc()
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	matches, keysAndValues, err := compareManagedFields(mk.compare(obj), mk.compare(found))
+	if err != nil { // err indicates a marshaling problem
+		return err
+	}
+
+	if matches {
+		managedObjectOperations.WithLabelValues(mk.kind, operationUnchanged).Inc()
+		return nil
+	}
+
+	// The object exists but differs. Apply it, and let the API server merge in the fields the operator owns.
+	reqLogger.Info(mk.kind+" already exists, but needs an update.", append([]interface{}{mk.kind + ".Name", name}, keysAndValues...)...)
+	if err := r.applyManagedObject(instance, obj); err != nil {
+		return err
+	}
+
+	// An apply that changes nothing leaves the resourceVersion as it was.
+	if obj.GetResourceVersion() == found.GetResourceVersion() {
+		managedObjectOperations.WithLabelValues(mk.kind, operationUnchanged).Inc()
+		return nil
+	}
+
+	reqLogger.Info("Updated "+mk.kind+".", mk.kind+".Name", name)
+	r.Eventf(instance, mk.updated.etype, mk.updated.reason, "%s: %s", mk.updated.message, name)
+	managedObjectOperations.WithLabelValues(mk.kind, operationUpdate).Inc()
+	return nil
+}
+
+// compareManagedFields returns true if every desired field matches the same field in found. The result
+// of each comparison is also returned as logger key/value pairs in the format HasExpected<Field>, bool.
+func compareManagedFields(desired, found map[string]interface{}) (bool, []interface{}, error) {
+	fields := make([]string, 0, len(desired))
+	for field := range desired {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	matches := true
+	var keysAndValues []interface{}
+	for _, field := range fields {
+		desiredInterface, err := cmdoputils.Interfacer{Data: desired[field]}.ToJSONInterface()
+		if err != nil {
+			return false, nil, err
+		}
+		foundInterface, err := cmdoputils.Interfacer{Data: found[field]}.ToJSONInterface()
+		if err != nil {
+			return false, nil, err
+		}
+
+		match := fieldsMatch(desiredInterface, foundInterface)
+		matches = matches && match
+		keysAndValues = append(keysAndValues, "HasExpected"+field, match)
+	}
+
+	return matches, keysAndValues, nil
+}
+
+// fieldsMatch returns true if the JSON form of a desired field matches the JSON form of the field in an
+// existing object, following the rules server-side apply uses to merge the desired field into it:
+//
+//   - Keys of an existing map that are not set in the desired map are kept, such as fields defaulted by the
+//     API server or set by other controllers, so they are not differences. Unset desired values are ignored.
+//   - Lists of objects that have a name, such as containers, ports and webhooks, are merged by name.
+//   - Any other list is replaced, so it must match in length and order. Container args are ordered.
+func fieldsMatch(desired, found interface{}) bool {
+	switch d := desired.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		f, ok := found.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range d {
+			if !fieldsMatch(v, f[k]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		f, ok := found.([]interface{})
+		if !ok {
+			return false
+		}
+		if named(d) {
+			// elements added by other managers are kept when merging by name.
+			for _, element := range d {
+				if !fieldsMatch(element, elementNamed(f, element.(map[string]interface{})["name"])) {
+					return false
+				}
+			}
+			return true
+		}
+		if len(f) != len(d) {
+			return false
+		}
+		for i := range d {
+			if !fieldsMatch(d[i], f[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(desired, found)
+	}
+}
+
+// named returns true if every element of the list is an object with a name.
+func named(list []interface{}) bool {
+	if len(list) == 0 {
+		return false
+	}
+
+	for _, element := range list {
+		m, ok := element.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := m["name"].(string); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// elementNamed returns the object in the list with the name, or nil if there is none.
+func elementNamed(list []interface{}, name interface{}) interface{} {
+	for _, element := range list {
+		if m, ok := element.(map[string]interface{}); ok && m["name"] == name {
+			return element
+		}
+	}
+
+	return nil
+}
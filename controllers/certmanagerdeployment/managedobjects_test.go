@@ -0,0 +1,222 @@
+package certmanagerdeployment
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+	"github.com/komish/cmd-operator-dev/cmdoputils"
+	"github.com/komish/cmd-operator-dev/controllers/componentry"
+)
+
+var _ = Describe("Managed kinds", func() {
+	getter := ResourceGetter{CustomResource: operatorsv1alpha1.CertManagerDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: operatorsv1alpha1.CertManagerDeploymentSpec{
+			Version: cmdoputils.GetStringPointer(componentry.CertManagerDefaultVersion),
+		},
+	}}
+
+	for _, mk := range managedKinds {
+		mk := mk
+		if mk.kind == "CustomResourceDefinition" {
+			// CRDs are read from disk relative to the operator's working directory.
+			continue
+		}
+
+		Context("When getting the desired state for "+mk.plural, func() {
+			It("Should return objects of the same type as the empty object", func() {
+				objs, err := mk.desired(&getter)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(objs).ToNot(BeEmpty())
+				for _, obj := range objs {
+					Expect(kindOf(obj)).To(Equal(mk.kind))
+					Expect(obj).To(BeAssignableToTypeOf(mk.empty()))
+				}
+			})
+
+			It("Should render the same objects every time", func() {
+				// existing objects are applied when they differ from a render, so any difference
+				// between renders would change them, and roll out deployments again.
+				first, err := mk.desired(&getter)
+				Expect(err).ToNot(HaveOccurred())
+				for i := 0; i < 20; i++ {
+					objs, err := mk.desired(&getter)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(objs).To(Equal(first))
+				}
+			})
+
+		})
+	}
+})
+
+// applyClient is a client that emulates server-side apply requests. Applied objects are given
+// resourceVersion, and applies that are not forced fail if conflicts are set.
+type applyClient struct {
+	client.Client
+	resourceVersion string
+	conflicts       []metav1.StatusCause
+	forced          []bool
+}
+
+func (c *applyClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	Expect(patch).To(Equal(client.Apply))
+	patchOpts := &client.PatchOptions{}
+	patchOpts.ApplyOptions(opts)
+	Expect(patchOpts.FieldManager).To(Equal(fieldManager))
+
+	forced := patchOpts.Force != nil && *patchOpts.Force
+	c.forced = append(c.forced, forced)
+	if len(c.conflicts) > 0 && !forced {
+		return apierrors.NewApplyConflict(c.conflicts, "Apply failed with conflicts")
+	}
+
+	obj.(metav1.Object).SetResourceVersion(c.resourceVersion)
+	return nil
+}
+
+var _ = Describe("Applying managed objects", func() {
+	var (
+		c        *applyClient
+		recorder *record.FakeRecorder
+		r        *CertManagerDeploymentReconciler
+		instance *operatorsv1alpha1.CertManagerDeployment
+		svc      *corev1.Service
+	)
+
+	BeforeEach(func() {
+		existing := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "cert-manager", Name: "cert-manager", ResourceVersion: "1"}}
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(operatorsv1alpha1.AddToScheme(s)).To(Succeed())
+		c = &applyClient{Client: fake.NewFakeClientWithScheme(s, existing), resourceVersion: "1"}
+		recorder = record.NewFakeRecorder(10)
+		r = &CertManagerDeploymentReconciler{Client: c, Log: logf.Log, Scheme: s, EventRecorder: recorder}
+		instance = &operatorsv1alpha1.CertManagerDeployment{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}
+		svc = &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "cert-manager", Name: "cert-manager", Labels: map[string]string{"app": "cert-manager"}}}
+	})
+
+	It("Should not apply existing objects whose compared fields match", func() {
+		existing := &corev1.Service{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: "cert-manager", Name: "cert-manager"}, existing)).To(Succeed())
+		existing.Labels = map[string]string{"app": "cert-manager", "team": "platform"}
+		existing.Spec.ClusterIP = "10.0.0.1"
+		Expect(c.Update(context.TODO(), existing)).To(Succeed())
+
+		Expect(r.reconcileManagedObject(instance, managedServices, svc, logf.Log)).To(Succeed())
+		Expect(c.forced).To(BeEmpty())
+		Expect(recorder.Events).ToNot(Receive())
+	})
+
+	It("Should apply existing objects without reporting unchanged ones as updated", func() {
+		Expect(r.reconcileManagedObject(instance, managedServices, svc, logf.Log)).To(Succeed())
+		Expect(c.forced).To(Equal([]bool{false}))
+		Expect(recorder.Events).ToNot(Receive())
+	})
+
+	It("Should report existing objects changed by the apply as updated", func() {
+		c.resourceVersion = "2"
+		Expect(r.reconcileManagedObject(instance, managedServices, svc, logf.Log)).To(Succeed())
+		Expect(recorder.Events).To(Receive(ContainSubstring(updatedManagedService.reason)))
+	})
+
+	It("Should apply patched fields to existing service accounts", func() {
+		sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "cert-manager", Name: "cert-manager"}}
+		Expect(c.Create(context.TODO(), sa.DeepCopy())).To(Succeed())
+
+		sa.Annotations = map[string]string{"eks.amazonaws.com/role-arn": "arn:aws:iam::111122223333:role/cert-manager"}
+		c.resourceVersion = "2"
+		Expect(r.reconcileManagedObject(instance, managedServiceAccounts, sa, logf.Log)).To(Succeed())
+		Expect(c.forced).To(Equal([]bool{false}))
+		Expect(recorder.Events).To(Receive(ContainSubstring(updatedManagedServiceAccount.reason)))
+	})
+
+	It("Should take over fields owned by other managers and report them", func() {
+		c.conflicts = []metav1.StatusCause{{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Message: `conflict with "kubectl-edit" using v1`,
+			Field:   ".spec.type",
+		}}
+		Expect(r.reconcileManagedObject(instance, managedServices, svc, logf.Log)).To(Succeed())
+		Expect(c.forced).To(Equal([]bool{false, true}))
+
+		var event string
+		Expect(recorder.Events).To(Receive(&event))
+		Expect(event).To(ContainSubstring(fieldsTakenOverManagedObject.reason))
+		Expect(event).To(ContainSubstring(`Service cert-manager/cert-manager: .spec.type (conflict with "kubectl-edit" using v1)`))
+	})
+
+	It("Should return errors other than conflicts without forcing", func() {
+		c.conflicts = nil
+		r.Client = &failingPatchClient{Client: c, err: apierrors.NewForbidden(schema.GroupResource{Resource: "services"}, "cert-manager", errors.New("denied"))}
+		Expect(apierrors.IsForbidden(r.applyManagedObject(instance, svc))).To(BeTrue())
+		Expect(c.forced).To(BeEmpty())
+	})
+})
+
+// failingPatchClient is a client whose patches fail with err.
+type failingPatchClient struct {
+	client.Client
+	err error
+}
+
+func (c *failingPatchClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return c.err
+}
+
+var _ = Describe("Comparing managed fields", func() {
+	match := func(desired, found interface{}) bool {
+		matches, _, err := compareManagedFields(map[string]interface{}{"Field": desired}, map[string]interface{}{"Field": found})
+		Expect(err).ToNot(HaveOccurred())
+		return matches
+	}
+
+	It("Should ignore fields that are only set on the existing object", func() {
+		desired := corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "https", Port: 443}}}
+		found := corev1.ServiceSpec{ClusterIP: "10.0.0.1", Ports: []corev1.ServicePort{{Name: "https", Port: 443, Protocol: corev1.ProtocolTCP}}}
+		Expect(match(desired, found)).To(BeTrue())
+
+		found.Ports[0].Port = 8443
+		Expect(match(desired, found)).To(BeFalse())
+	})
+
+	It("Should merge lists of named objects by name", func() {
+		desired := []corev1.Container{{Name: "controller", Image: "cert-manager"}}
+		found := []corev1.Container{{Name: "sidecar", Image: "proxy"}, {Name: "controller", Image: "cert-manager"}}
+		Expect(match(desired, found)).To(BeTrue())
+
+		found[1].Image = "other"
+		Expect(match(desired, found)).To(BeFalse())
+	})
+
+	It("Should compare other lists in order", func() {
+		Expect(match([]string{"--v=2", "--leader-elect"}, []string{"--v=2", "--leader-elect"})).To(BeTrue())
+		Expect(match([]string{"--v=2", "--leader-elect"}, []string{"--leader-elect", "--v=2"})).To(BeFalse())
+		Expect(match([]string{"--v=2"}, []string{"--v=2", "--leader-elect"})).To(BeFalse())
+	})
+
+	It("Should report each compared field", func() {
+		matches, keysAndValues, err := compareManagedFields(
+			map[string]interface{}{"Labels": map[string]string{"app": "cert-manager"}, "Annotations": map[string]string{"a": "b"}},
+			map[string]interface{}{"Labels": map[string]string{"app": "cert-manager"}, "Annotations": map[string]string{"a": "c"}},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matches).To(BeFalse())
+		Expect(keysAndValues).To(Equal([]interface{}{"HasExpectedAnnotations", false, "HasExpectedLabels", true}))
+	})
+})
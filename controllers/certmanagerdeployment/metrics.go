@@ -0,0 +1,48 @@
+package certmanagerdeployment
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// operationCreate is recorded when a managed object did not exist and was created.
+	operationCreate = "create"
+	// operationUpdate is recorded when a managed object did not match its desired state and was updated.
+	operationUpdate = "update"
+	// operationUnchanged is recorded when a managed object already matched its desired state.
+	operationUnchanged = "unchanged"
+)
+
+var (
+	// managedObjectOperations counts the outcome of reconciling each managed object.
+	managedObjectOperations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "certmanagerdeployment_managed_object_operations_total",
+			Help: "Number of managed objects reconciled by kind and operation (create, update, unchanged).",
+		},
+		[]string{"kind", "operation"},
+	)
+
+	// managedObjectErrors counts the errors encountered reconciling managed objects.
+	managedObjectErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "certmanagerdeployment_managed_object_errors_total",
+			Help: "Number of errors encountered reconciling managed objects by kind.",
+		},
+		[]string{"kind"},
+	)
+
+	// managedObjectReconcileDuration tracks how long it takes to reconcile all objects of a managed kind.
+	managedObjectReconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "certmanagerdeployment_managed_kind_reconcile_duration_seconds",
+			Help: "Time taken to reconcile all managed objects of a kind.",
+		},
+		[]string{"kind"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(managedObjectOperations, managedObjectErrors, managedObjectReconcileDuration)
+}
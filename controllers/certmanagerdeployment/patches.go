@@ -0,0 +1,191 @@
+package certmanagerdeployment
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+	adregv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/yaml"
+)
+
+// patchResult is the outcome of applying a single patch to a single object.
+type patchResult struct {
+	patch  string
+	object string
+	err    error
+}
+
+// managedObject is a managed resource that can be patched.
+type managedObject interface {
+	runtime.Object
+	metav1.Object
+}
+
+// kindOf returns the kind of a managed object. Objects rendered by the ResourceGetter
+// do not have their TypeMeta populated, so the kind is derived from the type.
+func kindOf(obj runtime.Object) string {
+	switch obj.(type) {
+	case *appsv1.Deployment:
+		return "Deployment"
+	case *corev1.Service:
+		return "Service"
+	case *corev1.ServiceAccount:
+		return "ServiceAccount"
+	case *corev1.Namespace:
+		return "Namespace"
+	case *rbacv1.Role:
+		return "Role"
+	case *rbacv1.RoleBinding:
+		return "RoleBinding"
+	case *rbacv1.ClusterRole:
+		return "ClusterRole"
+	case *rbacv1.ClusterRoleBinding:
+		return "ClusterRoleBinding"
+	case *apiextv1.CustomResourceDefinition:
+		return "CustomResourceDefinition"
+	case *adregv1.MutatingWebhookConfiguration:
+		return "MutatingWebhookConfiguration"
+	case *adregv1.ValidatingWebhookConfiguration:
+		return "ValidatingWebhookConfiguration"
+	default:
+		return reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+	}
+}
+
+// patchTargets returns true if the patch's target selects obj.
+func patchTargets(patch operatorsv1alpha1.ObjectPatch, obj managedObject) bool {
+	if patch.Target.Kind != kindOf(obj) {
+		return false
+	}
+
+	if patch.Target.Name != "" && patch.Target.Name != obj.GetName() {
+		return false
+	}
+
+	if patch.Target.Namespace != "" && patch.Target.Namespace != obj.GetNamespace() {
+		return false
+	}
+
+	return true
+}
+
+// applyPatches applies the patches defined in the CustomResource to obj in order, modifying
+// obj in place. A patch that fails to apply is skipped and the result is recorded so that it can
+// be reported by GetPatchStatuses.
+func (r *ResourceGetter) applyPatches(obj managedObject) {
+	for _, patch := range r.CustomResource.Spec.DangerZone.Patches {
+		if !patchTargets(patch, obj) {
+			continue
+		}
+
+		err := applyPatch(patch, obj)
+		r.patchResults = append(r.patchResults, patchResult{
+			patch:  patch.Name,
+			object: fmt.Sprintf("%s:%s/%s", kindOf(obj), obj.GetNamespace(), obj.GetName()),
+			err:    err,
+		})
+	}
+}
+
+// applyPatch applies a single patch to obj. obj is not modified if an error is returned.
+func applyPatch(patch operatorsv1alpha1.ObjectPatch, obj managedObject) error {
+	patchJSON, err := yaml.YAMLToJSON([]byte(patch.Patch))
+	if err != nil {
+		return fmt.Errorf("unable to parse patch: %s", err)
+	}
+
+	original, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	switch patch.Type {
+	case operatorsv1alpha1.StrategicMergePatchType:
+		patched, err = strategicpatch.StrategicMergePatch(original, patchJSON, obj)
+	case operatorsv1alpha1.JSON6902PatchType:
+		var ops jsonpatch.Patch
+		ops, err = jsonpatch.DecodePatch(patchJSON)
+		if err == nil {
+			patched, err = ops.Apply(original)
+		}
+	default:
+		err = fmt.Errorf("unknown patch type %q", patch.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	// decode into a new object so that fields removed by the patch are not retained.
+	result := reflect.New(reflect.TypeOf(obj).Elem())
+	if err := json.Unmarshal(patched, result.Interface()); err != nil {
+		return fmt.Errorf("patched object is invalid: %s", err)
+	}
+
+	reflect.ValueOf(obj).Elem().Set(result.Elem())
+	return nil
+}
+
+// GetPatchStatuses renders every managed object and returns the result of
+// applying each patch defined in the CustomResource.
+func (r *ResourceGetter) GetPatchStatuses() []operatorsv1alpha1.ObjectPatchStatus {
+	res := make([]operatorsv1alpha1.ObjectPatchStatus, 0)
+	if len(r.CustomResource.Spec.DangerZone.Patches) == 0 {
+		return res
+	}
+
+	// render all objects so that patch results are recorded.
+	r.patchResults = nil
+	r.GetNamespace()
+	r.GetCRDs()
+	r.GetServiceAccounts()
+	r.GetRoles()
+	r.GetRoleBindings()
+	r.GetClusterRoles()
+	r.GetClusterRoleBindings()
+	r.GetDeployments()
+	r.GetServices()
+	r.GetMutatingWebhooks()
+	r.GetValidatingWebhooks()
+
+	for _, patch := range r.CustomResource.Spec.DangerZone.Patches {
+		status := operatorsv1alpha1.ObjectPatchStatus{Name: patch.Name}
+		var failures []string
+		for _, result := range r.patchResults {
+			if result.patch != patch.Name {
+				continue
+			}
+
+			if result.err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %s", result.object, result.err))
+				continue
+			}
+
+			status.PatchedObjects = append(status.PatchedObjects, result.object)
+		}
+
+		switch {
+		case len(failures) > 0:
+			status.Message = "unable to apply patch to " + strings.Join(failures, "; ")
+		case len(status.PatchedObjects) == 0:
+			status.Message = "patch target did not match any managed objects"
+		default:
+			status.Applied = true
+		}
+
+		res = append(res, status)
+	}
+
+	return res
+}
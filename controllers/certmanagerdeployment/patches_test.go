@@ -0,0 +1,162 @@
+package certmanagerdeployment
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+	"github.com/komish/cmd-operator-dev/cmdoputils"
+	"github.com/komish/cmd-operator-dev/controllers/componentry"
+)
+
+var _ = Describe("DangerZone patches", func() {
+	var getter ResourceGetter
+
+	BeforeEach(func() {
+		getter = ResourceGetter{CustomResource: operatorsv1alpha1.CertManagerDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec: operatorsv1alpha1.CertManagerDeploymentSpec{
+				Version: cmdoputils.GetStringPointer(componentry.CertManagerDefaultVersion),
+			},
+		}}
+	})
+
+	Context("When a strategic merge patch targets a deployment by name", func() {
+		BeforeEach(func() {
+			getter.CustomResource.Spec.DangerZone.Patches = []operatorsv1alpha1.ObjectPatch{
+				{
+					Name:   "proxy-env",
+					Target: operatorsv1alpha1.ObjectPatchTarget{Kind: "Deployment", Name: "cert-manager-controller"},
+					Type:   operatorsv1alpha1.StrategicMergePatchType,
+					Patch: `
+spec:
+  template:
+    spec:
+      containers:
+      - name: cert-manager
+        env:
+        - name: HTTPS_PROXY
+          value: http://proxy:3128
+`,
+				},
+			}
+		})
+
+		It("Should only modify the targeted deployment", func() {
+			deploys := getter.GetDeployments()
+			Expect(deploys).To(HaveLen(3))
+			for _, deploy := range deploys {
+				env := deploy.Spec.Template.Spec.Containers[0].Env
+				if deploy.GetName() == "cert-manager-controller" {
+					Expect(env).To(ContainElement(corev1.EnvVar{Name: "HTTPS_PROXY", Value: "http://proxy:3128"}))
+					Expect(deploy.Spec.Template.Spec.Containers[0].Args).ToNot(BeEmpty())
+				} else {
+					Expect(env).ToNot(ContainElement(corev1.EnvVar{Name: "HTTPS_PROXY", Value: "http://proxy:3128"}))
+				}
+			}
+		})
+
+		It("Should report the patch as applied", func() {
+			statuses := getter.GetPatchStatuses()
+			Expect(statuses).To(HaveLen(1))
+			Expect(statuses[0].Applied).To(BeTrue())
+			Expect(statuses[0].PatchedObjects).To(ConsistOf("Deployment:" + componentry.CertManagerDeploymentNamespace + "/cert-manager-controller"))
+		})
+	})
+
+	Context("When a JSON6902 patch targets all services", func() {
+		BeforeEach(func() {
+			getter.CustomResource.Spec.DangerZone.Patches = []operatorsv1alpha1.ObjectPatch{
+				{
+					Name:   "service-annotation",
+					Target: operatorsv1alpha1.ObjectPatchTarget{Kind: "Service"},
+					Type:   operatorsv1alpha1.JSON6902PatchType,
+					Patch:  `[{"op": "add", "path": "/metadata/annotations", "value": {"example.com/team": "platform"}}]`,
+				},
+			}
+		})
+
+		It("Should modify every service", func() {
+			svcs := getter.GetServices()
+			Expect(svcs).ToNot(BeEmpty())
+			for _, svc := range svcs {
+				Expect(svc.GetAnnotations()).To(HaveKeyWithValue("example.com/team", "platform"))
+			}
+		})
+	})
+
+	Context("When a patch cannot be applied", func() {
+		BeforeEach(func() {
+			getter.CustomResource.Spec.DangerZone.Patches = []operatorsv1alpha1.ObjectPatch{
+				{
+					Name:   "bad-path",
+					Target: operatorsv1alpha1.ObjectPatchTarget{Kind: "ServiceAccount"},
+					Type:   operatorsv1alpha1.JSON6902PatchType,
+					Patch:  `[{"op": "replace", "path": "/does/not/exist", "value": "x"}]`,
+				},
+				{
+					Name:   "no-match",
+					Target: operatorsv1alpha1.ObjectPatchTarget{Kind: "Deployment", Name: "not-managed"},
+					Type:   operatorsv1alpha1.StrategicMergePatchType,
+					Patch:  `metadata: {labels: {foo: bar}}`,
+				},
+			}
+		})
+
+		It("Should leave the object unmodified", func() {
+			unpatched := ResourceGetter{CustomResource: getter.CustomResource}
+			unpatched.CustomResource.Spec.DangerZone.Patches = nil
+			Expect(getter.GetServiceAccounts()).To(Equal(unpatched.GetServiceAccounts()))
+		})
+
+		It("Should report the failure and unmatched patches in status", func() {
+			statuses := getter.GetPatchStatuses()
+			Expect(statuses).To(HaveLen(2))
+			Expect(statuses[0].Name).To(Equal("bad-path"))
+			Expect(statuses[0].Applied).To(BeFalse())
+			Expect(statuses[0].Message).To(ContainSubstring("unable to apply patch"))
+			Expect(statuses[1].Name).To(Equal("no-match"))
+			Expect(statuses[1].Applied).To(BeFalse())
+			Expect(statuses[1].Message).To(ContainSubstring("did not match"))
+		})
+	})
+
+	Context("When patching objects that already exist", func() {
+		It("Should compare the patched fields of every managed kind, so they are applied to existing objects", func() {
+			unpatched := getter
+			for _, mk := range managedKinds {
+				if mk.kind == "CustomResourceDefinition" {
+					// CRDs are read from disk relative to the operator's working directory.
+					continue
+				}
+
+				getter.CustomResource.Spec.DangerZone.Patches = []operatorsv1alpha1.ObjectPatch{
+					{
+						Name:   "team-annotation",
+						Target: operatorsv1alpha1.ObjectPatchTarget{Kind: mk.kind},
+						Type:   operatorsv1alpha1.JSON6902PatchType,
+						Patch:  `[{"op": "add", "path": "/metadata/annotations", "value": {"example.com/team": "platform"}}]`,
+					},
+				}
+
+				existing, err := mk.desired(&unpatched)
+				Expect(err).ToNot(HaveOccurred())
+				patched, err := mk.desired(&getter)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(patched).To(HaveLen(len(existing)))
+
+				for i := range patched {
+					matches, _, err := compareManagedFields(mk.compare(patched[i]), mk.compare(existing[i]))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(matches).To(BeFalse(), mk.kind)
+				}
+
+				statuses := getter.GetPatchStatuses()
+				Expect(statuses).To(HaveLen(1))
+				Expect(statuses[0].Applied).To(BeTrue(), mk.kind)
+			}
+		})
+	})
+})
@@ -0,0 +1,41 @@
+package certmanagerdeployment
+
+import (
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+)
+
+// PodRefresherRunner starts and stops the pod refresher while the operator is running.
+type PodRefresherRunner interface {
+	// Apply starts or stops the pod refresher so that it runs as configured by config, which
+	// is nil if the pod refresher is not configured.
+	Apply(config *operatorsv1alpha1.PodRefresherConfig) error
+}
+
+// reconcilePodRefresher starts or stops the pod refresher as configured by the instance, which is
+// nil if the instance was deleted. The configuration is ignored if the pod refresher was enabled
+// by the operator's flags.
+func (r *CertManagerDeploymentReconciler) reconcilePodRefresher(instance *operatorsv1alpha1.CertManagerDeployment) error {
+	if r.PodRefresher == nil {
+		if instance != nil && instance.Spec.PodRefresher != nil {
+			r.Log.Info("Ignoring spec.podRefresher as the pod refresher was enabled by the operator's flags")
+		}
+		return nil
+	}
+
+	var config *operatorsv1alpha1.PodRefresherConfig
+	if instance != nil {
+		config = instance.Spec.PodRefresher
+	}
+
+	err := r.PodRefresher.Apply(config)
+	if err != nil && instance != nil {
+		r.Eventf(instance,
+			configurePodRefresherFailed.etype,
+			configurePodRefresherFailed.reason,
+			"%s: %s",
+			configurePodRefresherFailed.message,
+			err)
+	}
+
+	return err
+}
@@ -0,0 +1,71 @@
+package certmanagerdeployment
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+)
+
+// fakePodRefresherRunner records the configurations applied to it.
+type fakePodRefresherRunner struct {
+	applied []*operatorsv1alpha1.PodRefresherConfig
+	err     error
+}
+
+func (f *fakePodRefresherRunner) Apply(config *operatorsv1alpha1.PodRefresherConfig) error {
+	f.applied = append(f.applied, config)
+	return f.err
+}
+
+var _ = Describe("Pod refresher configuration", func() {
+	var (
+		runner   *fakePodRefresherRunner
+		recorder *record.FakeRecorder
+		r        *CertManagerDeploymentReconciler
+		instance *operatorsv1alpha1.CertManagerDeployment
+	)
+
+	BeforeEach(func() {
+		runner = &fakePodRefresherRunner{}
+		recorder = record.NewFakeRecorder(10)
+		r = &CertManagerDeploymentReconciler{Log: logf.Log, EventRecorder: recorder, PodRefresher: runner}
+		instance = &operatorsv1alpha1.CertManagerDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec: operatorsv1alpha1.CertManagerDeploymentSpec{
+				PodRefresher: &operatorsv1alpha1.PodRefresherConfig{
+					Enabled:  true,
+					Strategy: operatorsv1alpha1.HTTPReloadPodRefreshStrategyType,
+				},
+			},
+		}
+	})
+
+	It("Should apply the configuration of the instance", func() {
+		Expect(r.reconcilePodRefresher(instance)).To(Succeed())
+		Expect(runner.applied).To(Equal([]*operatorsv1alpha1.PodRefresherConfig{instance.Spec.PodRefresher}))
+	})
+
+	It("Should stop the pod refresher when the instance is deleted", func() {
+		Expect(r.reconcilePodRefresher(nil)).To(Succeed())
+		Expect(runner.applied).To(HaveLen(1))
+		Expect(runner.applied[0]).To(BeNil())
+	})
+
+	It("Should report configurations that could not be applied", func() {
+		runner.err = errors.New("invalid refresh strategy")
+		Expect(r.reconcilePodRefresher(instance)).ToNot(Succeed())
+		Expect(recorder.Events).To(Receive(ContainSubstring(configurePodRefresherFailed.reason)))
+	})
+
+	It("Should ignore the configuration when the pod refresher was enabled by flags", func() {
+		r.PodRefresher = nil
+		Expect(r.reconcilePodRefresher(instance)).To(Succeed())
+		Expect(recorder.Events).ToNot(Receive())
+	})
+})
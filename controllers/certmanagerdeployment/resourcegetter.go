@@ -6,4 +6,7 @@ import operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
 // a CertManagerDeployment CR.
 type ResourceGetter struct {
 	CustomResource operatorsv1alpha1.CertManagerDeployment
+
+	// patchResults records the result of each patch applied while rendering objects.
+	patchResults []patchResult
 }
@@ -8,6 +8,27 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// managedRoles reconciles the Role resources for a given CertManagerDeployment resource.
+var managedRoles = managedKind{
+	kind:   "Role",
+	plural: "roles",
+	desired: func(rg *ResourceGetter) ([]managedObject, error) {
+		var objs []managedObject
+		for _, role := range rg.GetRoles() {
+			objs = append(objs, role)
+		}
+		return objs, nil
+	},
+	empty: func() managedObject { return &rbacv1.Role{} },
+	compare: func(obj managedObject) map[string]interface{} {
+		role := obj.(*rbacv1.Role)
+		return map[string]interface{}{"Rules": role.Rules, "Labels": role.Labels, "Annotations": role.Annotations}
+	},
+	owned:    true,
+	creating: createManagedRole,
+	updated:  updatedManagedRole,
+}
+
 // GetRoles will return new role objects for each CertManagerComponent associated
 // with the CustomResource.
 func (r *ResourceGetter) GetRoles() []*rbacv1.Role {
@@ -21,6 +42,10 @@ func (r *ResourceGetter) GetRoles() []*rbacv1.Role {
 		}
 	}
 
+	for _, role := range roles {
+		r.applyPatches(role)
+	}
+
 	return roles
 }
 
@@ -1,56 +1,32 @@
 package certmanagerdeployment
 
 import (
-	"context"
-
-	"github.com/go-logr/logr"
 	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
 	"github.com/komish/cmd-operator-dev/cmdoputils"
 	"github.com/komish/cmd-operator-dev/controllers/componentry"
 	corev1 "k8s.io/api/core/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-// reconcileServiceAccounts will reconcile the Service Account resources for a given CertManagerDeployment resource.
-func (r *CertManagerDeploymentReconciler) reconcileServiceAccounts(instance *operatorsv1alpha1.CertManagerDeployment, reqLogger logr.Logger) error {
-	reqLogger.Info("Starting reconciliation: service accounts")
-	defer reqLogger.Info("Ending reconciliation: service accounts")
-	var err error
-
-	getter := ResourceGetter{CustomResource: *instance}
-	sas := getter.GetServiceAccounts()
-
-	for _, sa := range sas {
-		if err := controllerutil.SetControllerReference(instance, sa, r.Scheme); err != nil {
-			return err
-		}
-		found := &corev1.ServiceAccount{}
-		err = r.Get(context.TODO(), types.NamespacedName{Name: sa.GetName(), Namespace: sa.GetNamespace()}, found)
-
-		if err != nil && apierrors.IsNotFound(err) {
-			reqLogger.Info("Creating service account", "ServiceAccount.Namespace", sa.GetNamespace(), "ServiceAccount.Name", sa.GetName())
-			r.Eventf(instance,
-				createManagedServiceAccount.etype,
-				createManagedServiceAccount.reason,
-				"%s: %s/%s",
-				createManagedServiceAccount.message,
-				sa.GetNamespace(),
-				sa.GetName())
-			if err := r.Create(context.TODO(), sa); err != nil {
-				return err
-			}
-
-			// successful create. Move onto next iteration.
-			continue
-		} else if err != nil {
-			return err
+// managedServiceAccounts reconciles the ServiceAccount resources for a given CertManagerDeployment resource.
+var managedServiceAccounts = managedKind{
+	kind:   "ServiceAccount",
+	plural: "service accounts",
+	desired: func(rg *ResourceGetter) ([]managedObject, error) {
+		var objs []managedObject
+		for _, sa := range rg.GetServiceAccounts() {
+			objs = append(objs, sa)
 		}
-	}
-
-	return nil
+		return objs, nil
+	},
+	empty: func() managedObject { return &corev1.ServiceAccount{} },
+	compare: func(obj managedObject) map[string]interface{} {
+		sa := obj.(*corev1.ServiceAccount)
+		return map[string]interface{}{"Secrets": sa.Secrets, "ImagePullSecrets": sa.ImagePullSecrets, "AutomountServiceAccountToken": sa.AutomountServiceAccountToken, "Labels": sa.Labels, "Annotations": sa.Annotations}
+	},
+	owned:    true,
+	creating: createManagedServiceAccount,
+	updated:  updatedManagedServiceAccount,
 }
 
 // GetServiceAccounts will return new service account objects for the CR.
@@ -63,6 +39,11 @@ func (r *ResourceGetter) GetServiceAccounts() []*corev1.ServiceAccount {
 		sa := newServiceAccount(component, r.CustomResource)
 		sas = append(sas, sa)
 	}
+
+	for _, sa := range sas {
+		r.applyPatches(sa)
+	}
+
 	return sas
 }
 
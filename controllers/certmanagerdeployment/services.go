@@ -1,125 +1,34 @@
 package certmanagerdeployment
 
 import (
-	"context"
 	"reflect"
 
-	"github.com/go-logr/logr"
-	"github.com/imdario/mergo"
 	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
 	"github.com/komish/cmd-operator-dev/cmdoputils"
 	"github.com/komish/cmd-operator-dev/controllers/componentry"
 	corev1 "k8s.io/api/core/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-// reconcileServices will reconcile the Service resources for a given CertManagerDeployment resource.
-func (r *CertManagerDeploymentReconciler) reconcileServices(instance *operatorsv1alpha1.CertManagerDeployment, reqLogger logr.Logger) error {
-	reqLogger.Info("Starting reconciliation: services")
-	defer reqLogger.Info("Ending reconciliation: services")
-
-	getter := ResourceGetter{CustomResource: *instance}
-	svcs := getter.GetServices()
-
-	// set controller reference on those objects
-	for _, svc := range svcs {
-		if err := controllerutil.SetControllerReference(instance, svc, r.Scheme); err != nil {
-			return err
-		}
-		found := &corev1.Service{}
-		err := r.Get(context.TODO(), types.NamespacedName{Namespace: svc.GetNamespace(), Name: svc.GetName()}, found)
-		if err != nil && apierrors.IsNotFound(err) {
-			reqLogger.Info("Creating Service", "Service.Namespace", svc.GetNamespace(), "Service.Name", svc.GetName())
-			r.Eventf(instance,
-				createManagedService.etype,
-				createManagedService.reason,
-				"%s: %s/%s",
-				createManagedService.message,
-				svc.GetNamespace(), svc.GetName())
-			if err := r.Create(context.TODO(), svc); err != nil {
-				return err
-			}
-
-			// successful create. Move onto next iteration.
-			continue
-		} else if err != nil {
-			return err
-		}
-
-		// the service exists. If it needs updating, update it.
-		genSpecInterface, err := cmdoputils.Interfacer{Data: svc.Spec}.ToJSONInterface()
-		if err != nil { // err indicates a marshaling problem
-			return err
-		}
-		foundSpecInterface, err := cmdoputils.Interfacer{Data: found.Spec}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		genLabelsInterface, err := cmdoputils.Interfacer{Data: svc.Labels}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-		foundLabelsInterface, err := cmdoputils.Interfacer{Data: found.Labels}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		genAnnotsInterface, err := cmdoputils.Interfacer{Data: svc.Annotations}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-		foundAnnotsInterface, err := cmdoputils.Interfacer{Data: found.Annotations}.ToJSONInterface()
-		if err != nil {
-			return err
+// managedServices reconciles the Service resources for a given CertManagerDeployment resource.
+var managedServices = managedKind{
+	kind:   "Service",
+	plural: "services",
+	desired: func(rg *ResourceGetter) ([]managedObject, error) {
+		var objs []managedObject
+		for _, svc := range rg.GetServices() {
+			objs = append(objs, svc)
 		}
-
-		specsMatch := cmdoputils.ObjectsMatch(genSpecInterface, foundSpecInterface)
-		labelsMatch := cmdoputils.ObjectsMatch(genLabelsInterface, foundLabelsInterface)
-		annotsMatch := cmdoputils.ObjectsMatch(genAnnotsInterface, foundAnnotsInterface)
-
-		if !(specsMatch && labelsMatch && annotsMatch) {
-			reqLogger.Info("Service already exists, but needs an update.",
-				"Service.Name", svc.GetName(),
-				"HasExpectedLabels", labelsMatch,
-				"HasExpectedAnnotation", annotsMatch,
-				"HasExpectedSpec", specsMatch)
-			r.Eventf(instance, updatingManagedService.etype, updatingManagedService.reason, "%s: %s/%s", updatingManagedService.message, svc.GetNamespace(), svc.GetName())
-
-			// modify the state of the old object to post to API
-			updated := found.DeepCopy()
-
-			if !specsMatch {
-				// update our local copy with values to keys as defined in our generated spec.
-				err := mergo.Merge(&updated.Spec, svc.Spec, mergo.WithOverride)
-				if err != nil {
-					// Some problem merging the specs
-					return err
-				}
-			}
-
-			if !labelsMatch {
-				// TODO(): should we avoid clobbering and instead just add our labels?
-				updated.ObjectMeta.Labels = svc.GetLabels()
-			}
-
-			if !annotsMatch {
-				// TODO(): should we avoid clobbering and instead just add our annotations?
-				updated.ObjectMeta.Annotations = svc.GetAnnotations()
-			}
-
-			reqLogger.Info("Updating Service.", "Service.Name", svc.GetName(), "Service.Namespace", svc.GetNamespace())
-			if err := r.Update(context.TODO(), updated); err != nil {
-				return err
-			}
-
-			r.Eventf(instance, updatedManagedService.etype, updatedManagedService.reason, "%s: %s/%s", updatedManagedService.message, svc.GetNamespace(), svc.GetName())
-		}
-	}
-	return nil
+		return objs, nil
+	},
+	empty: func() managedObject { return &corev1.Service{} },
+	compare: func(obj managedObject) map[string]interface{} {
+		svc := obj.(*corev1.Service)
+		return map[string]interface{}{"Spec": svc.Spec, "Labels": svc.Labels, "Annotations": svc.Annotations}
+	},
+	owned:    true,
+	creating: createManagedService,
+	updated:  updatedManagedService,
 }
 
 // GetServices will return new services for the CR.
@@ -136,6 +45,10 @@ func (r *ResourceGetter) GetServices() []*corev1.Service {
 		}
 	}
 
+	for _, svc := range svcs {
+		r.applyPatches(svc)
+	}
+
 	return svcs
 }
 
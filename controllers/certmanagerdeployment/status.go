@@ -3,6 +3,7 @@ package certmanagerdeployment
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 
@@ -13,6 +14,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -34,6 +36,32 @@ func (r *CertManagerDeploymentReconciler) reconcileStatus(instance *operatorsv1a
 	r.reconcileStatusDeploymentsHealthy(status, getter, reqLogger)
 	r.reconcileStatusCRDsHealthy(status, getter, reqLogger)
 	r.reconcileStatusPhase(status)
+	r.reconcileStatusOverrideNotices(status, getter)
+	r.reconcileStatusPatches(status, getter)
+	// the certificate health is summarized by the certificateHealthReporter.
+	status.CertificateHealth = instance.Status.CertificateHealth
+
+	// let the user know when the handling of their overrides has changed.
+	if !reflect.DeepEqual(status.OverrideNotices, instance.Status.OverrideNotices) {
+		for _, notice := range status.OverrideNotices {
+			r.Eventf(instance,
+				containerArgOverrideMigrated.etype,
+				containerArgOverrideMigrated.reason,
+				"%s: %s --%s: %s",
+				containerArgOverrideMigrated.message,
+				notice.Component,
+				notice.Flag,
+				notice.Message)
+		}
+	}
+
+	// keep the timestamps of anything that has not changed, and skip the update if nothing has so that
+	// writing the status does not trigger another reconciliation.
+	preserveUnchangedTimestamps(status, &instance.Status)
+	if equality.Semantic.DeepEqual(*status, instance.Status) {
+		reqLogger.V(2).Info("Status is unchanged for object", "CertManagerDeployment.Name", instance.GetName())
+		return nil
+	}
 
 	// Update the object with new status
 	obj.Status = *status
@@ -416,6 +444,25 @@ func updateStatusCRDConditions(inStatus *operatorsv1alpha1.CertManagerDeployment
 	return inStatus
 }
 
+// preserveUnchangedTimestamps sets the LastUpdateTime of the conditions in inStatus to those in
+// previous where they are otherwise unchanged, so that they only move when something changed.
+func preserveUnchangedTimestamps(inStatus, previous *operatorsv1alpha1.CertManagerDeploymentStatus) *operatorsv1alpha1.CertManagerDeploymentStatus {
+	previousConditions := conditionsAsMap(previous.Conditions)
+	for i, cond := range inStatus.Conditions {
+		prev, ok := previousConditions[cond.Type]
+		if !ok {
+			continue
+		}
+
+		prev.LastUpdateTime = cond.LastUpdateTime
+		if prev == cond {
+			inStatus.Conditions[i].LastUpdateTime = previousConditions[cond.Type].LastUpdateTime
+		}
+	}
+
+	return inStatus
+}
+
 // conditionsAsMap takes in a slice of conditions for the CertManagerDeployment resource and returns a map where the key is
 // the condition.Type and the value is the condition itself.
 func conditionsAsMap(conditions []operatorsv1alpha1.CertManagerDeploymentCondition) map[operatorsv1alpha1.CertManagerDeploymentConditionType]operatorsv1alpha1.CertManagerDeploymentCondition {
@@ -427,3 +474,27 @@ func conditionsAsMap(conditions []operatorsv1alpha1.CertManagerDeploymentConditi
 
 	return c
 }
+
+// reconcileStatusOverrideNotices is a subreconciliation function called by ReconcileStatus that reports
+// container argument overrides that were affected by flag changes in the requested version of cert-manager.
+func (r *CertManagerDeploymentReconciler) reconcileStatusOverrideNotices(
+	inStatus *operatorsv1alpha1.CertManagerDeploymentStatus,
+	rg ResourceGetter) *operatorsv1alpha1.CertManagerDeploymentStatus {
+	if notices := rg.GetOverrideNotices(); len(notices) > 0 {
+		inStatus.OverrideNotices = notices
+	}
+
+	return inStatus
+}
+
+// reconcileStatusPatches is a subreconciliation function called by ReconcileStatus that reports
+// the result of applying each patch in spec.dangerZone.patches to the managed objects.
+func (r *CertManagerDeploymentReconciler) reconcileStatusPatches(
+	inStatus *operatorsv1alpha1.CertManagerDeploymentStatus,
+	rg ResourceGetter) *operatorsv1alpha1.CertManagerDeploymentStatus {
+	if statuses := rg.GetPatchStatuses(); len(statuses) > 0 {
+		inStatus.PatchStatuses = statuses
+	}
+
+	return inStatus
+}
@@ -0,0 +1,109 @@
+package certmanagerdeployment
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+	"github.com/komish/cmd-operator-dev/cmdoputils"
+	"github.com/komish/cmd-operator-dev/controllers/componentry"
+)
+
+// statusWriteCountingClient is a client that counts the status updates and patches made with it.
+type statusWriteCountingClient struct {
+	client.Client
+	statusUpdates int
+}
+
+func (c *statusWriteCountingClient) Status() client.StatusWriter {
+	return &statusWriteCounter{StatusWriter: c.Client.Status(), c: c}
+}
+
+type statusWriteCounter struct {
+	client.StatusWriter
+	c *statusWriteCountingClient
+}
+
+func (w *statusWriteCounter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	w.c.statusUpdates++
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func (w *statusWriteCounter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	w.c.statusUpdates++
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
+
+var _ = Describe("Status", func() {
+	var (
+		c *statusWriteCountingClient
+		r *CertManagerDeploymentReconciler
+	)
+
+	key := types.NamespacedName{Name: "cluster"}
+	getInstance := func() *operatorsv1alpha1.CertManagerDeployment {
+		instance := &operatorsv1alpha1.CertManagerDeployment{}
+		Expect(c.Get(context.TODO(), key, instance)).To(Succeed())
+		return instance
+	}
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(apiextv1.AddToScheme(s)).To(Succeed())
+		Expect(operatorsv1alpha1.AddToScheme(s)).To(Succeed())
+
+		c = &statusWriteCountingClient{Client: fake.NewFakeClientWithScheme(s, &operatorsv1alpha1.CertManagerDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name},
+			Spec: operatorsv1alpha1.CertManagerDeploymentSpec{
+				Version: cmdoputils.GetStringPointer(componentry.CertManagerDefaultVersion),
+			},
+		})}
+		r = &CertManagerDeploymentReconciler{Client: c, Log: logf.Log, Scheme: s, EventRecorder: record.NewFakeRecorder(10)}
+	})
+
+	It("Should not write the status again when nothing changed", func() {
+		Expect(r.reconcileStatus(getInstance(), logf.Log)).To(Succeed())
+		Expect(c.statusUpdates).To(Equal(1))
+
+		Expect(r.reconcileStatus(getInstance(), logf.Log)).To(Succeed())
+		Expect(c.statusUpdates).To(Equal(1))
+	})
+
+	It("Should keep the certificate health summarized by the reporter", func() {
+		instance := getInstance()
+		instance.Status.CertificateHealth = &operatorsv1alpha1.CertificateHealth{CertificatesReady: 2, LastUpdateTime: metav1.Unix(100, 0)}
+		Expect(r.reconcileStatus(instance, logf.Log)).To(Succeed())
+		Expect(getInstance().Status.CertificateHealth.CertificatesReady).To(Equal(2))
+	})
+
+	It("Should keep the timestamps of unchanged conditions", func() {
+		previous := operatorsv1alpha1.CertManagerDeploymentStatus{
+			Conditions: []operatorsv1alpha1.CertManagerDeploymentCondition{
+				{Type: operatorsv1alpha1.ConditionCRDsAreReady, Status: "True", LastUpdateTime: metav1.Unix(100, 0)},
+				{Type: operatorsv1alpha1.ConditionDeploymentsAreReady, Status: "False", LastUpdateTime: metav1.Unix(100, 0)},
+			},
+		}
+		status := &operatorsv1alpha1.CertManagerDeploymentStatus{
+			Conditions: []operatorsv1alpha1.CertManagerDeploymentCondition{
+				{Type: operatorsv1alpha1.ConditionCRDsAreReady, Status: "True", LastUpdateTime: metav1.Unix(200, 0)},
+				{Type: operatorsv1alpha1.ConditionDeploymentsAreReady, Status: "True", LastUpdateTime: metav1.Unix(200, 0)},
+			},
+		}
+
+		preserveUnchangedTimestamps(status, &previous)
+		Expect(status.Conditions[0].LastUpdateTime).To(Equal(metav1.Unix(100, 0)))
+		Expect(status.Conditions[1].LastUpdateTime).To(Equal(metav1.Unix(200, 0)))
+	})
+})
@@ -1,220 +1,56 @@
 package certmanagerdeployment
 
 import (
-	"context"
-
-	"github.com/go-logr/logr"
 	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
 	"github.com/komish/cmd-operator-dev/cmdoputils"
 	"github.com/komish/cmd-operator-dev/controllers/componentry"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	adregv1 "k8s.io/api/admissionregistration/v1"
 )
 
-// reconcileWebhooks reconciles MutatingWebhookConfiguration and ValidatingWebhookConfiguration resource(s) for a given CertManagerDeployment resource.
-func (r *CertManagerDeploymentReconciler) reconcileWebhooks(instance *operatorsv1alpha1.CertManagerDeployment, reqLogger logr.Logger) error {
-	reqLogger.Info("Starting reconciliation: webhooks")
-	defer reqLogger.Info("Ending reconciliation: webhooks")
-
-	getter := ResourceGetter{CustomResource: *instance}
-
-	mwhs := getter.GetMutatingWebhooks()
-
-	for _, mwh := range mwhs {
-		if err := controllerutil.SetControllerReference(instance, mwh, r.Scheme); err != nil {
-			return err
-		}
-		found := &adregv1.MutatingWebhookConfiguration{}
-		err := r.Get(context.TODO(), types.NamespacedName{Name: mwh.GetName()}, found)
-		if err != nil && apierrors.IsNotFound(err) {
-			reqLogger.Info("Creating MutatingWebhookConfiguration", "MutatingWebhookConfiguration.Name", mwh.GetName())
-			r.Eventf(instance,
-				createManagedWebhook.etype,
-				createManagedWebhook.reason,
-				"%s: %s",
-				createManagedWebhook.message,
-				mwh.GetName())
-			if err := r.Create(context.TODO(), mwh); err != nil {
-				return err
-			}
-
-			// successful create. Move onto next iteration.
-			continue
-		} else if err != nil {
-			return err
-		}
-
-		// Update the mutating webhook if needed
-		genLabelsInterface, err := cmdoputils.Interfacer{Data: mwh.Labels}.ToJSONInterface()
-		if err != nil { // err indicates a marshaling problem
-			return err
-		}
-
-		foundLabelsInterface, err := cmdoputils.Interfacer{Data: found.Labels}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		genAnnotsInterface, err := cmdoputils.Interfacer{Data: mwh.Annotations}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-		foundAnnotsInterface, err := cmdoputils.Interfacer{Data: found.Annotations}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		genWebhooksInterface, err := cmdoputils.Interfacer{Data: mwh.Webhooks}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		foundWebhooksInterface, err := cmdoputils.Interfacer{Data: found.Webhooks}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		// Check for equality
-		labelsMatch := cmdoputils.ObjectsMatch(genLabelsInterface, foundLabelsInterface)
-		annotsMatch := cmdoputils.ObjectsMatch(genAnnotsInterface, foundAnnotsInterface)
-		webhooksMatch := cmdoputils.ObjectsMatch(genWebhooksInterface, foundWebhooksInterface)
-
-		// If not equal, update.
-		if !(webhooksMatch && labelsMatch && annotsMatch) {
-			reqLogger.Info("MutatingWebhookConfiguration already exists, but needs an update. Updating.",
-				"MutatingWebhookConfiguration.Name", mwh.GetName(),
-				"HasExpectedWebhooks", webhooksMatch,
-				"HasExpectedLabels", labelsMatch,
-				"HasExpectedAnnotations", annotsMatch)
-			r.Eventf(instance, updatingManagedWebhook.etype, updatingManagedWebhook.reason, "%s: %s", updatingManagedWebhook.message, mwh.GetName())
-
-			updated := found.DeepCopy()
-
-			if !webhooksMatch {
-				// TODO(): should we avoid clobbering and instead just add our annotations?
-				updated.Webhooks = mwh.Webhooks
-			}
-
-			if !labelsMatch {
-				// TODO(): should we avoid clobbering and instead just add our labels?
-				updated.ObjectMeta.Labels = mwh.GetLabels()
-			}
-
-			if !annotsMatch {
-				// TODO(): should we avoid clobbering and instead just add our annotations?
-				updated.ObjectMeta.Annotations = mwh.GetAnnotations()
-			}
-
-			reqLogger.Info("Updating MutatingWebhookConfiguration", "MutatingWebhookConfiguration.Name", mwh.GetName())
-			if err := r.Update(context.TODO(), updated); err != nil {
-				return err
-			}
-
-			r.Eventf(instance, updatedManagedWebhook.etype, updatedManagedWebhook.reason, "%s: %s", updatedManagedWebhook.message, mwh.GetName())
-		}
-	}
-
-	// validating webhooks
-	vwhs := getter.GetValidatingWebhooks()
-	for _, vwh := range vwhs {
-		if err := controllerutil.SetControllerReference(instance, vwh, r.Scheme); err != nil {
-			return err
-		}
-
-		found := &adregv1.ValidatingWebhookConfiguration{}
-		err := r.Get(context.TODO(), types.NamespacedName{Name: vwh.GetName()}, found)
-		if err != nil && apierrors.IsNotFound(err) {
-			reqLogger.Info("Creating ValidatingWebhookConfiguration", "ValidatingWebhookConfiguration.Name", vwh.GetName())
-			r.Eventf(instance,
-				createManagedWebhook.etype,
-				createManagedWebhook.reason,
-				"%s: %s",
-				createManagedWebhook.message,
-				vwh.GetName())
-			if err := r.Create(context.TODO(), vwh); err != nil {
-				return err
-			}
-
-			// successful create. Move onto next iteration.
-			continue
-		} else if err != nil {
-			return err
-		}
-
-		// Update the validating webhook if needed
-		genLabelsInterface, err := cmdoputils.Interfacer{Data: vwh.Labels}.ToJSONInterface()
-		if err != nil { // error indicates a marshaling problem
-			return err
-		}
-		foundLabelsInterface, err := cmdoputils.Interfacer{Data: found.Labels}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		genAnnotsInterface, err := cmdoputils.Interfacer{Data: vwh.Annotations}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-		foundAnnotsInterface, err := cmdoputils.Interfacer{Data: found.Annotations}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		genWebhooksInterface, err := cmdoputils.Interfacer{Data: vwh.Webhooks}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		foundWebhooksInterface, err := cmdoputils.Interfacer{Data: found.Webhooks}.ToJSONInterface()
-		if err != nil {
-			return err
-		}
-
-		// Check for equality
-		labelsMatch := cmdoputils.ObjectsMatch(genLabelsInterface, foundLabelsInterface)
-		annotsMatch := cmdoputils.ObjectsMatch(genAnnotsInterface, foundAnnotsInterface)
-		webhooksMatch := cmdoputils.ObjectsMatch(genWebhooksInterface, foundWebhooksInterface)
-
-		// If not equal, update.
-		if !(webhooksMatch && labelsMatch && annotsMatch) {
-			reqLogger.Info("ValidatingWebhookConfiguration already exists, but needs an update. Updating.",
-				"ValidatingWebhookConfiguration.Name", vwh.GetName(),
-				"HasExpectedWebhooks", webhooksMatch,
-				"HasExpectedLabels", labelsMatch,
-				"HasExpectedAnnotations", annotsMatch)
-			r.Eventf(instance, updatingManagedWebhook.etype, updatingManagedWebhook.reason, "%s: %s", updatingManagedWebhook.message, vwh.GetName())
-
-			updated := found.DeepCopy()
-
-			// TODO(): should we avoid clobbering and
-			// instead just merge these meta items
-			if !webhooksMatch {
-				updated.Webhooks = vwh.Webhooks
-			}
-
-			if !labelsMatch {
-				updated.ObjectMeta.Labels = vwh.GetLabels()
-			}
-
-			if !annotsMatch {
-				updated.ObjectMeta.Annotations = vwh.GetAnnotations()
-			}
-
-			reqLogger.Info("Updating ValidatingWebhookConfiguration", "ValidatingWebhookConfiguration.Name", vwh.GetName())
-			if err := r.Update(context.TODO(), updated); err != nil {
-				// some issue performing the update.
-				return err
-			}
-
-			r.Eventf(instance, updatedManagedWebhook.etype, updatedManagedWebhook.reason, "%s: %s", updatedManagedWebhook.message, vwh.GetName())
-		}
-	}
+// managedMutatingWebhooks reconciles the MutatingWebhookConfiguration resources for a given
+// CertManagerDeployment resource.
+var managedMutatingWebhooks = managedKind{
+	kind:   "MutatingWebhookConfiguration",
+	plural: "mutating webhooks",
+	desired: func(rg *ResourceGetter) ([]managedObject, error) {
+		var objs []managedObject
+		for _, mwh := range rg.GetMutatingWebhooks() {
+			objs = append(objs, mwh)
+		}
+		return objs, nil
+	},
+	empty: func() managedObject { return &adregv1.MutatingWebhookConfiguration{} },
+	compare: func(obj managedObject) map[string]interface{} {
+		mwh := obj.(*adregv1.MutatingWebhookConfiguration)
+		return map[string]interface{}{"Webhooks": mwh.Webhooks, "Labels": mwh.Labels, "Annotations": mwh.Annotations}
+	},
+	owned:    true,
+	creating: createManagedWebhook,
+	updated:  updatedManagedWebhook,
+}
 
-	return nil
+// managedValidatingWebhooks reconciles the ValidatingWebhookConfiguration resources for a given
+// CertManagerDeployment resource.
+var managedValidatingWebhooks = managedKind{
+	kind:   "ValidatingWebhookConfiguration",
+	plural: "validating webhooks",
+	desired: func(rg *ResourceGetter) ([]managedObject, error) {
+		var objs []managedObject
+		for _, vwh := range rg.GetValidatingWebhooks() {
+			objs = append(objs, vwh)
+		}
+		return objs, nil
+	},
+	empty: func() managedObject { return &adregv1.ValidatingWebhookConfiguration{} },
+	compare: func(obj managedObject) map[string]interface{} {
+		vwh := obj.(*adregv1.ValidatingWebhookConfiguration)
+		return map[string]interface{}{"Webhooks": vwh.Webhooks, "Labels": vwh.Labels, "Annotations": vwh.Annotations}
+	},
+	owned:    true,
+	creating: createManagedWebhook,
+	updated:  updatedManagedWebhook,
 }
 
 // GetMutatingWebhooks returns MutatingWebhookConfiguration objects for a given CertManagerDeployment
@@ -247,6 +83,10 @@ func (r *ResourceGetter) GetMutatingWebhooks() []*adregv1.MutatingWebhookConfigu
 
 	}
 
+	for _, hook := range hooks {
+		r.applyPatches(hook)
+	}
+
 	return hooks
 }
 
@@ -280,6 +120,10 @@ func (r *ResourceGetter) GetValidatingWebhooks() []*adregv1.ValidatingWebhookCon
 
 	}
 
+	for _, hook := range hooks {
+		r.applyPatches(hook)
+	}
+
 	return hooks
 }
 
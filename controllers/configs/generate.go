@@ -0,0 +1,6 @@
+package configs
+
+// The types and defaults packages for each supported version of cert-manager are generated from
+// the captured --help output of each component found in hack/flags. See hack/configgen for details.
+//go:generate go run ../../hack/configgen -flags ../../hack/flags/v1.1.0 -out v1_1_0
+//go:generate go run ../../hack/configgen -flags ../../hack/flags/v1.2.0 -out v1_2_0
@@ -5,6 +5,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
 )
 
 var _ = Describe("GetDefaultConfigFor", func() {
@@ -147,3 +148,17 @@ var _ = Describe("getEmptyCAInjectorConfigForVersion", func() {
 		})
 	})
 })
+
+var _ = Describe("Generated default configurations", func() {
+	Context("When decoding the default configuration into the empty configuration of the same version", func() {
+		for _, version := range []string{"v1.1.0", "v1.2.0"} {
+			for _, component := range []string{controller, webhook, cainjector} {
+				version, component := version, component
+				It("Should decode without error for the "+component+" at "+version, func() {
+					schema := GetEmptyConfigFor(component, version)
+					Expect(yaml.UnmarshalStrict(GetDefaultConfigFor(component, version), schema)).To(Succeed())
+				})
+			}
+		}
+	})
+})
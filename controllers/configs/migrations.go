@@ -0,0 +1,215 @@
+package configs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FlagChangeType describes how a flag changed between two versions of cert-manager.
+type FlagChangeType string
+
+const (
+	// FlagRenamed indicates the flag is accepted under a new name. Overrides using the
+	// old name are rewritten to use the new name.
+	FlagRenamed FlagChangeType = "Renamed"
+	// FlagDeprecated indicates the flag is still accepted but is expected to be removed
+	// in a future version. Overrides using the flag are kept as-is.
+	FlagDeprecated FlagChangeType = "Deprecated"
+	// FlagRemoved indicates the flag is no longer accepted. Overrides using the flag are dropped.
+	FlagRemoved FlagChangeType = "Removed"
+	// FlagRenameConflict is only used in notices, for overrides that set both the old and the new
+	// name of a renamed flag. The value for the old name is ignored.
+	FlagRenameConflict FlagChangeType = "RenameConflict"
+)
+
+// FlagChange is a change to a single flag of a cert-manager component.
+type FlagChange struct {
+	// Component is the name of the component whose flag changed.
+	Component string
+	// Flag is the name of the flag in the version being migrated from.
+	Flag string
+	// Type is the type of change.
+	Type FlagChangeType
+	// RenamedTo is the new name of the flag. Only used when Type is FlagRenamed.
+	RenamedTo string
+	// Message is a human readable explanation of the change and what to use instead.
+	Message string
+}
+
+// FlagMigration contains the flag changes introduced when moving from one
+// version of cert-manager to the next.
+type FlagMigration struct {
+	From    string
+	To      string
+	Changes []FlagChange
+}
+
+// flagMigrations is the ordered list of flag changes between supported versions of
+// cert-manager. A new entry should be added whenever a supported version is added.
+var flagMigrations = []FlagMigration{
+	{
+		From: "v1.1.0",
+		To:   "v1.2.0",
+		Changes: []FlagChange{
+			{
+				Component: controller,
+				Flag:      "renew-before-expiry-duration",
+				Type:      FlagRemoved,
+				Message:   "The default renewal window is no longer configurable on the controller. Set spec.renewBefore on each Certificate instead.",
+			},
+		},
+	},
+}
+
+// OverrideNotice describes a container argument override that was affected by
+// a flag change when migrating to a given version of cert-manager.
+type OverrideNotice struct {
+	Component string
+	Flag      string
+	Type      FlagChangeType
+	Message   string
+}
+
+// MigrateOverrides rewrites the JSON-encoded flag overrides for the component so that
+// they are valid for the requested version. Flags renamed in any migration leading up
+// to version are moved to their new name, and flags that were removed are dropped. A
+// flag is only migrated if it is not a valid flag for version, so flags that are re-introduced
+// in later versions are left alone. Notices are returned for every override that was
+// rewritten, dropped, or uses a deprecated flag.
+//
+// If overrides is empty it is returned as-is. This function will return a panic if an
+// incorrect component name or version is provided.
+func MigrateOverrides(componentName, version string, overrides []byte) ([]byte, []OverrideNotice, error) {
+	if len(overrides) == 0 {
+		return overrides, nil, nil
+	}
+
+	var flags map[string]interface{}
+	if err := json.Unmarshal(overrides, &flags); err != nil {
+		return overrides, nil, err
+	}
+	if flags == nil {
+		return overrides, nil, nil
+	}
+
+	valid := flagNamesFor(componentName, version)
+	notices := make([]OverrideNotice, 0)
+
+	for _, migration := range flagMigrations {
+		if compareVersions(migration.To, version) > 0 {
+			break
+		}
+
+		for _, change := range migration.Changes {
+			if change.Component != componentName {
+				continue
+			}
+
+			val, set := flags[change.Flag]
+			if !set {
+				continue
+			}
+
+			switch change.Type {
+			case FlagDeprecated:
+				notices = append(notices, OverrideNotice{
+					Component: componentName,
+					Flag:      change.Flag,
+					Type:      FlagDeprecated,
+					Message:   fmt.Sprintf("flag is deprecated as of %s: %s", migration.To, change.Message),
+				})
+				continue
+			case FlagRenamed, FlagRemoved:
+				if valid[change.Flag] {
+					// the flag is accepted by the requested version, nothing to do.
+					continue
+				}
+			}
+
+			delete(flags, change.Flag)
+
+			if change.Type == FlagRenamed {
+				if _, exists := flags[change.RenamedTo]; exists {
+					notices = append(notices, OverrideNotice{
+						Component: componentName,
+						Flag:      change.Flag,
+						Type:      FlagRenameConflict,
+						Message: fmt.Sprintf("flag was renamed to %s in %s but both are set; the value for %s is used",
+							change.RenamedTo, migration.To, change.RenamedTo),
+					})
+					continue
+				}
+
+				flags[change.RenamedTo] = val
+				notices = append(notices, OverrideNotice{
+					Component: componentName,
+					Flag:      change.Flag,
+					Type:      FlagRenamed,
+					Message:   fmt.Sprintf("flag was renamed to %s in %s: %s", change.RenamedTo, migration.To, change.Message),
+				})
+				continue
+			}
+
+			notices = append(notices, OverrideNotice{
+				Component: componentName,
+				Flag:      change.Flag,
+				Type:      FlagRemoved,
+				Message:   fmt.Sprintf("flag was removed in %s and is ignored: %s", migration.To, change.Message),
+			})
+		}
+	}
+
+	if len(notices) == 0 {
+		return overrides, notices, nil
+	}
+
+	migrated, err := json.Marshal(flags)
+	if err != nil {
+		return overrides, nil, err
+	}
+
+	return migrated, notices, nil
+}
+
+// flagNamesFor returns the set of flags accepted by the component at the specified version,
+// as defined by the json tags of the Flags field of its configuration type.
+func flagNamesFor(componentName, version string) map[string]bool {
+	names := map[string]bool{}
+
+	cfg := reflect.Indirect(reflect.ValueOf(GetEmptyConfigFor(componentName, version)))
+	flagsField := cfg.FieldByName("Flags")
+	if !flagsField.IsValid() {
+		return names
+	}
+
+	flagsType := flagsField.Type()
+	for i := 0; i < flagsType.NumField(); i++ {
+		tag := strings.Split(flagsType.Field(i).Tag.Get("json"), ",")[0]
+		if tag != "" && tag != "-" {
+			names[tag] = true
+		}
+	}
+
+	return names
+}
+
+// compareVersions compares two version strings in format vX.Y.Z and returns -1, 0,
+// or 1 if a is less than, equal to, or greater than b respectively.
+func compareVersions(a, b string) int {
+	var av, bv [3]int
+	fmt.Sscanf(a, "v%d.%d.%d", &av[0], &av[1], &av[2])
+	fmt.Sscanf(b, "v%d.%d.%d", &bv[0], &bv[1], &bv[2])
+
+	for i := range av {
+		switch {
+		case av[i] < bv[i]:
+			return -1
+		case av[i] > bv[i]:
+			return 1
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,137 @@
+package configs
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MigrateOverrides", func() {
+	var original []FlagMigration
+
+	BeforeEach(func() {
+		original = flagMigrations
+	})
+
+	AfterEach(func() {
+		flagMigrations = original
+	})
+
+	decode := func(raw []byte) map[string]interface{} {
+		var res map[string]interface{}
+		Expect(json.Unmarshal(raw, &res)).To(Succeed())
+		return res
+	}
+
+	Context("When overrides use a flag removed in the requested version", func() {
+		It("Should drop the flag and return a removal notice", func() {
+			in := []byte(`{"renew-before-expiry-duration":"720h","v":"4"}`)
+			out, notices, err := MigrateOverrides(controller, "v1.2.0", in)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decode(out)).To(Equal(map[string]interface{}{"v": "4"}))
+			Expect(notices).To(HaveLen(1))
+			Expect(notices[0].Component).To(Equal(controller))
+			Expect(notices[0].Flag).To(Equal("renew-before-expiry-duration"))
+			Expect(notices[0].Type).To(Equal(FlagRemoved))
+		})
+
+		It("Should leave the flag alone when the requested version predates the removal", func() {
+			in := []byte(`{"renew-before-expiry-duration":"720h"}`)
+			out, notices, err := MigrateOverrides(controller, "v1.1.0", in)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(Equal(in))
+			Expect(notices).To(BeEmpty())
+		})
+
+		It("Should only consider changes for the requested component", func() {
+			in := []byte(`{"renew-before-expiry-duration":"720h"}`)
+			out, notices, err := MigrateOverrides(webhook, "v1.2.0", in)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(Equal(in))
+			Expect(notices).To(BeEmpty())
+		})
+	})
+
+	Context("When overrides use a flag renamed in the requested version", func() {
+		BeforeEach(func() {
+			flagMigrations = []FlagMigration{
+				{
+					From: "v1.1.0",
+					To:   "v1.2.0",
+					Changes: []FlagChange{
+						{Component: controller, Flag: "renew-before-expiry-duration", Type: FlagRenamed, RenamedTo: "enable-profiling"},
+					},
+				},
+			}
+		})
+
+		It("Should move the value to the new flag name", func() {
+			out, notices, err := MigrateOverrides(controller, "v1.2.0", []byte(`{"renew-before-expiry-duration":true}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decode(out)).To(Equal(map[string]interface{}{"enable-profiling": true}))
+			Expect(notices).To(HaveLen(1))
+			Expect(notices[0].Type).To(Equal(FlagRenamed))
+		})
+
+		It("Should prefer the new flag name when both are set", func() {
+			out, notices, err := MigrateOverrides(controller, "v1.2.0", []byte(`{"renew-before-expiry-duration":true,"enable-profiling":false}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decode(out)).To(Equal(map[string]interface{}{"enable-profiling": false}))
+			Expect(notices).To(HaveLen(1))
+			Expect(notices[0].Type).To(Equal(FlagRenameConflict))
+			Expect(notices[0].Flag).To(Equal("renew-before-expiry-duration"))
+			Expect(notices[0].Message).To(ContainSubstring("the value for enable-profiling is used"))
+		})
+	})
+
+	Context("When overrides use a deprecated flag", func() {
+		BeforeEach(func() {
+			flagMigrations = []FlagMigration{
+				{
+					From: "v1.1.0",
+					To:   "v1.2.0",
+					Changes: []FlagChange{
+						{Component: controller, Flag: "enable-profiling", Type: FlagDeprecated},
+					},
+				},
+			}
+		})
+
+		It("Should keep the flag and return a deprecation notice", func() {
+			in := []byte(`{"enable-profiling":true}`)
+			out, notices, err := MigrateOverrides(controller, "v1.2.0", in)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decode(out)).To(Equal(map[string]interface{}{"enable-profiling": true}))
+			Expect(notices).To(HaveLen(1))
+			Expect(notices[0].Type).To(Equal(FlagDeprecated))
+		})
+	})
+
+	Context("When overrides are empty or invalid", func() {
+		It("Should return empty overrides as-is", func() {
+			out, notices, err := MigrateOverrides(controller, "v1.2.0", []byte{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(BeEmpty())
+			Expect(notices).To(BeEmpty())
+		})
+
+		It("Should return an error for overrides that are not a JSON object", func() {
+			_, _, err := MigrateOverrides(controller, "v1.2.0", []byte(`["v"]`))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("flagMigrations", func() {
+	It("Should only reference flags that exist in the version being migrated from", func() {
+		for _, migration := range flagMigrations {
+			for _, change := range migration.Changes {
+				Expect(flagNamesFor(change.Component, migration.From)).To(HaveKey(change.Flag))
+				if change.Type == FlagRenamed {
+					Expect(flagNamesFor(change.Component, migration.To)).To(HaveKey(change.RenamedTo))
+				}
+			}
+		}
+	})
+})
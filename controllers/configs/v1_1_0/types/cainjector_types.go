@@ -1,3 +1,5 @@
+// Code generated by hack/configgen from the cert-manager v1.1.0 cainjector --help output. DO NOT EDIT.
+
 package types
 
 import (
@@ -6,17 +8,62 @@ import (
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+// CertManagerCAInjectorConfig is the configuration of the cert-manager cainjector component.
 type CertManagerCAInjectorConfig struct {
 	metav1.TypeMeta `json:",inline"`
 
 	Flags CertManagerCAInjectorFlags `json:"flags"`
 }
 
+// CertManagerCAInjectorFlags contains the commandline flags accepted by the cert-manager cainjector component.
 type CertManagerCAInjectorFlags struct {
-	loggingFlags
-	Kubeconfig              string `json:"kubeconfig"`
-	Master                  string `json:"master"`
-	Namespace               string `json:"namespace"`
-	LeaderElect             bool   `json:"leader-elect"`
+	// AddDirHeader maps to --add_dir_header. If true, adds the file directory to the header of the log
+	// messages.
+	AddDirHeader bool `json:"add_dir_header"`
+	// Alsologtostderr maps to --alsologtostderr. log to standard error as well as files.
+	Alsologtostderr bool `json:"alsologtostderr"`
+	// Kubeconfig maps to --kubeconfig. Paths to a kubeconfig. Only required if out-of-cluster.
+	Kubeconfig string `json:"kubeconfig"`
+	// LeaderElect maps to --leader-elect. If true, cainjector will perform leader election between
+	// instances to ensure no more than one instance of cainjector operates at a time. Upstream
+	// default: true.
+	LeaderElect bool `json:"leader-elect"`
+	// LeaderElectionNamespace maps to --leader-election-namespace. Namespace used to perform leader
+	// election. Only used if leader election is enabled. Upstream default: "kube-system".
 	LeaderElectionNamespace string `json:"leader-election-namespace"`
+	// LogFlushFrequency maps to --log-flush-frequency. Maximum number of seconds between log flushes.
+	// Upstream default: 5s.
+	LogFlushFrequency metav1.Duration `json:"log-flush-frequency"`
+	// LogBacktraceAt maps to --log_backtrace_at. when logging hits line file:N, emit a stack trace.
+	// Upstream default: :0.
+	LogBacktraceAt string `json:"log_backtrace_at"`
+	// LogDir maps to --log_dir. If non-empty, write log files in this directory.
+	LogDir string `json:"log_dir"`
+	// LogFile maps to --log_file. If non-empty, use this log file.
+	LogFile string `json:"log_file"`
+	// LogFileMaxSize maps to --log_file_max_size. Defines the maximum size a log file can grow to.
+	// Unit is megabytes. If the value is 0, the maximum file size is unlimited. Upstream default:
+	// 1800.
+	LogFileMaxSize uint `json:"log_file_max_size"`
+	// Logtostderr maps to --logtostderr. log to standard error instead of files. Upstream default:
+	// true.
+	Logtostderr bool `json:"logtostderr"`
+	// Master maps to --master. Optional apiserver host address to connect to. If not specified,
+	// autoconfiguration will be attempted.
+	Master string `json:"master"`
+	// Namespace maps to --namespace. If set, this limits the scope of cainjector to a single
+	// namespace. If set, cainjector will not update resources with certificates outside of the
+	// configured namespace.
+	Namespace string `json:"namespace"`
+	// SkipHeaders maps to --skip_headers. If true, avoid header prefixes in the log messages.
+	SkipHeaders bool `json:"skip_headers"`
+	// SkipLogHeaders maps to --skip_log_headers. If true, avoid headers when opening log files.
+	SkipLogHeaders bool `json:"skip_log_headers"`
+	// Stderrthreshold maps to --stderrthreshold. logs at or above this threshold go to stderr.
+	// Upstream default: 2.
+	Stderrthreshold int32 `json:"stderrthreshold"`
+	// V maps to --v. number for the log level verbosity.
+	V int32 `json:"v"`
+	// Vmodule maps to --vmodule. comma-separated list of pattern=N settings for file-filtered logging.
+	Vmodule string `json:"vmodule"`
 }
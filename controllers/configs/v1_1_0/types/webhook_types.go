@@ -1,3 +1,5 @@
+// Code generated by hack/configgen from the cert-manager v1.1.0 webhook --help output. DO NOT EDIT.
+
 package types
 
 import (
@@ -6,22 +8,78 @@ import (
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+// CertManagerWebhookConfig is the configuration of the cert-manager webhook component.
 type CertManagerWebhookConfig struct {
 	metav1.TypeMeta `json:",inline"`
 
 	Flags CertManagerWebhookFlags `json:"flags"`
 }
 
+// CertManagerWebhookFlags contains the commandline flags accepted by the cert-manager webhook component.
 type CertManagerWebhookFlags struct {
-	loggingFlags
-	ListenPort                      int      `json:"secure-port"`
-	HealthzPort                     int      `json:"healthz-port"`
-	TLSCertFile                     string   `json:"tls-cert-file"`
-	TLSKeyFile                      string   `json:"tls-private-key-file"`
-	DynamicServingCASecretNamespace string   `json:"dynamic-serving-ca-secret-namespace"`
-	DynamicServingCASecretName      string   `json:"dynamic-serving-ca-secret-name"`
-	DynamicServingDNSNames          []string `json:"dynamic-serving-dns-names"`
-	Kubeconfig                      string   `json:"kubeconfig"`
-	TLSCipherSuites                 []string `json:"tls-cipher-suites"` // https://github.com/kubernetes/component-base/blob/master/cli/flag/ciphersuites_flag.go
-	MinTLSVersion                   string   `json:"tls-min-version"`
+	// AddDirHeader maps to --add_dir_header. If true, adds the file directory to the header of the log
+	// messages.
+	AddDirHeader bool `json:"add_dir_header"`
+	// Alsologtostderr maps to --alsologtostderr. log to standard error as well as files.
+	Alsologtostderr bool `json:"alsologtostderr"`
+	// DynamicServingCASecretName maps to --dynamic-serving-ca-secret-name. name of the secret used to
+	// store the CA that signs serving certificates.
+	DynamicServingCASecretName string `json:"dynamic-serving-ca-secret-name"`
+	// DynamicServingCASecretNamespace maps to --dynamic-serving-ca-secret-namespace. namespace of the
+	// secret used to store the CA that signs serving certificates.
+	DynamicServingCASecretNamespace string `json:"dynamic-serving-ca-secret-namespace"`
+	// DynamicServingDNSNames maps to --dynamic-serving-dns-names. DNS names that should be present on
+	// certificates generated by the dynamic serving CA.
+	DynamicServingDNSNames []string `json:"dynamic-serving-dns-names"`
+	// HealthzPort maps to --healthz-port. port number to listen on for insecure healthz connections.
+	// Upstream default: 6080.
+	HealthzPort int `json:"healthz-port"`
+	// Kubeconfig maps to --kubeconfig. optional path to the kubeconfig used to connect to the
+	// apiserver. If not specified, in-cluster-config will be used.
+	Kubeconfig string `json:"kubeconfig"`
+	// LogFlushFrequency maps to --log-flush-frequency. Maximum number of seconds between log flushes.
+	// Upstream default: 5s.
+	LogFlushFrequency metav1.Duration `json:"log-flush-frequency"`
+	// LogBacktraceAt maps to --log_backtrace_at. when logging hits line file:N, emit a stack trace.
+	// Upstream default: :0.
+	LogBacktraceAt string `json:"log_backtrace_at"`
+	// LogDir maps to --log_dir. If non-empty, write log files in this directory.
+	LogDir string `json:"log_dir"`
+	// LogFile maps to --log_file. If non-empty, use this log file.
+	LogFile string `json:"log_file"`
+	// LogFileMaxSize maps to --log_file_max_size. Defines the maximum size a log file can grow to.
+	// Unit is megabytes. If the value is 0, the maximum file size is unlimited. Upstream default:
+	// 1800.
+	LogFileMaxSize uint `json:"log_file_max_size"`
+	// Logtostderr maps to --logtostderr. log to standard error instead of files. Upstream default:
+	// true.
+	Logtostderr bool `json:"logtostderr"`
+	// SecurePort maps to --secure-port. port number to listen on for secure TLS connections. Upstream
+	// default: 6443.
+	SecurePort int `json:"secure-port"`
+	// SkipHeaders maps to --skip_headers. If true, avoid header prefixes in the log messages.
+	SkipHeaders bool `json:"skip_headers"`
+	// SkipLogHeaders maps to --skip_log_headers. If true, avoid headers when opening log files.
+	SkipLogHeaders bool `json:"skip_log_headers"`
+	// Stderrthreshold maps to --stderrthreshold. logs at or above this threshold go to stderr.
+	// Upstream default: 2.
+	Stderrthreshold int32 `json:"stderrthreshold"`
+	// TLSCertFile maps to --tls-cert-file. path to the file containing the TLS certificate to serve
+	// with.
+	TLSCertFile string `json:"tls-cert-file"`
+	// TLSCipherSuites maps to --tls-cipher-suites. Comma-separated list of cipher suites for the
+	// server. If omitted, the default Go cipher suites will be use. Possible values:
+	// TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,TLS_RSA_WITH_AES_128_CBC_SHA,TLS_RSA_WITH_AES_128_GCM_SHA256,TLS_RSA_WITH_AES_256_CBC_SHA,TLS_RSA_WITH_AES_256_GCM_SHA384.
+	TLSCipherSuites []string `json:"tls-cipher-suites"`
+	// TLSMinVersion maps to --tls-min-version. Minimum TLS version supported. If omitted, the default
+	// Go minimum version will be used. Possible values: VersionTLS10, VersionTLS11, VersionTLS12,
+	// VersionTLS13.
+	TLSMinVersion string `json:"tls-min-version"`
+	// TLSPrivateKeyFile maps to --tls-private-key-file. path to the file containing the TLS private
+	// key to serve with.
+	TLSPrivateKeyFile string `json:"tls-private-key-file"`
+	// V maps to --v. number for the log level verbosity.
+	V int32 `json:"v"`
+	// Vmodule maps to --vmodule. comma-separated list of pattern=N settings for file-filtered logging.
+	Vmodule string `json:"vmodule"`
 }
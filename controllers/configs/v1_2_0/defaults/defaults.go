@@ -1,35 +1,69 @@
+// Code generated by hack/configgen from the cert-manager v1.2.0 flag definitions and hack/flags/v1.2.0/defaults.yaml. DO NOT EDIT.
+
 package defaults
 
 // ConfigForController returns a default config for the controller component as a byte slice of YAML.
+//
+// The operator sets the following flags by default:
+//
+//	--cluster-resource-namespace
+//	  Namespace to store resources owned by cluster scoped resources such as ClusterIssuer in. This
+//	  must be specified if ClusterIssuers are enabled. Upstream default: "kube-system".
+//	--leader-election-namespace
+//	  Namespace used to perform leader election. Only used if leader election is enabled. Upstream
+//	  default: "kube-system".
+//	--v
+//	  number for the log level verbosity.
 func ConfigForController() []byte {
 	return []byte(`apiVersion: certmanagerconfigs.operators.redhat.io/v1
 kind: CertManagerControllerConfig
 flags:
-  v: 2
   cluster-resource-namespace: $(POD_NAMESPACE)
-  leader-election-namespace: $(POD_NAMESPACE)`)
+  leader-election-namespace: $(POD_NAMESPACE)
+  v: 2`)
 }
 
 // ConfigForWebhook returns a default config for the webhook component as a byte slice of YAML.
+//
+// The operator sets the following flags by default:
+//
+//	--dynamic-serving-ca-secret-name
+//	  name of the secret used to store the CA that signs serving certificates.
+//	--dynamic-serving-ca-secret-namespace
+//	  namespace of the secret used to store the CA that signs serving certificates.
+//	--dynamic-serving-dns-names
+//	  DNS names that should be present on certificates generated by the dynamic serving CA.
+//	--secure-port
+//	  port number to listen on for secure TLS connections. Upstream default: 6443.
+//	--v
+//	  number for the log level verbosity.
 func ConfigForWebhook() []byte {
 	return []byte(`apiVersion: certmanagerconfigs.operators.redhat.io/v1
 kind: CertManagerWebhookConfig
 flags:
-  v: 2
-  secure-port: 10250
-  dynamic-serving-ca-secret-namespace: $(POD_NAMESPACE)
   dynamic-serving-ca-secret-name: cert-manager-webhook-ca
+  dynamic-serving-ca-secret-namespace: $(POD_NAMESPACE)
   dynamic-serving-dns-names:
   - cert-manager-webhook
   - cert-manager-webhook.cert-manager
-  - cert-manager-webhook.cert-manager.svc`)
+  - cert-manager-webhook.cert-manager.svc
+  secure-port: 10250
+  v: 2`)
 }
 
-// ConfigForCAInjector returns a default config for the webhook component as a byte slice of YAML.
+// ConfigForCAInjector returns a default config for the cainjector component as a byte slice of YAML.
+//
+// The operator sets the following flags by default:
+//
+//	--leader-election-namespace
+//	  Namespace used to perform leader election. Only used if leader election is enabled. Upstream
+//	  default: "kube-system".
+//	--v
+//	  number for the log level verbosity.
 func ConfigForCAInjector() []byte {
 	return []byte(`apiVersion: certmanagerconfigs.operators.redhat.io/v1
 kind: CertManagerCAInjectorConfig
 flags:
-  v: 2
-  leader-election-namespace: $(POD_NAMESPACE)`)
+  leader-election-namespace: $(POD_NAMESPACE)
+  v: 2`)
 }
@@ -1,50 +1,171 @@
+// Code generated by hack/configgen from the cert-manager v1.2.0 controller --help output. DO NOT EDIT.
+
 package types
 
 import (
-	"time"
-
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+// CertManagerControllerConfig is the configuration of the cert-manager controller component.
 type CertManagerControllerConfig struct {
 	metav1.TypeMeta `json:",inline"`
 
 	Flags CertManagerControllerFlags `json:"flags"`
 }
 
+// CertManagerControllerFlags contains the commandline flags accepted by the cert-manager controller component.
 type CertManagerControllerFlags struct {
-	loggingFlags                                         // most of these come from klog
-	Kubeconfig                             string        `json:"kubeconfig"`
-	Master                                 string        `json:"master"`
-	ACMEHTTP01SolverImage                  string        `json:"acme-http01-solver-image"`
-	ACMEHTTP01SolveCPUResourceLimits       string        `json:"acme-http01-solver-resource-limits-cpu"`
-	ACMEHTTP01SolverMemoryResourceLimits   string        `json:"acme-http01-solver-resource-limits-memory"`
-	ACMEHTTP01SolverCPURequestRequests     string        `json:"acme-http01-solver-resource-request-cpu"`
-	ACMEHTTP01SolverMemoryResourceRequests string        `json:"acme-http01-solver-resource-request-memory"`
-	AutoCertificateAnnotations             []string      `json:"auto-certificate-annotations"`
-	ClusterIssuerAmbientCredentials        bool          `json:"cluster-issuer-ambient-credentials"`
-	ClusterResourceNamespace               string        `json:"cluster-resource-namespace"`
-	Controllers                            []string      `json:"controllers"`
-	DefaultIssuerGroup                     string        `json:"default-issuer-group"`
-	DefaultIssuerKind                      string        `json:"default-issuer-kind"`
-	DefaultIssuerName                      string        `json:"default-issuer-name"`
-	DNS01CheckRetryPeriod                  time.Duration `json:"dns01-check-retry-period"`
-	DNS01RecursiveNameservers              []string      `json:"dns01-recursive-nameservers"`
-	DNS01RecursiveNameserversOnly          bool          `json:"dns01-recursive-nameservers-only"`
-	EnableCertificateOwnerRefs             bool          `json:"enable-certificate-owner-ref"`
-	EnableProfiling                        bool          `json:"enable-profiling"`
-	FeatureGates                           []string      `json:"feature-gates"` // supposed to be map[string]bool
-	IssuerAmbientCredentials               bool          `json:"issuer-ambient-credentials"`
-	KubeAPIBurst                           float64       `json:"kube-api-burst"`
-	KubeAPIQPS                             float64       `json:"kube-api-qps"`
-	LeaderElect                            bool          `json:"leader-elect"`
-	LeaderElectLeaseDuration               time.Duration `json:"leader-election-lease-duration"`
-	LeaderElectionNamespace                string        `json:"leader-election-namespace"`
-	LeaderElectRenewDeadline               time.Duration `json:"leader-election-renew-deadline"`
-	LeaderElectionRetryPeriod              time.Duration `json:"leader-election-retry-period"`
-	MaxConcurrentChallenges                float64       `json:"max-concurrent-challenges"`
-	MetricsListenAddress                   string        `json:"metrics-listen-address"`
-	Namespace                              string        `json:"namespace"`
+	// ACMEHTTP01SolverImage maps to --acme-http01-solver-image. The docker image to use to solve ACME
+	// HTTP01 challenges. You most likely will not need to change this parameter unless you are testing
+	// a new feature or developing cert-manager. Upstream default:
+	// "quay.io/jetstack/cert-manager-acmesolver:v1.2.0".
+	ACMEHTTP01SolverImage string `json:"acme-http01-solver-image"`
+	// ACMEHTTP01SolverResourceLimitsCPU maps to --acme-http01-solver-resource-limits-cpu. Defines the
+	// resource limits CPU size when spawning new ACME HTTP01 challenge solver pods. Upstream default:
+	// "100m".
+	ACMEHTTP01SolverResourceLimitsCPU string `json:"acme-http01-solver-resource-limits-cpu"`
+	// ACMEHTTP01SolverResourceLimitsMemory maps to --acme-http01-solver-resource-limits-memory.
+	// Defines the resource limits Memory size when spawning new ACME HTTP01 challenge solver pods.
+	// Upstream default: "64Mi".
+	ACMEHTTP01SolverResourceLimitsMemory string `json:"acme-http01-solver-resource-limits-memory"`
+	// ACMEHTTP01SolverResourceRequestCPU maps to --acme-http01-solver-resource-request-cpu. Defines
+	// the resource request CPU size when spawning new ACME HTTP01 challenge solver pods. Upstream
+	// default: "10m".
+	ACMEHTTP01SolverResourceRequestCPU string `json:"acme-http01-solver-resource-request-cpu"`
+	// ACMEHTTP01SolverResourceRequestMemory maps to --acme-http01-solver-resource-request-memory.
+	// Defines the resource request Memory size when spawning new ACME HTTP01 challenge solver pods.
+	// Upstream default: "64Mi".
+	ACMEHTTP01SolverResourceRequestMemory string `json:"acme-http01-solver-resource-request-memory"`
+	// AddDirHeader maps to --add_dir_header. If true, adds the file directory to the header of the log
+	// messages.
+	AddDirHeader bool `json:"add_dir_header"`
+	// Alsologtostderr maps to --alsologtostderr. log to standard error as well as files.
+	Alsologtostderr bool `json:"alsologtostderr"`
+	// AutoCertificateAnnotations maps to --auto-certificate-annotations. The annotation consumed by
+	// the ingress-shim controller to indicate a ingress is requesting a certificate. Upstream default:
+	// [kubernetes.io/tls-acme].
+	AutoCertificateAnnotations []string `json:"auto-certificate-annotations"`
+	// ClusterIssuerAmbientCredentials maps to --cluster-issuer-ambient-credentials. Whether a
+	// cluster-issuer may make use of ambient credentials for issuers. 'Ambient Credentials' are
+	// credentials drawn from the environment, metadata services, or local files which are not
+	// explicitly configured in the ClusterIssuer API object. When this flag is enabled, the following
+	// sources for credentials are also used: AWS - All sources the Go SDK defaults to, notably
+	// including any EC2 IAM roles available via instance metadata. Upstream default: true.
+	ClusterIssuerAmbientCredentials bool `json:"cluster-issuer-ambient-credentials"`
+	// ClusterResourceNamespace maps to --cluster-resource-namespace. Namespace to store resources
+	// owned by cluster scoped resources such as ClusterIssuer in. This must be specified if
+	// ClusterIssuers are enabled. Upstream default: "kube-system".
+	ClusterResourceNamespace string `json:"cluster-resource-namespace"`
+	// Controllers maps to --controllers. The set of controllers to enable. Upstream default: [*].
+	Controllers []string `json:"controllers"`
+	// DefaultIssuerGroup maps to --default-issuer-group. Group of the Issuer to use when the tls is
+	// requested but issuer group is not specified on the ingress resource. Upstream default:
+	// "cert-manager.io".
+	DefaultIssuerGroup string `json:"default-issuer-group"`
+	// DefaultIssuerKind maps to --default-issuer-kind. Kind of the Issuer to use when the tls is
+	// requested but issuer kind is not specified on the ingress resource. Upstream default: "Issuer".
+	DefaultIssuerKind string `json:"default-issuer-kind"`
+	// DefaultIssuerName maps to --default-issuer-name. Name of the Issuer to use when the tls is
+	// requested but issuer name is not specified on the ingress resource.
+	DefaultIssuerName string `json:"default-issuer-name"`
+	// DNS01CheckRetryPeriod maps to --dns01-check-retry-period. The duration the controller should
+	// wait between checking if a ACME dns entry exists. Upstream default: 10s.
+	DNS01CheckRetryPeriod metav1.Duration `json:"dns01-check-retry-period"`
+	// DNS01RecursiveNameservers maps to --dns01-recursive-nameservers. A list of comma separated dns
+	// server endpoints used for DNS01 check requests. This should be a list containing host and port,
+	// for example 8.8.8.8:53,8.8.4.4:53.
+	DNS01RecursiveNameservers []string `json:"dns01-recursive-nameservers"`
+	// DNS01RecursiveNameserversOnly maps to --dns01-recursive-nameservers-only. When true,
+	// cert-manager will only ever query the configured DNS resolvers to perform the ACME DNS01 self
+	// check. This is useful in DNS constrained environments, where access to authoritative nameservers
+	// is restricted. Enabling this option could cause the DNS01 self check to take longer due to
+	// caching performed by the recursive nameservers.
+	DNS01RecursiveNameserversOnly bool `json:"dns01-recursive-nameservers-only"`
+	// EnableCertificateOwnerRef maps to --enable-certificate-owner-ref. Whether to set the certificate
+	// resource as an owner of secret where the tls certificate is stored. When this flag is enabled,
+	// the secret will be automatically removed when the certificate resource is deleted.
+	EnableCertificateOwnerRef bool `json:"enable-certificate-owner-ref"`
+	// EnableProfiling maps to --enable-profiling. Enable profiling for controller.
+	EnableProfiling bool `json:"enable-profiling"`
+	// FeatureGates maps to --feature-gates. A set of key=value pairs that describe feature gates for
+	// alpha/experimental features.
+	FeatureGates map[string]bool `json:"feature-gates"`
+	// IssuerAmbientCredentials maps to --issuer-ambient-credentials. Whether an issuer may make use of
+	// ambient credentials. 'Ambient Credentials' are credentials drawn from the environment, metadata
+	// services, or local files which are not explicitly configured in the Issuer API object. When this
+	// flag is enabled, the following sources for credentials are also used: AWS - All sources the Go
+	// SDK defaults to, notably including any EC2 IAM roles available via instance metadata.
+	IssuerAmbientCredentials bool `json:"issuer-ambient-credentials"`
+	// KubeAPIBurst maps to --kube-api-burst. the maximum burst queries-per-second of requests sent to
+	// the Kubernetes apiserver. Upstream default: 50.
+	KubeAPIBurst int `json:"kube-api-burst"`
+	// KubeAPIQPS maps to --kube-api-qps. indicates the maximum queries-per-second requests to the
+	// Kubernetes apiserver. Upstream default: 20.
+	KubeAPIQPS float32 `json:"kube-api-qps"`
+	// Kubeconfig maps to --kubeconfig. Paths to a kubeconfig. Only required if out-of-cluster.
+	Kubeconfig string `json:"kubeconfig"`
+	// LeaderElect maps to --leader-elect. If true, cert-manager will perform leader election between
+	// instances to ensure no more than one instance of cert-manager operates at a time. Upstream
+	// default: true.
+	LeaderElect bool `json:"leader-elect"`
+	// LeaderElectionLeaseDuration maps to --leader-election-lease-duration. The duration that
+	// non-leader candidates will wait after observing a leadership renewal until attempting to acquire
+	// leadership of a led but unrenewed leader slot. This is effectively the maximum duration that a
+	// leader can be stopped before it is replaced by another candidate. This is only applicable if
+	// leader election is enabled. Upstream default: 1m0s.
+	LeaderElectionLeaseDuration metav1.Duration `json:"leader-election-lease-duration"`
+	// LeaderElectionNamespace maps to --leader-election-namespace. Namespace used to perform leader
+	// election. Only used if leader election is enabled. Upstream default: "kube-system".
+	LeaderElectionNamespace string `json:"leader-election-namespace"`
+	// LeaderElectionRenewDeadline maps to --leader-election-renew-deadline. The interval between
+	// attempts by the acting master to renew a leadership slot before it stops leading. This must be
+	// less than or equal to the lease duration. This is only applicable if leader election is enabled.
+	// Upstream default: 40s.
+	LeaderElectionRenewDeadline metav1.Duration `json:"leader-election-renew-deadline"`
+	// LeaderElectionRetryPeriod maps to --leader-election-retry-period. The duration the clients
+	// should wait between attempting acquisition and renewal of a leadership. This is only applicable
+	// if leader election is enabled. Upstream default: 15s.
+	LeaderElectionRetryPeriod metav1.Duration `json:"leader-election-retry-period"`
+	// LogFlushFrequency maps to --log-flush-frequency. Maximum number of seconds between log flushes.
+	// Upstream default: 5s.
+	LogFlushFrequency metav1.Duration `json:"log-flush-frequency"`
+	// LogBacktraceAt maps to --log_backtrace_at. when logging hits line file:N, emit a stack trace.
+	// Upstream default: :0.
+	LogBacktraceAt string `json:"log_backtrace_at"`
+	// LogDir maps to --log_dir. If non-empty, write log files in this directory.
+	LogDir string `json:"log_dir"`
+	// LogFile maps to --log_file. If non-empty, use this log file.
+	LogFile string `json:"log_file"`
+	// LogFileMaxSize maps to --log_file_max_size. Defines the maximum size a log file can grow to.
+	// Unit is megabytes. If the value is 0, the maximum file size is unlimited. Upstream default:
+	// 1800.
+	LogFileMaxSize uint `json:"log_file_max_size"`
+	// Logtostderr maps to --logtostderr. log to standard error instead of files. Upstream default:
+	// true.
+	Logtostderr bool `json:"logtostderr"`
+	// Master maps to --master. Optional apiserver host address to connect to. If not specified,
+	// autoconfiguration will be attempted.
+	Master string `json:"master"`
+	// MaxConcurrentChallenges maps to --max-concurrent-challenges. The maximum number of challenges
+	// that can be scheduled as 'processing' at once. Upstream default: 60.
+	MaxConcurrentChallenges int `json:"max-concurrent-challenges"`
+	// MetricsListenAddress maps to --metrics-listen-address. The host and port that the metrics
+	// endpoint should listen on. Upstream default: "0.0.0.0:9402".
+	MetricsListenAddress string `json:"metrics-listen-address"`
+	// Namespace maps to --namespace. If set, this limits the scope of cert-manager to a single
+	// namespace and ClusterIssuers are disabled. If not specified, all namespaces will be watched.
+	Namespace string `json:"namespace"`
+	// SkipHeaders maps to --skip_headers. If true, avoid header prefixes in the log messages.
+	SkipHeaders bool `json:"skip_headers"`
+	// SkipLogHeaders maps to --skip_log_headers. If true, avoid headers when opening log files.
+	SkipLogHeaders bool `json:"skip_log_headers"`
+	// Stderrthreshold maps to --stderrthreshold. logs at or above this threshold go to stderr.
+	// Upstream default: 2.
+	Stderrthreshold int32 `json:"stderrthreshold"`
+	// V maps to --v. number for the log level verbosity.
+	V int32 `json:"v"`
+	// Vmodule maps to --vmodule. comma-separated list of pattern=N settings for file-filtered logging.
+	Vmodule string `json:"vmodule"`
 }
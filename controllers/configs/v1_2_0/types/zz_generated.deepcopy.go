@@ -1,22 +1,4 @@
-// +build !ignore_autogenerated
-
-/*
-
-
-Licensed under the Apache License, Version 2.0 (the "License");
-you may not use this file except in compliance with the License.
-You may obtain a copy of the License at
-
-    http://www.apache.org/licenses/LICENSE-2.0
-
-Unless required by applicable law or agreed to in writing, software
-distributed under the License is distributed on an "AS IS" BASIS,
-WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
-See the License for the specific language governing permissions and
-limitations under the License.
-*/
-
-// Code generated by controller-gen. DO NOT EDIT.
+// Code generated by hack/configgen from the cert-manager v1.2.0 flag definitions. DO NOT EDIT.
 
 package types
 
@@ -52,7 +34,10 @@ func (in *CertManagerCAInjectorConfig) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertManagerCAInjectorFlags) DeepCopyInto(out *CertManagerCAInjectorFlags) {
 	*out = *in
-	out.loggingFlags = in.loggingFlags
+	out.LeaderElectionLeaseDuration = in.LeaderElectionLeaseDuration
+	out.LeaderElectionRenewDeadline = in.LeaderElectionRenewDeadline
+	out.LeaderElectionRetryPeriod = in.LeaderElectionRetryPeriod
+	out.LogFlushFrequency = in.LogFlushFrequency
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerCAInjectorFlags.
@@ -93,7 +78,6 @@ func (in *CertManagerControllerConfig) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertManagerControllerFlags) DeepCopyInto(out *CertManagerControllerFlags) {
 	*out = *in
-	out.loggingFlags = in.loggingFlags
 	if in.AutoCertificateAnnotations != nil {
 		in, out := &in.AutoCertificateAnnotations, &out.AutoCertificateAnnotations
 		*out = make([]string, len(*in))
@@ -104,6 +88,7 @@ func (in *CertManagerControllerFlags) DeepCopyInto(out *CertManagerControllerFla
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	out.DNS01CheckRetryPeriod = in.DNS01CheckRetryPeriod
 	if in.DNS01RecursiveNameservers != nil {
 		in, out := &in.DNS01RecursiveNameservers, &out.DNS01RecursiveNameservers
 		*out = make([]string, len(*in))
@@ -111,9 +96,15 @@ func (in *CertManagerControllerFlags) DeepCopyInto(out *CertManagerControllerFla
 	}
 	if in.FeatureGates != nil {
 		in, out := &in.FeatureGates, &out.FeatureGates
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
+	out.LeaderElectionLeaseDuration = in.LeaderElectionLeaseDuration
+	out.LeaderElectionRenewDeadline = in.LeaderElectionRenewDeadline
+	out.LeaderElectionRetryPeriod = in.LeaderElectionRetryPeriod
+	out.LogFlushFrequency = in.LogFlushFrequency
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerControllerFlags.
@@ -154,12 +145,12 @@ func (in *CertManagerWebhookConfig) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertManagerWebhookFlags) DeepCopyInto(out *CertManagerWebhookFlags) {
 	*out = *in
-	out.loggingFlags = in.loggingFlags
 	if in.DynamicServingDNSNames != nil {
 		in, out := &in.DynamicServingDNSNames, &out.DynamicServingDNSNames
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	out.LogFlushFrequency = in.LogFlushFrequency
 	if in.TLSCipherSuites != nil {
 		in, out := &in.TLSCipherSuites, &out.TLSCipherSuites
 		*out = make([]string, len(*in))
@@ -0,0 +1,33 @@
+package podrefresher
+
+import (
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// auditAnnotation reports the restarts of a workload with events and metrics instead of
+// restarting it, when set to true. It can be set on the workload, or on its namespace.
+const auditAnnotation string = "certmanagerdeployment.redhat.io/refresh-audit"
+
+// auditFor returns true if restarts of a workload are only reported, from the annotation on
+// the first of objs that has it, falling back to audit. objs are typically the workload followed
+// by its namespace. An annotation that isn't a boolean is ignored.
+func auditFor(audit bool, objs ...metav1.Object) bool {
+	for _, obj := range objs {
+		if obj == nil {
+			continue
+		}
+
+		val, ok := obj.GetAnnotations()[auditAnnotation]
+		if !ok {
+			continue
+		}
+
+		if b, err := strconv.ParseBool(val); err == nil {
+			return b
+		}
+	}
+
+	return audit
+}
@@ -0,0 +1,42 @@
+package podrefresher
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+)
+
+var _ = Describe("Audit mode", func() {
+	audited := func(val string) metav1.Object {
+		return &metav1.ObjectMeta{Annotations: map[string]string{auditAnnotation: val}}
+	}
+
+	Context("When no object sets the audit annotation", func() {
+		It("Should fall back to the default", func() {
+			Expect(auditFor(false, &metav1.ObjectMeta{}, nil)).To(BeFalse())
+			Expect(auditFor(true, &metav1.ObjectMeta{}, nil)).To(BeTrue())
+		})
+	})
+
+	Context("When objects set the audit annotation", func() {
+		It("Should use the first object with the annotation", func() {
+			Expect(auditFor(false, &metav1.ObjectMeta{}, audited("true"))).To(BeTrue())
+			Expect(auditFor(true, audited("false"), audited("true"))).To(BeFalse())
+		})
+
+		It("Should ignore annotations that aren't booleans", func() {
+			Expect(auditFor(false, audited("maybe"), audited("true"))).To(BeTrue())
+			Expect(auditFor(true, audited("maybe"))).To(BeTrue())
+		})
+	})
+
+	Context("When a policy enables audit mode", func() {
+		It("Should audit the workloads it selects", func() {
+			policy := &operatorsv1alpha1.PodRefreshPolicy{Spec: operatorsv1alpha1.PodRefreshPolicySpec{Audit: true}}
+			Expect(auditFor(false, &metav1.ObjectMeta{}, policyAnnotations(policy))).To(BeTrue())
+			Expect(auditFor(false, audited("false"), policyAnnotations(policy))).To(BeFalse())
+		})
+	})
+})
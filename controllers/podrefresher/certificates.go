@@ -0,0 +1,165 @@
+package podrefresher
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	certificateNotAfterDesc = prometheus.NewDesc(
+		"podrefresher_certificate_not_after_timestamp_seconds",
+		"Time after which the certificate in a cert-manager issued secret is no longer valid, in seconds since the epoch, by namespace and secret.",
+		[]string{"namespace", "secret"}, nil,
+	)
+
+	certificateNotBeforeDesc = prometheus.NewDesc(
+		"podrefresher_certificate_not_before_timestamp_seconds",
+		"Time before which the certificate in a cert-manager issued secret is not yet valid, in seconds since the epoch, by namespace and secret.",
+		[]string{"namespace", "secret"}, nil,
+	)
+
+	certificateInfoDesc = prometheus.NewDesc(
+		"podrefresher_certificate_info",
+		"Issuer and comma separated DNS names of the certificate in a cert-manager issued secret, by namespace and secret. Always 1.",
+		[]string{"namespace", "secret", "issuer", "dns_names"}, nil,
+	)
+)
+
+// certificateInfo is the metadata exported for the certificate in a secret.
+type certificateInfo struct {
+	notBefore time.Time
+	notAfter  time.Time
+	issuer    string
+	// dnsNames are the sorted DNS subject alternative names of the certificate.
+	dnsNames []string
+}
+
+// parseCertificate returns the metadata of the first certificate in the PEM encoded data, which
+// is the leaf certificate of the chains cert-manager stores in secrets.
+func parseCertificate(data []byte) (certificateInfo, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certificateInfo{}, errors.New("no PEM encoded certificate found")
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return certificateInfo{}, err
+		}
+
+		dnsNames := append([]string{}, cert.DNSNames...)
+		sort.Strings(dnsNames)
+		return certificateInfo{
+			notBefore: cert.NotBefore,
+			notAfter:  cert.NotAfter,
+			issuer:    cert.Issuer.String(),
+			dnsNames:  dnsNames,
+		}, nil
+	}
+}
+
+// parsedCertificate is the result of parsing the certificate in a secret at a resource version.
+type parsedCertificate struct {
+	resourceVersion string
+	info            certificateInfo
+	err             error
+}
+
+// certificateCollector exports the validity, issuer and DNS names of the certificates in cert-manager
+// issued secrets read from the reader. Secrets are read when metrics are scraped, so certificates
+// whose Certificate resources were deleted are still exported, and certificates are parsed again
+// only when their secret changes.
+type certificateCollector struct {
+	reader client.Reader
+	log    logr.Logger
+
+	mu     sync.Mutex
+	parsed map[types.NamespacedName]parsedCertificate
+}
+
+// newCertificateCollector returns a certificateCollector reading secrets with the reader.
+func newCertificateCollector(reader client.Reader, log logr.Logger) *certificateCollector {
+	return &certificateCollector{reader: reader, log: log, parsed: make(map[types.NamespacedName]parsedCertificate)}
+}
+
+// Describe implements prometheus.Collector.
+func (c *certificateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- certificateNotAfterDesc
+	ch <- certificateNotBeforeDesc
+	ch <- certificateInfoDesc
+}
+
+// Collect implements prometheus.Collector, exporting the metadata of the certificate in each cert-manager
+// issued secret. Secrets without a certificate that can be parsed are skipped.
+func (c *certificateCollector) Collect(ch chan<- prometheus.Metric) {
+	secrets := corev1.SecretList{}
+	if err := c.reader.List(context.TODO(), &secrets); err != nil {
+		ch <- prometheus.NewInvalidMetric(certificateInfoDesc, err)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	seen := make(map[types.NamespacedName]parsedCertificate, len(secrets.Items))
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if _, isCertManagerIssued := secret.GetAnnotations()[issuerKindAnnotation]; !isCertManagerIssued {
+			continue
+		}
+
+		key := types.NamespacedName{Namespace: secret.GetNamespace(), Name: secret.GetName()}
+		parsed, ok := c.parsed[key]
+		if !ok || parsed.resourceVersion != secret.GetResourceVersion() {
+			parsed = parsedCertificate{resourceVersion: secret.GetResourceVersion()}
+			parsed.info, parsed.err = parseCertificate(secret.Data[corev1.TLSCertKey])
+			if parsed.err != nil {
+				c.log.V(1).Info("Unable to parse certificate", "Namespace", key.Namespace, "Secret", key.Name, "Error", parsed.err.Error())
+			}
+		}
+		seen[key] = parsed
+
+		if parsed.err != nil {
+			continue
+		}
+
+		info := parsed.info
+		ch <- prometheus.MustNewConstMetric(certificateNotAfterDesc, prometheus.GaugeValue, float64(info.notAfter.Unix()), key.Namespace, key.Name)
+		ch <- prometheus.MustNewConstMetric(certificateNotBeforeDesc, prometheus.GaugeValue, float64(info.notBefore.Unix()), key.Namespace, key.Name)
+		ch <- prometheus.MustNewConstMetric(certificateInfoDesc, prometheus.GaugeValue, 1, key.Namespace, key.Name, info.issuer, strings.Join(info.dnsNames, ","))
+	}
+
+	// secrets that were deleted are forgotten.
+	c.parsed = seen
+}
+
+// Start implements manager.Runnable, registering the collector with the controller metrics registry
+// until stop is closed, so that the pod refresher can be stopped and started again.
+func (c *certificateCollector) Start(stop <-chan struct{}) error {
+	if err := metrics.Registry.Register(c); err != nil {
+		return err
+	}
+
+	<-stop
+	metrics.Registry.Unregister(c)
+	return nil
+}
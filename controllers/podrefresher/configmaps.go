@@ -0,0 +1,69 @@
+package podrefresher
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// configMapRefreshReconciler reconciles ConfigMaps that are refresh sources, such as CA trust
+// bundles, refreshing the workloads using them the same way PodRefreshReconciler does for secrets.
+type configMapRefreshReconciler struct {
+	*PodRefreshReconciler
+}
+
+// Reconcile refreshes the workloads using the ConfigMap if it is a refresh source.
+func (r *configMapRefreshReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(context.TODO(), req.NamespacedName, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			optedInWorkloads.DeleteLabelValues(req.Namespace, configMapKeyPrefix+req.Name)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	// requests mapped from workload events may name any ConfigMap the workload references.
+	selected, err := sourceSelected(r.Client, sourceKindConfigMap, configMap)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !selected {
+		r.Log.V(2).Info("ConfigMap is not a refresh source. Disregarding.", "ConfigMap.Name", configMap.GetName(), "ConfigMap.Namespace", configMap.GetNamespace())
+		return reconcile.Result{}, nil
+	}
+	r.Log.Info("ConfigMap is a refresh source. Checking workloads using ConfigMap.", "ConfigMap.Name", configMap.GetName(), "ConfigMap.Namespace", configMap.GetNamespace())
+
+	return r.refreshConsumers(configMapSource(configMap))
+}
+
+// setupWithManager configures a controller owned by the manager mgr, watching ConfigMaps and
+// the workloads and namespaces that may need to be refreshed for them.
+func (r *configMapRefreshReconciler) setupWithManager(mgr ctrl.Manager) error {
+	bldr := ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.ConfigMap{}, builder.WithPredicates(
+			predicate.ResourceVersionChangedPredicate{},
+			refreshSourcePredicate{reader: r.Client, kind: sourceKindConfigMap},
+		))
+
+	for _, adapter := range r.workloads.adapters {
+		bldr = bldr.Watches(
+			&source.Kind{Type: newWorkload(adapter)},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: configMapsForWorkload(adapter)},
+			builder.WithPredicates(optedInPredicate{keys: r.optInKeys()}))
+	}
+
+	bldr = bldr.Watches(
+		&source.Kind{Type: &corev1.Namespace{}},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.configMapsInNamespace)},
+		builder.WithPredicates(namespaceOptedInPredicate{keys: r.optInKeys()}))
+
+	return bldr.Complete(r)
+}
@@ -0,0 +1,217 @@
+package podrefresher
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DependencyReportPath is the path the certificate dependency report is served at.
+	DependencyReportPath = "/certificate-dependencies"
+
+	// dependencyReportShutdownTimeout is how long requests for the report are given to
+	// complete when the server is stopped.
+	dependencyReportShutdownTimeout = 5 * time.Second
+
+	issuerNameAnnotation      string = "cert-manager.io/issuer-name"
+	certificateNameAnnotation string = "cert-manager.io/certificate-name"
+)
+
+// workloadReference identifies a workload consuming a certificate.
+type workloadReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// certificateDependency is a cert-manager issued secret and the workloads consuming it.
+type certificateDependency struct {
+	Namespace string `json:"namespace"`
+	Secret    string `json:"secret"`
+	// Certificate is the name of the Certificate the secret was issued for.
+	Certificate string `json:"certificate,omitempty"`
+	// Issuer is the kind and name of the issuer of the certificate, as Kind/name.
+	Issuer string `json:"issuer,omitempty"`
+	// NotAfter is when the certificate in the secret expires. It is omitted if the secret has no
+	// certificate that can be parsed.
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+	// Consumers are the workloads whose pods reference the secret, sorted by kind and name.
+	Consumers []workloadReference `json:"consumers"`
+}
+
+// dependencyReport maps cert-manager issued secrets to the workloads consuming them.
+type dependencyReport struct {
+	GeneratedAt metav1.Time `json:"generatedAt"`
+	// Certificates are sorted by namespace and secret name.
+	Certificates []certificateDependency `json:"certificates"`
+}
+
+// DependencyReportHandler serves a JSON report of the workloads consuming each cert-manager issued
+// secret, and when the certificate in each secret expires. Workloads are matched to secrets in the
+// same way as they are when refreshing them. The report covers a single namespace if one is given in
+// the namespace query parameter, and the whole cluster otherwise.
+type DependencyReportHandler struct {
+	// Reader lists secrets and workloads. It is typically the manager's cache, so that
+	// requests for the report don't load the API server.
+	Reader client.Reader
+	Log    logr.Logger
+	// ExtraWorkloads are adapters for workload kinds reported in addition to the defaults.
+	ExtraWorkloads []WorkloadAdapter
+}
+
+// ServeHTTP implements http.Handler.
+func (h *DependencyReportHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report, err := buildDependencyReport(h.Reader, newWorkloadRegistry(h.ExtraWorkloads...).adapters, req.URL.Query().Get("namespace"), time.Now())
+	if err != nil {
+		h.Log.Error(err, "Unable to build certificate dependency report")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		h.Log.Error(err, "Unable to write certificate dependency report")
+	}
+}
+
+// DependencyReportServer serves the DependencyReportHandler at the DependencyReportPath of its own
+// address rather than on the metrics endpoint, as the report names every cert-manager issued secret
+// and the workloads consuming it, and requests are not authenticated.
+type DependencyReportServer struct {
+	// Addr is the address the server binds to, such as 127.0.0.1:8081.
+	Addr    string
+	Handler *DependencyReportHandler
+}
+
+// Start implements manager.Runnable. The server runs until stop is closed.
+func (s *DependencyReportServer) Start(stop <-chan struct{}) error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(DependencyReportPath, s.Handler)
+	server := &http.Server{Handler: mux}
+
+	errs := make(chan error, 1)
+	go func() {
+		s.Handler.Log.Info("Serving certificate dependency report", "Address", listener.Addr().String(), "Path", DependencyReportPath)
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			errs <- err
+		}
+		close(errs)
+	}()
+
+	select {
+	case <-stop:
+		ctx, cancel := context.WithTimeout(context.Background(), dependencyReportShutdownTimeout)
+		defer cancel()
+		return server.Shutdown(ctx)
+	case err := <-errs:
+		return err
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The report is served by every
+// replica of the operator, like metrics are.
+func (s *DependencyReportServer) NeedLeaderElection() bool {
+	return false
+}
+
+// buildDependencyReport returns the report of the workloads of the adapters' kinds consuming each
+// cert-manager issued secret in namespace, or in all namespaces if namespace is empty. Workload kinds
+// that are not served by the cluster are skipped.
+func buildDependencyReport(reader client.Reader, adapters []WorkloadAdapter, namespace string, now time.Time) (dependencyReport, error) {
+	secrets := corev1.SecretList{}
+	if err := reader.List(context.TODO(), &secrets, client.InNamespace(namespace)); err != nil {
+		return dependencyReport{}, err
+	}
+
+	dependencies := make(map[types.NamespacedName]*certificateDependency)
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		annotations := secret.GetAnnotations()
+		issuerKind, isCertManagerIssued := annotations[issuerKindAnnotation]
+		if !isCertManagerIssued {
+			continue
+		}
+
+		dependency := &certificateDependency{
+			Namespace:   secret.GetNamespace(),
+			Secret:      secret.GetName(),
+			Certificate: annotations[certificateNameAnnotation],
+			Consumers:   []workloadReference{},
+		}
+		if issuerName, ok := annotations[issuerNameAnnotation]; ok {
+			dependency.Issuer = issuerKind + "/" + issuerName
+		}
+		if info, err := parseCertificate(secret.Data[corev1.TLSCertKey]); err == nil {
+			notAfter := metav1.NewTime(info.notAfter)
+			dependency.NotAfter = &notAfter
+		}
+		dependencies[types.NamespacedName{Namespace: secret.GetNamespace(), Name: secret.GetName()}] = dependency
+	}
+
+	for _, adapter := range adapters {
+		kind := adapter.GroupVersionKind().Kind
+		workloads := newWorkloadList(adapter)
+		if err := reader.List(context.TODO(), workloads, client.InNamespace(namespace)); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return dependencyReport{}, err
+		}
+
+		for i := range workloads.Items {
+			workload := &workloads.Items[i]
+			template, found, err := adapter.PodTemplate(workload)
+			if err != nil || !found {
+				continue
+			}
+
+			for name := range secretsReferencedBy(template.Spec) {
+				dependency, ok := dependencies[types.NamespacedName{Namespace: workload.GetNamespace(), Name: name}]
+				if !ok {
+					continue
+				}
+				dependency.Consumers = append(dependency.Consumers, workloadReference{Kind: kind, Name: workload.GetName()})
+			}
+		}
+	}
+
+	report := dependencyReport{GeneratedAt: metav1.NewTime(now), Certificates: make([]certificateDependency, 0, len(dependencies))}
+	for _, dependency := range dependencies {
+		consumers := dependency.Consumers
+		sort.Slice(consumers, func(i, j int) bool {
+			if consumers[i].Kind != consumers[j].Kind {
+				return consumers[i].Kind < consumers[j].Kind
+			}
+			return consumers[i].Name < consumers[j].Name
+		})
+		report.Certificates = append(report.Certificates, *dependency)
+	}
+	sort.Slice(report.Certificates, func(i, j int) bool {
+		if report.Certificates[i].Namespace != report.Certificates[j].Namespace {
+			return report.Certificates[i].Namespace < report.Certificates[j].Namespace
+		}
+		return report.Certificates[i].Secret < report.Certificates[j].Secret
+	})
+
+	return report, nil
+}
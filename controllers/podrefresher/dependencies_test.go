@@ -0,0 +1,144 @@
+package podrefresher
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// servedKindsReader is a reader that returns the errors the API server does for kinds not registered in the scheme.
+type servedKindsReader struct {
+	client.Reader
+}
+
+func (r servedKindsReader) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	err := r.Reader.List(ctx, list, opts...)
+	if runtime.IsNotRegisteredError(err) {
+		gvk := list.GetObjectKind().GroupVersionKind()
+		return &meta.NoKindMatchError{GroupKind: gvk.GroupKind(), SearchedVersions: []string{gvk.Version}}
+	}
+
+	return err
+}
+
+var _ = Describe("Certificate dependency report", func() {
+	notAfter := time.Date(2021, 1, 31, 0, 0, 0, 0, time.UTC)
+	var c client.Client
+
+	issuedSecret := func(namespace, name string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Annotations: map[string]string{
+				issuerKindAnnotation:      "ClusterIssuer",
+				issuerNameAnnotation:      "ca",
+				certificateNameAnnotation: name,
+			}},
+			Data: map[string][]byte{corev1.TLSCertKey: selfSignedCertificate(notAfter.Add(-time.Hour), notAfter)},
+		}
+	}
+
+	BeforeEach(func() {
+		sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "db"}}
+		sts.Spec.Template.Spec.Volumes = []corev1.Volume{{Name: "tls", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "web-tls"}}}}
+
+		c = fake.NewFakeClientWithScheme(scheme.Scheme,
+			issuedSecret("ns", "web-tls"),
+			issuedSecret("ns", "unused-tls"),
+			issuedSecret("other", "web-tls"),
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "plain"}},
+			deploymentUsingSecrets("ns", "web", "web-tls", "plain"),
+			deploymentUsingSecrets("ns", "api", "web-tls"),
+			sts,
+		)
+	})
+
+	It("Should map each cert-manager issued secret to the workloads consuming it", func() {
+		adapters := []WorkloadAdapter{adapterFor("Deployment"), adapterFor("StatefulSet")}
+		report, err := buildDependencyReport(c, adapters, "", time.Now())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(report.Certificates).To(HaveLen(3))
+		web := report.Certificates[1]
+		Expect(web.Namespace).To(Equal("ns"))
+		Expect(web.Secret).To(Equal("web-tls"))
+		Expect(web.Certificate).To(Equal("web-tls"))
+		Expect(web.Issuer).To(Equal("ClusterIssuer/ca"))
+		Expect(web.NotAfter.Time).To(BeTemporally("==", notAfter))
+		Expect(web.Consumers).To(Equal([]workloadReference{
+			{Kind: "Deployment", Name: "api"},
+			{Kind: "Deployment", Name: "web"},
+			{Kind: "StatefulSet", Name: "db"},
+		}))
+
+		Expect(report.Certificates[0].Secret).To(Equal("unused-tls"))
+		Expect(report.Certificates[0].Consumers).To(BeEmpty())
+		Expect(report.Certificates[2].Namespace).To(Equal("other"))
+		Expect(report.Certificates[2].Consumers).To(BeEmpty())
+	})
+
+	It("Should serve the report for a namespace as JSON", func() {
+		h := &DependencyReportHandler{Reader: servedKindsReader{c}, Log: logf.Log}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DependencyReportPath+"?namespace=other", nil))
+		Expect(rec.Code).To(Equal(http.StatusOK), rec.Body.String())
+
+		report := dependencyReport{}
+		Expect(json.Unmarshal(rec.Body.Bytes(), &report)).To(Succeed())
+		Expect(report.Certificates).To(HaveLen(1))
+		Expect(report.Certificates[0].Namespace).To(Equal("other"))
+	})
+
+	It("Should only serve GET requests", func() {
+		h := &DependencyReportHandler{Reader: c, Log: logf.Log}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, DependencyReportPath, nil))
+		Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("Should serve the report on its own address until stopped", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		addr := listener.Addr().String()
+		Expect(listener.Close()).To(Succeed())
+
+		server := &DependencyReportServer{Addr: addr, Handler: &DependencyReportHandler{Reader: servedKindsReader{c}, Log: logf.Log}}
+		Expect(server.NeedLeaderElection()).To(BeFalse())
+
+		stop := make(chan struct{})
+		stopped := make(chan error)
+		go func() {
+			stopped <- server.Start(stop)
+		}()
+
+		Eventually(func() (int, error) {
+			resp, err := http.Get("http://" + addr + DependencyReportPath)
+			if err != nil {
+				return 0, err
+			}
+			resp.Body.Close()
+			return resp.StatusCode, nil
+		}, 5*time.Second, 50*time.Millisecond).Should(Equal(http.StatusOK))
+
+		resp, err := http.Get("http://" + addr + "/metrics")
+		Expect(err).ToNot(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+
+		close(stop)
+		Eventually(stopped, 5*time.Second).Should(Receive(BeNil()))
+	})
+})
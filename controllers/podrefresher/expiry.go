@@ -0,0 +1,96 @@
+package podrefresher
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// expiryCheck is the result of checking whether the certificate in a refresh source is close to expiring.
+type expiryCheck struct {
+	// warning is the event the workloads using the source are warned with, or nil if they are not warned.
+	warning  *podRefresherEvent
+	notAfter time.Time
+	// recheckAfter is how long until the certificate should be checked again, or 0 if it doesn't need to be.
+	recheckAfter time.Duration
+}
+
+// checkExpiry checks whether the certificate in the secret src expires within window of now. Certificates
+// that are not yet within window are checked again when they enter it, and certificates within it are
+// checked again when they expire. Sources that are not secrets with a certificate that can be parsed are
+// not checked, nor are any sources if window is 0.
+func checkExpiry(src refreshSource, window time.Duration, now time.Time) expiryCheck {
+	if window <= 0 || src.secret == nil {
+		return expiryCheck{}
+	}
+
+	info, err := parseCertificate(src.data[corev1.TLSCertKey])
+	if err != nil {
+		return expiryCheck{}
+	}
+
+	check := expiryCheck{notAfter: info.notAfter}
+	remaining := info.notAfter.Sub(now)
+	switch {
+	case remaining > window:
+		check.recheckAfter = remaining - window
+	case remaining > 0:
+		check.warning = &certificateExpiring
+		check.recheckAfter = remaining
+	default:
+		check.warning = &certificateExpired
+	}
+
+	return check
+}
+
+// expiryWarnings tracks the workloads that have been warned about the certificate in each secret, so
+// that each workload is warned once that the certificate is expiring, and once that it has expired,
+// rather than every time the secret is reconciled.
+type expiryWarnings struct {
+	mu      sync.Mutex
+	secrets map[types.NamespacedName]warnedCertificate
+}
+
+// warnedCertificate is the certificate in a secret, and the warnings sent about it.
+type warnedCertificate struct {
+	notAfter time.Time
+	// warned are the warnings sent, keyed by the event reason and the workload.
+	warned map[string]struct{}
+}
+
+// newExpiryWarnings returns an empty expiryWarnings.
+func newExpiryWarnings() *expiryWarnings {
+	return &expiryWarnings{secrets: make(map[types.NamespacedName]warnedCertificate)}
+}
+
+// warn records that workload is warned with reason about the certificate expiring at notAfter in the
+// secret, returning false if it already was. Warnings about a previous certificate in the secret are forgotten.
+func (w *expiryWarnings) warn(secret types.NamespacedName, notAfter time.Time, reason, workload string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	certificate, ok := w.secrets[secret]
+	if !ok || !certificate.notAfter.Equal(notAfter) {
+		certificate = warnedCertificate{notAfter: notAfter, warned: make(map[string]struct{})}
+		w.secrets[secret] = certificate
+	}
+
+	key := reason + "/" + workload
+	if _, ok := certificate.warned[key]; ok {
+		return false
+	}
+
+	certificate.warned[key] = struct{}{}
+	return true
+}
+
+// forget stops tracking the warnings about the certificate in the secret, as it was deleted or renewed.
+func (w *expiryWarnings) forget(secret types.NamespacedName) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.secrets, secret)
+}
@@ -0,0 +1,72 @@
+package podrefresher
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Certificate expiry warnings", func() {
+	notAfter := time.Date(2021, 1, 31, 0, 0, 0, 0, time.UTC)
+	window := 7 * 24 * time.Hour
+	src := secretSource(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "web-tls"},
+		Data:       map[string][]byte{corev1.TLSCertKey: selfSignedCertificate(notAfter.Add(-90*24*time.Hour), notAfter)},
+	})
+
+	It("Should check again when the certificate enters the window", func() {
+		check := checkExpiry(src, window, notAfter.Add(-10*24*time.Hour))
+		Expect(check.warning).To(BeNil())
+		Expect(check.recheckAfter).To(Equal(3 * 24 * time.Hour))
+	})
+
+	It("Should warn within the window and check again when the certificate expires", func() {
+		check := checkExpiry(src, window, notAfter.Add(-24*time.Hour))
+		Expect(check.warning).To(Equal(&certificateExpiring))
+		Expect(check.notAfter).To(BeTemporally("==", notAfter))
+		Expect(check.recheckAfter).To(Equal(24 * time.Hour))
+	})
+
+	It("Should warn that expired certificates have expired", func() {
+		check := checkExpiry(src, window, notAfter.Add(time.Minute))
+		Expect(check.warning).To(Equal(&certificateExpired))
+		Expect(check.recheckAfter).To(BeZero())
+	})
+
+	It("Should not check when disabled or without a certificate", func() {
+		Expect(checkExpiry(src, 0, notAfter)).To(Equal(expiryCheck{}))
+		Expect(checkExpiry(secretSource(&corev1.Secret{}), window, notAfter)).To(Equal(expiryCheck{}))
+		Expect(checkExpiry(configMapSource(&corev1.ConfigMap{}), window, notAfter)).To(Equal(expiryCheck{}))
+	})
+
+	Context("When tracking the warnings sent", func() {
+		secret := types.NamespacedName{Namespace: "ns", Name: "web-tls"}
+		var warnings *expiryWarnings
+
+		BeforeEach(func() {
+			warnings = newExpiryWarnings()
+		})
+
+		It("Should warn each workload once for each certificate", func() {
+			Expect(warnings.warn(secret, notAfter, certificateExpiring.reason, "Deployment/web")).To(BeTrue())
+			Expect(warnings.warn(secret, notAfter, certificateExpiring.reason, "Deployment/web")).To(BeFalse())
+			Expect(warnings.warn(secret, notAfter, certificateExpiring.reason, "Deployment/api")).To(BeTrue())
+			Expect(warnings.warn(secret, notAfter, certificateExpired.reason, "Deployment/web")).To(BeTrue())
+		})
+
+		It("Should warn again about a new certificate", func() {
+			Expect(warnings.warn(secret, notAfter, certificateExpiring.reason, "Deployment/web")).To(BeTrue())
+			Expect(warnings.warn(secret, notAfter.Add(90*24*time.Hour), certificateExpiring.reason, "Deployment/web")).To(BeTrue())
+		})
+
+		It("Should warn again after forgetting the secret", func() {
+			Expect(warnings.warn(secret, notAfter, certificateExpiring.reason, "Deployment/web")).To(BeTrue())
+			warnings.forget(secret)
+			Expect(warnings.warn(secret, notAfter, certificateExpiring.reason, "Deployment/web")).To(BeTrue())
+		})
+	})
+})
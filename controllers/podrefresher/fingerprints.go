@@ -0,0 +1,74 @@
+package podrefresher
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// secretFingerprintAnnotation records the fingerprint of each secret a workload was last restarted for.
+	secretFingerprintAnnotation string = "certmanagerdeployment.redhat.io/secret-fingerprints"
+	// restartOnAnnotation selects which changes to a secret restart a workload. It can be set
+	// on the workload, or on its namespace.
+	restartOnAnnotation string = "certmanagerdeployment.redhat.io/restart-on"
+)
+
+// Values of the restartOnAnnotation.
+const (
+	// restartOnAny restarts a workload when any of the fingerprinted keys change. This is the default.
+	restartOnAny = "any"
+	// restartOnLeaf restarts a workload when the leaf certificate or its key change.
+	restartOnLeaf = "leaf"
+	// restartOnCA restarts a workload when the CA certificate changes. Secrets without one never restart it.
+	restartOnCA = "ca"
+)
+
+// DefaultFingerprintKeys are the secret keys fingerprinted to detect that a certificate has changed.
+var DefaultFingerprintKeys = []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey, caCertKey}
+
+// caCertKey is the key of the CA certificate in secrets issued by cert-manager.
+const caCertKey = "ca.crt"
+
+// fingerprintKeysFor returns the secret keys fingerprinted for a workload, selected by the
+// restartOnAnnotation of the first of objs that has it, and true if they were selected by it.
+// If none do, keys are used.
+func fingerprintKeysFor(keys []string, objs ...metav1.Object) ([]string, bool) {
+	restartOn, _ := annotationFor(restartOnAnnotation, objs...)
+	switch restartOn {
+	case restartOnLeaf:
+		return []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey}, true
+	case restartOnCA:
+		return []string{caCertKey}, true
+	default:
+		return keys, false
+	}
+}
+
+// dataFingerprint returns a hash of the values of keys in data. Changes to the source's
+// metadata or to other keys do not change the fingerprint.
+func dataFingerprint(data map[string][]byte, keys []string) string {
+	sorted := append([]string{}, keys...)
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	for _, key := range sorted {
+		value, ok := data[key]
+		if !ok {
+			// a missing key contributes nothing, unlike a key with an empty value.
+			continue
+		}
+
+		// length-prefix keys and values so that their boundaries are unambiguous.
+		for _, field := range [][]byte{[]byte(key), value} {
+			_ = binary.Write(h, binary.BigEndian, uint64(len(field)))
+			h.Write(field)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,169 @@
+package podrefresher
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Secret fingerprints", func() {
+	var secret *corev1.Secret
+
+	BeforeEach(func() {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "tls", ResourceVersion: "1"},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       []byte("leaf"),
+				corev1.TLSPrivateKeyKey: []byte("key"),
+				caCertKey:               []byte("ca"),
+			},
+		}
+	})
+
+	Context("When only the secret's metadata changes", func() {
+		It("Should not change the fingerprint", func() {
+			before := secretSource(secret).fingerprint(DefaultFingerprintKeys)
+			secret.SetResourceVersion("2")
+			secret.SetAnnotations(map[string]string{"cert-manager.io/issuer-kind": "Issuer"})
+			Expect(secretSource(secret).fingerprint(DefaultFingerprintKeys)).To(Equal(before))
+		})
+	})
+
+	Context("When keys that are not fingerprinted change", func() {
+		It("Should not change the fingerprint", func() {
+			before := secretSource(secret).fingerprint(DefaultFingerprintKeys)
+			secret.Data["keystore.jks"] = []byte("keystore")
+			Expect(secretSource(secret).fingerprint(DefaultFingerprintKeys)).To(Equal(before))
+		})
+	})
+
+	Context("When a fingerprinted key changes", func() {
+		It("Should change the fingerprint", func() {
+			before := secretSource(secret).fingerprint(DefaultFingerprintKeys)
+			secret.Data[corev1.TLSCertKey] = []byte("renewed")
+			Expect(secretSource(secret).fingerprint(DefaultFingerprintKeys)).ToNot(Equal(before))
+		})
+
+		It("Should distinguish a missing key from an empty key", func() {
+			delete(secret.Data, caCertKey)
+			before := secretSource(secret).fingerprint(DefaultFingerprintKeys)
+			secret.Data[caCertKey] = []byte{}
+			Expect(secretSource(secret).fingerprint(DefaultFingerprintKeys)).ToNot(Equal(before))
+		})
+
+		It("Should not depend on the order of the keys", func() {
+			Expect(secretSource(secret).fingerprint([]string{caCertKey, corev1.TLSCertKey})).
+				To(Equal(secretSource(secret).fingerprint([]string{corev1.TLSCertKey, caCertKey})))
+		})
+	})
+
+	Context("When a workload restarts only on some changes", func() {
+		var workload *metav1.ObjectMeta
+
+		BeforeEach(func() {
+			workload = &metav1.ObjectMeta{Annotations: map[string]string{}}
+		})
+
+		It("Should use the given keys by default", func() {
+			keys, selected := fingerprintKeysFor([]string{"custom"}, workload)
+			Expect(keys).To(Equal([]string{"custom"}))
+			Expect(selected).To(BeFalse())
+		})
+
+		It("Should ignore CA changes for leaf-only workloads", func() {
+			workload.Annotations[restartOnAnnotation] = restartOnLeaf
+			keys, selected := fingerprintKeysFor(DefaultFingerprintKeys, workload)
+			Expect(selected).To(BeTrue())
+			before := secretSource(secret).fingerprint(keys)
+			secret.Data[caCertKey] = []byte("new ca")
+			Expect(secretSource(secret).fingerprint(keys)).To(Equal(before))
+			secret.Data[corev1.TLSCertKey] = []byte("renewed")
+			Expect(secretSource(secret).fingerprint(keys)).ToNot(Equal(before))
+		})
+
+		It("Should ignore leaf changes for CA-only workloads", func() {
+			workload.Annotations[restartOnAnnotation] = restartOnCA
+			keys, selected := fingerprintKeysFor(DefaultFingerprintKeys, workload)
+			Expect(selected).To(BeTrue())
+			before := secretSource(secret).fingerprint(keys)
+			secret.Data[corev1.TLSCertKey] = []byte("renewed")
+			Expect(secretSource(secret).fingerprint(keys)).To(Equal(before))
+			secret.Data[caCertKey] = []byte("new ca")
+			Expect(secretSource(secret).fingerprint(keys)).ToNot(Equal(before))
+		})
+	})
+
+	Context("When a CA-only workload uses a secret without a CA certificate", func() {
+		It("Should not restart it when the leaf certificate changes, and report why", func() {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "tls"},
+				Data:       map[string][]byte{corev1.TLSCertKey: []byte("leaf"), corev1.TLSPrivateKeyKey: []byte("key")},
+			}
+			deploy := deploymentUsingSecrets("ns", "web", "tls")
+			deploy.SetAnnotations(map[string]string{allowRestartAnnotation: "true", restartOnAnnotation: restartOnCA})
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}}
+			c := fake.NewFakeClientWithScheme(scheme.Scheme, deploy, secret, namespace)
+			recorder := record.NewFakeRecorder(10)
+			r := &PodRefreshReconciler{Client: c, Log: logf.Log, EventRecorder: recorder, apiReader: c, scheduler: newRestartScheduler(0, 0)}
+
+			adapter := adapterFor("Deployment")
+			workload := newWorkload(adapter)
+			Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: "web"}, workload)).To(Succeed())
+
+			secret.Data[corev1.TLSCertKey] = []byte("renewed")
+			Expect(r.refreshWorkload(adapter, secretSource(secret), workload, nil, namespace)).To(BeZero())
+
+			Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: "web"}, workload)).To(Succeed())
+			_, restarted, err := unstructured.NestedString(workload.Object, "spec", "template", "metadata", "labels", timeRestartedLabel)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restarted).To(BeFalse())
+			Expect(recorder.Events).To(Receive(ContainSubstring(refreshIgnored.reason)))
+		})
+	})
+
+	Context("When checking whether a workload uses an outdated secret", func() {
+		var annotations map[string]string
+
+		BeforeEach(func() {
+			annotations = map[string]string{}
+		})
+
+		It("Should be outdated if nothing is recorded for the secret", func() {
+			Expect(outdatedSecretInUse("tls", "1", "abc", annotations)).To(BeTrue())
+		})
+
+		It("Should compare the recorded fingerprint", func() {
+			updateSecretRevisionAnnotation(secretFingerprintAnnotation, "tls", "abc", annotations)
+			updateSecretRevisionAnnotation(secretResourceVersionAnnotation, "tls", "1", annotations)
+			Expect(outdatedSecretInUse("tls", "2", "abc", annotations)).To(BeFalse())
+			Expect(outdatedSecretInUse("tls", "1", "def", annotations)).To(BeTrue())
+		})
+
+		It("Should compare the resource version if no fingerprint was recorded", func() {
+			updateSecretRevisionAnnotation(secretResourceVersionAnnotation, "tls", "1", annotations)
+			Expect(outdatedSecretInUse("tls", "1", "abc", annotations)).To(BeFalse())
+			Expect(outdatedSecretInUse("tls", "2", "abc", annotations)).To(BeTrue())
+		})
+
+		It("Should keep the entries of other secrets when recording a secret", func() {
+			updateSecretRevisionAnnotation(secretFingerprintAnnotation, "other", "xyz", annotations)
+			updateSecretRevisionAnnotation(secretFingerprintAnnotation, "tls", "abc", annotations)
+			Expect(annotations[secretFingerprintAnnotation]).To(MatchJSON(`{"other": "xyz", "tls": "abc"}`))
+		})
+
+		It("Should replace a malformed annotation when recording a secret", func() {
+			annotations[secretFingerprintAnnotation] = "not-json"
+			updateSecretRevisionAnnotation(secretFingerprintAnnotation, "tls", "abc", annotations)
+			Expect(annotations[secretFingerprintAnnotation]).To(MatchJSON(`{"tls": "abc"}`))
+		})
+	})
+})
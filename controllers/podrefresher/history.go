@@ -0,0 +1,102 @@
+package podrefresher
+
+import (
+	"context"
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientretry "k8s.io/client-go/util/retry"
+)
+
+const (
+	// refreshHistoryConfigMap is the name of the ConfigMap in each namespace recording the history of
+	// refreshes in the namespace. Each key is the name of a secret, or of a ConfigMap prefixed with
+	// configMapKeyPrefix, and each value is a JSON list of refreshRecords for the workloads refreshed
+	// when it changed, oldest first.
+	refreshHistoryConfigMap string = "pod-refresher-history"
+	// refreshHistoryLimit is the maximum number of records kept for each secret. Older records are dropped.
+	refreshHistoryLimit = 20
+)
+
+// refreshResult is the outcome of refreshing a workload.
+type refreshResult string
+
+const (
+	// refreshRestarted is recorded when a workload is restarted.
+	refreshRestarted refreshResult = "Restarted"
+	// refreshFailed is recorded when a workload could not be restarted.
+	refreshFailed refreshResult = "Failed"
+	// refreshReloaded is recorded when the pods of a workload reload their certificates in place.
+	refreshReloaded refreshResult = "Reloaded"
+	// refreshAudited is recorded when a workload would have been restarted in audit mode.
+	refreshAudited refreshResult = "Audited"
+	// refreshCompleted is recorded when the rollout of a restarted workload completes.
+	refreshCompleted refreshResult = "Completed"
+	// refreshTimedOut is recorded when the rollout of a restarted workload does not complete in time.
+	refreshTimedOut refreshResult = "TimedOut"
+)
+
+// refreshRecord is an entry in the refresh history of a secret.
+type refreshRecord struct {
+	Kind   string        `json:"kind"`
+	Name   string        `json:"name"`
+	Time   metav1.Time   `json:"time"`
+	Result refreshResult `json:"result"`
+	// Reason explains why the workload was refreshed, or why the refresh failed.
+	Reason string `json:"reason,omitempty"`
+}
+
+// appendRefreshRecord appends the record to the history of the secret in data, dropping the oldest
+// records beyond the refreshHistoryLimit. A malformed history is replaced.
+func appendRefreshRecord(data map[string]string, secretName string, record refreshRecord) {
+	var records []refreshRecord
+	if err := json.Unmarshal([]byte(data[secretName]), &records); err != nil {
+		records = nil
+	}
+
+	records = append(records, record)
+	if len(records) > refreshHistoryLimit {
+		records = records[len(records)-refreshHistoryLimit:]
+	}
+
+	d, _ := json.Marshal(records)
+	data[secretName] = string(d)
+}
+
+// recordRefresh adds the record to the refresh history of the secret in namespace. The history is
+// informational, so failing to record it is logged and does not fail the refresh. Secrets and
+// ConfigMaps are refreshed by separate controllers, so conflicting updates are retried.
+func (r *PodRefreshReconciler) recordRefresh(namespace, secretName string, record refreshRecord) {
+	err := clientretry.RetryOnConflict(clientretry.DefaultRetry, func() error {
+		return r.updateRefreshHistory(namespace, secretName, record)
+	})
+	if err != nil {
+		r.Log.Error(err, "Unable to record refresh history", "Namespace", namespace, "Secret", secretName, "Kind", record.Kind, "Name", record.Name)
+	}
+}
+
+// updateRefreshHistory adds the record to the refresh history ConfigMap in namespace, creating it
+// if it does not exist.
+func (r *PodRefreshReconciler) updateRefreshHistory(namespace, secretName string, record refreshRecord) error {
+	history := &corev1.ConfigMap{}
+	err := r.apiReader.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: refreshHistoryConfigMap}, history)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	if apierrors.IsNotFound(err) {
+		history = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: refreshHistoryConfigMap}}
+		history.Data = make(map[string]string)
+		appendRefreshRecord(history.Data, secretName, record)
+		return r.Create(context.TODO(), history)
+	}
+
+	if history.Data == nil {
+		history.Data = make(map[string]string)
+	}
+	appendRefreshRecord(history.Data, secretName, record)
+	return r.Update(context.TODO(), history)
+}
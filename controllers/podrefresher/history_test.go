@@ -0,0 +1,73 @@
+package podrefresher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Refresh history", func() {
+	record := func(name string, result refreshResult) refreshRecord {
+		return refreshRecord{Kind: "Deployment", Name: name, Time: metav1.NewTime(time.Date(2020, 11, 2, 0, 0, 0, 0, time.Local)), Result: result}
+	}
+
+	historyOf := func(data map[string]string, secretName string) []refreshRecord {
+		var records []refreshRecord
+		Expect(json.Unmarshal([]byte(data[secretName]), &records)).To(Succeed())
+		return records
+	}
+
+	Context("When appending records", func() {
+		It("Should keep records for each secret separately", func() {
+			data := make(map[string]string)
+			appendRefreshRecord(data, "tls", record("web", refreshRestarted))
+			appendRefreshRecord(data, "tls", record("web", refreshCompleted))
+			appendRefreshRecord(data, "internal", record("api", refreshFailed))
+
+			Expect(historyOf(data, "tls")).To(Equal([]refreshRecord{record("web", refreshRestarted), record("web", refreshCompleted)}))
+			Expect(historyOf(data, "internal")).To(Equal([]refreshRecord{record("api", refreshFailed)}))
+		})
+
+		It("Should drop the oldest records beyond the limit", func() {
+			data := make(map[string]string)
+			for i := 0; i < refreshHistoryLimit+5; i++ {
+				appendRefreshRecord(data, "tls", record(fmt.Sprintf("web-%d", i), refreshRestarted))
+			}
+
+			records := historyOf(data, "tls")
+			Expect(records).To(HaveLen(refreshHistoryLimit))
+			Expect(records[0].Name).To(Equal("web-5"))
+			Expect(records[refreshHistoryLimit-1].Name).To(Equal(fmt.Sprintf("web-%d", refreshHistoryLimit+4)))
+		})
+
+		It("Should replace a malformed history", func() {
+			data := map[string]string{"tls": "not json"}
+			appendRefreshRecord(data, "tls", record("web", refreshAudited))
+			Expect(historyOf(data, "tls")).To(Equal([]refreshRecord{record("web", refreshAudited)}))
+		})
+	})
+
+	Context("When recording refreshes", func() {
+		It("Should create and update the history ConfigMap in the namespace", func() {
+			c := fake.NewFakeClientWithScheme(scheme.Scheme)
+			r := &PodRefreshReconciler{Client: c, Log: logf.Log, apiReader: c}
+
+			r.recordRefresh("ns", "tls", record("web", refreshRestarted))
+			r.recordRefresh("ns", "tls", record("web", refreshCompleted))
+
+			history := &corev1.ConfigMap{}
+			Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: refreshHistoryConfigMap}, history)).To(Succeed())
+			Expect(historyOf(history.Data, "tls")).To(Equal([]refreshRecord{record("web", refreshRestarted), record("web", refreshCompleted)}))
+		})
+	})
+})
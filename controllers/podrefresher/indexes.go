@@ -0,0 +1,113 @@
+package podrefresher
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// secretReferenceIndex is the cache field index mapping a secret name to the workloads
+	// in the same namespace that reference it.
+	secretReferenceIndex = "podrefresher.secretReferences"
+	// configMapReferenceIndex is the cache field index mapping a ConfigMap name to the workloads
+	// in the same namespace that reference it.
+	configMapReferenceIndex = "podrefresher.configMapReferences"
+)
+
+// referencesFunc returns the names of the objects of a kind referenced by a pod spec.
+type referencesFunc func(corev1.PodSpec) map[string]struct{}
+
+// setupIndexes adds the secretReferenceIndex and configMapReferenceIndex to the cache for each
+// workload kind in the registry.
+func setupIndexes(indexer client.FieldIndexer, registry *workloadRegistry) error {
+	for _, adapter := range registry.adapters {
+		if err := indexer.IndexField(context.TODO(), newWorkload(adapter), secretReferenceIndex, indexSecretReferences(adapter)); err != nil {
+			return err
+		}
+
+		if err := indexer.IndexField(context.TODO(), newWorkload(adapter), configMapReferenceIndex, indexConfigMapReferences(adapter)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// indexSecretReferences returns an IndexerFunc returning the names of the secrets
+// referenced by the pod template of a workload handled by the adapter.
+func indexSecretReferences(adapter WorkloadAdapter) client.IndexerFunc {
+	return func(obj runtime.Object) []string {
+		return secretNamesReferencedBy(adapter, obj)
+	}
+}
+
+// indexConfigMapReferences returns an IndexerFunc returning the names of the ConfigMaps
+// referenced by the pod template of a workload handled by the adapter.
+func indexConfigMapReferences(adapter WorkloadAdapter) client.IndexerFunc {
+	return func(obj runtime.Object) []string {
+		return namesReferencedBy(adapter, obj, configMapsReferencedBy)
+	}
+}
+
+// secretNamesReferencedBy returns the sorted names of the secrets referenced by the pod template
+// of a workload handled by the adapter.
+func secretNamesReferencedBy(adapter WorkloadAdapter, obj runtime.Object) []string {
+	return namesReferencedBy(adapter, obj, secretsReferencedBy)
+}
+
+// namesReferencedBy returns the sorted names of the objects returned by references for the pod
+// template of a workload handled by the adapter.
+func namesReferencedBy(adapter WorkloadAdapter, obj runtime.Object, references referencesFunc) []string {
+	workload, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+
+	template, found, err := adapter.PodTemplate(workload)
+	if err != nil || !found {
+		return nil
+	}
+
+	refs := references(template.Spec)
+	names := make([]string, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// secretsForWorkload returns a mapper of workloads handled by the adapter to reconcile requests
+// for each secret referenced by the workload's pod template.
+func secretsForWorkload(adapter WorkloadAdapter) handler.ToRequestsFunc {
+	return referencedByWorkload(adapter, secretsReferencedBy)
+}
+
+// configMapsForWorkload returns a mapper of workloads handled by the adapter to reconcile requests
+// for each ConfigMap referenced by the workload's pod template.
+func configMapsForWorkload(adapter WorkloadAdapter) handler.ToRequestsFunc {
+	return referencedByWorkload(adapter, configMapsReferencedBy)
+}
+
+// referencedByWorkload returns a mapper of workloads handled by the adapter to reconcile requests
+// for each object returned by references for the workload's pod template.
+func referencedByWorkload(adapter WorkloadAdapter, references referencesFunc) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []reconcile.Request {
+		names := namesReferencedBy(adapter, o.Object, references)
+		reqs := make([]reconcile.Request, 0, len(names))
+		for _, name := range names {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: o.Meta.GetNamespace()}})
+		}
+
+		return reqs
+	}
+}
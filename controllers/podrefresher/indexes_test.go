@@ -0,0 +1,195 @@
+package podrefresher
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// deploymentUsingSecrets returns a deployment mounting each of the named secrets as a volume.
+func deploymentUsingSecrets(namespace, name string, secrets ...string) *appsv1.Deployment {
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	for _, secret := range secrets {
+		deploy.Spec.Template.Spec.Volumes = append(deploy.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name:         secret,
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secret}},
+		})
+	}
+
+	return deploy
+}
+
+// toUnstructured returns obj as an unstructured object, as workloads are read by the pod refresher.
+func toUnstructured(obj runtime.Object) *unstructured.Unstructured {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		panic(err)
+	}
+
+	return &unstructured.Unstructured{Object: content}
+}
+
+// adapterFor returns the default workload adapter for kind.
+func adapterFor(kind string) WorkloadAdapter {
+	for _, adapter := range defaultWorkloadAdapters() {
+		if adapter.GroupVersionKind().Kind == kind {
+			return adapter
+		}
+	}
+
+	panic("no default workload adapter for " + kind)
+}
+
+var _ = Describe("Secret reference indexes", func() {
+	Context("When indexing a workload", func() {
+		It("Should return the referenced secrets in sorted order", func() {
+			deploy := deploymentUsingSecrets("ns", "web", "b-secret", "a-secret")
+			deploy.Spec.Template.Spec.Containers = []corev1.Container{
+				{
+					Name: "web",
+					EnvFrom: []corev1.EnvFromSource{
+						{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "a-secret"}}},
+					},
+				},
+			}
+			Expect(indexSecretReferences(adapterFor("Deployment"))(toUnstructured(deploy))).To(Equal([]string{"a-secret", "b-secret"}))
+		})
+
+		It("Should index workloads using the adapter's pod template", func() {
+			template := deploymentUsingSecrets("ns", "web", "tls").Spec.Template
+			Expect(indexSecretReferences(adapterFor("DaemonSet"))(toUnstructured(&appsv1.DaemonSet{Spec: appsv1.DaemonSetSpec{Template: template}}))).To(Equal([]string{"tls"}))
+			Expect(indexSecretReferences(adapterFor("StatefulSet"))(toUnstructured(&appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Template: template}}))).To(Equal([]string{"tls"}))
+		})
+
+		It("Should not index workloads without a pod template", func() {
+			Expect(indexSecretReferences(adapterFor("CronJob"))(toUnstructured(deploymentUsingSecrets("ns", "web", "tls")))).To(BeEmpty())
+		})
+	})
+
+	Context("When mapping a workload event to requests", func() {
+		It("Should request a reconcile for each referenced secret in the workload's namespace", func() {
+			deploy := deploymentUsingSecrets("ns", "web", "tls", "ca")
+			obj := toUnstructured(deploy)
+			reqs := secretsForWorkload(adapterFor("Deployment"))(handler.MapObject{Meta: obj, Object: obj})
+			Expect(reqs).To(HaveLen(2))
+			Expect(reqs[0].NamespacedName).To(Equal(types.NamespacedName{Namespace: "ns", Name: "ca"}))
+			Expect(reqs[1].NamespacedName).To(Equal(types.NamespacedName{Namespace: "ns", Name: "tls"}))
+		})
+	})
+
+	Context("When filtering workload events", func() {
+		var (
+			optedOut = deploymentUsingSecrets("ns", "web", "tls")
+			optedIn  = deploymentUsingSecrets("ns", "web", "tls")
+		)
+		optedIn.SetAnnotations(map[string]string{allowRestartAnnotation: "true"})
+
+		It("Should pass updates where the workload opts in", func() {
+			Expect(optedInPredicate{keys: newOptInKeys("")}.Update(event.UpdateEvent{MetaOld: optedOut, MetaNew: optedIn})).To(BeTrue())
+		})
+
+		It("Should filter updates where the workload was already opted in", func() {
+			Expect(optedInPredicate{keys: newOptInKeys("")}.Update(event.UpdateEvent{MetaOld: optedIn, MetaNew: optedIn})).To(BeFalse())
+			Expect(optedInPredicate{keys: newOptInKeys("")}.Update(event.UpdateEvent{MetaOld: optedIn, MetaNew: optedOut})).To(BeFalse())
+		})
+
+		It("Should pass creates", func() {
+			Expect(optedInPredicate{}.Create(event.CreateEvent{Meta: optedOut})).To(BeTrue())
+		})
+	})
+})
+
+// benchmarkIndexer returns a cache indexer populated the same way as the manager's cache, holding
+// the given number of deployments that each use their own secret, of which the first consumers also use
+// a shared secret.
+func benchmarkIndexer(b *testing.B, workloads, consumers int) cache.Indexer {
+	indexFunc := indexSecretReferences(adapterFor("Deployment"))
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		secretReferenceIndex: func(obj interface{}) ([]string, error) {
+			ns := obj.(metav1.Object).GetNamespace()
+			var keys []string
+			for _, name := range indexFunc(obj.(runtime.Object)) {
+				keys = append(keys, ns+"/"+name)
+			}
+			return keys, nil
+		},
+	})
+
+	for i := 0; i < workloads; i++ {
+		secrets := []string{fmt.Sprintf("secret-%d", i)}
+		if i < consumers {
+			secrets = append(secrets, "shared")
+		}
+		if err := indexer.Add(toUnstructured(deploymentUsingSecrets("bench", fmt.Sprintf("deploy-%d", i), secrets...))); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	return indexer
+}
+
+// BenchmarkListAndScan finds the consumers of a secret by listing every workload in the
+// namespace and scanning each pod template.
+func BenchmarkListAndScan(b *testing.B) {
+	indexer := benchmarkIndexer(b, 1000, 5)
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "bench"}}
+	adapter := adapterFor("Deployment")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		objs, err := indexer.ByIndex(cache.NamespaceIndex, secret.GetNamespace())
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		found := 0
+		for _, obj := range objs {
+			// the cache returns copies of listed objects.
+			workload := obj.(*unstructured.Unstructured).DeepCopy()
+			if template, _, _ := adapter.PodTemplate(workload); usesSecret(secret, template.Spec) {
+				found++
+			}
+		}
+		if found != 5 {
+			b.Fatalf("expected 5 consumers, found %d", found)
+		}
+	}
+}
+
+// BenchmarkIndexedLookup finds the consumers of a secret using the secretReferenceIndex.
+func BenchmarkIndexedLookup(b *testing.B) {
+	indexer := benchmarkIndexer(b, 1000, 5)
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "bench"}}
+	adapter := adapterFor("Deployment")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		objs, err := indexer.ByIndex(secretReferenceIndex, secret.GetNamespace()+"/"+secret.GetName())
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		found := 0
+		for _, obj := range objs {
+			workload := obj.(*unstructured.Unstructured).DeepCopy()
+			if template, _, _ := adapter.PodTemplate(workload); usesSecret(secret, template.Spec) {
+				found++
+			}
+		}
+		if found != 5 {
+			b.Fatalf("expected 5 consumers, found %d", found)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package podrefresher
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// auditedRefreshes counts the workloads that would have been refreshed in audit mode.
+	auditedRefreshes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "podrefresher_audited_refreshes_total",
+			Help: "Number of workload refreshes reported but not made in audit mode by namespace and kind.",
+		},
+		[]string{"namespace", "kind"},
+	)
+
+	// restartsTriggered counts the workloads restarted by the refresher.
+	restartsTriggered = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "podrefresher_restarts_total",
+			Help: "Number of workload restarts triggered by namespace and kind.",
+		},
+		[]string{"namespace", "kind"},
+	)
+
+	// restartFailures counts the attempts to restart workloads that failed.
+	restartFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "podrefresher_restart_failures_total",
+			Help: "Number of failed workload restart attempts by namespace and kind.",
+		},
+		[]string{"namespace", "kind"},
+	)
+
+	// refreshDelay tracks the time from a secret being updated until the rollout of a workload
+	// restarted for it completes.
+	refreshDelay = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "podrefresher_refresh_delay_seconds",
+			Help:    "Time from a secret being updated until the rollout of a workload restarted for it completed, by namespace and kind.",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 16),
+		},
+		[]string{"namespace", "kind"},
+	)
+
+	// optedInWorkloads tracks the number of workloads using each secret that have opted in to restarts.
+	optedInWorkloads = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "podrefresher_opted_in_workloads",
+			Help: "Number of workloads using a secret that have opted in to restarts, by namespace and secret.",
+		},
+		[]string{"namespace", "secret"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(auditedRefreshes, restartsTriggered, restartFailures, refreshDelay, optedInWorkloads)
+}
+
+// secretUpdateTime returns when the secret was last updated, from the latest of the times its fields
+// were managed, or its creation time if none are recorded.
+func secretUpdateTime(secret metav1.Object) time.Time {
+	updated := secret.GetCreationTimestamp().Time
+	for _, entry := range secret.GetManagedFields() {
+		if entry.Time != nil && entry.Time.After(updated) {
+			updated = entry.Time.Time
+		}
+	}
+
+	return updated
+}
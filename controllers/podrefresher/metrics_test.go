@@ -0,0 +1,34 @@
+package podrefresher
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Metrics", func() {
+	Context("When determining when a secret was updated", func() {
+		created := time.Date(2020, 11, 2, 0, 0, 0, 0, time.UTC)
+
+		It("Should use the latest managed fields time", func() {
+			first, last := metav1.NewTime(created.Add(time.Minute)), metav1.NewTime(created.Add(time.Hour))
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				CreationTimestamp: metav1.NewTime(created),
+				ManagedFields: []metav1.ManagedFieldsEntry{
+					{Manager: "cert-manager", Time: &last},
+					{Manager: "kubectl", Time: &first},
+					{Manager: "unknown"},
+				},
+			}}
+			Expect(secretUpdateTime(secret)).To(Equal(last.Time))
+		})
+
+		It("Should fall back to the creation time", func() {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)}}
+			Expect(secretUpdateTime(secret)).To(Equal(created))
+		})
+	})
+})
@@ -0,0 +1,171 @@
+package podrefresher
+
+import (
+	"context"
+	"fmt"
+
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// optInKeys are the annotation keys that opt workloads and namespaces in to restarts, in order
+// of precedence.
+type optInKeys []string
+
+// newOptInKeys returns the optInKeys for the annotation, which is checked before the
+// allowRestartAnnotation if it is set. The allowRestartAnnotation is always checked, so
+// that objects that opted in before an annotation was configured are still refreshed.
+func newOptInKeys(annotation string) optInKeys {
+	if annotation == "" || annotation == allowRestartAnnotation {
+		return optInKeys{allowRestartAnnotation}
+	}
+
+	return optInKeys{annotation, allowRestartAnnotation}
+}
+
+// find returns the first of the keys in values, along with its value, and false if none of the
+// keys are in values.
+func (k optInKeys) find(values map[string]string) (string, string, bool) {
+	for _, key := range k {
+		if val, ok := values[key]; ok {
+			return key, val, true
+		}
+	}
+
+	return "", "", false
+}
+
+// optedIn returns true if the workload has opted in to restarts, along with the reason for the
+// decision. The allow-restart annotation on the workload takes precedence, so that a workload
+// can opt out by setting it to any value other than true. Otherwise, the workload is opted in
+// if it is selected by a policy, or if its namespace has the allow-restart annotation or label
+// set to true.
+func (k optInKeys) optedIn(workload metav1.Object, policy *operatorsv1alpha1.PodRefreshPolicy, namespace metav1.Object) (bool, string) {
+	if key, val, ok := k.find(workload.GetAnnotations()); ok {
+		if val == "true" {
+			return true, fmt.Sprintf("the object is annotated with %s=true", key)
+		}
+		return false, fmt.Sprintf("the object is annotated with %s=%s", key, val)
+	}
+
+	if policy != nil {
+		return true, fmt.Sprintf("the object is selected by PodRefreshPolicy %s", policy.GetName())
+	}
+
+	if key, ok := k.annotated(namespace); ok {
+		return true, fmt.Sprintf("namespace %s is annotated with %s=true", namespace.GetName(), key)
+	}
+
+	if key, ok := k.labeled(namespace); ok {
+		return true, fmt.Sprintf("namespace %s is labeled with %s=true", namespace.GetName(), key)
+	}
+
+	return false, "neither the object nor its namespace has opted in to restarts"
+}
+
+// annotated returns true if the object meta has opted into restarts via inclusion of an
+// allow-restart annotation, along with its key. It returns false if the first of the keys
+// the object is annotated with isn't set to true, or if none of them are set at all.
+func (k optInKeys) annotated(metadata metav1.Object) (string, bool) {
+	key, val, ok := k.find(metadata.GetAnnotations())
+	return key, ok && val == "true"
+}
+
+// labeled returns true if the object meta has opted into restarts via a label with the
+// same key and value as an allow-restart annotation, along with its key. Namespaces can
+// use either, so that namespaces can be opted in with label-based tooling.
+func (k optInKeys) labeled(metadata metav1.Object) (string, bool) {
+	key, val, ok := k.find(metadata.GetLabels())
+	return key, ok && val == "true"
+}
+
+// namespaceOptedIn returns true if the namespace has opted in to restarts with either an
+// allow-restart annotation or label.
+func (k optInKeys) namespaceOptedIn(namespace metav1.Object) bool {
+	_, annotated := k.annotated(namespace)
+	_, labeled := k.labeled(namespace)
+	return annotated || labeled
+}
+
+// secretsInNamespace maps a namespace to reconcile requests for every secret in it that is a refresh
+// source, so that workloads in the namespace are checked when it opts in to restarts.
+func (r *PodRefreshReconciler) secretsInNamespace(o handler.MapObject) []reconcile.Request {
+	secrets := corev1.SecretList{}
+	if err := r.List(context.TODO(), &secrets, client.InNamespace(o.Meta.GetName())); err != nil {
+		r.Log.Error(err, "Unable to list secrets", "Namespace", o.Meta.GetName())
+		return nil
+	}
+
+	objs := make([]metav1.Object, 0, len(secrets.Items))
+	for i := range secrets.Items {
+		objs = append(objs, &secrets.Items[i])
+	}
+
+	return r.sourcesInNamespace(o.Meta.GetName(), sourceKindSecret, objs)
+}
+
+// configMapsInNamespace maps a namespace to reconcile requests for every ConfigMap in it that is a
+// refresh source, so that workloads in the namespace are checked when it opts in to restarts.
+func (r *PodRefreshReconciler) configMapsInNamespace(o handler.MapObject) []reconcile.Request {
+	configMaps := corev1.ConfigMapList{}
+	if err := r.List(context.TODO(), &configMaps, client.InNamespace(o.Meta.GetName())); err != nil {
+		r.Log.Error(err, "Unable to list ConfigMaps", "Namespace", o.Meta.GetName())
+		return nil
+	}
+
+	objs := make([]metav1.Object, 0, len(configMaps.Items))
+	for i := range configMaps.Items {
+		objs = append(objs, &configMaps.Items[i])
+	}
+
+	return r.sourcesInNamespace(o.Meta.GetName(), sourceKindConfigMap, objs)
+}
+
+// sourcesInNamespace returns reconcile requests for the objs of the kind in namespace that are refresh sources.
+func (r *PodRefreshReconciler) sourcesInNamespace(namespace, kind string, objs []metav1.Object) []reconcile.Request {
+	policies := operatorsv1alpha1.PodRefreshPolicyList{}
+	if err := r.List(context.TODO(), &policies, client.InNamespace(namespace)); err != nil {
+		r.Log.Error(err, "Unable to list PodRefreshPolicies", "Namespace", namespace)
+		return nil
+	}
+
+	var reqs []reconcile.Request
+	for _, obj := range objs {
+		if !isRefreshSource(kind, obj, policies.Items) {
+			continue
+		}
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}})
+	}
+
+	return reqs
+}
+
+// namespaceOptedInPredicate implements a predicate passing namespace update events where the
+// namespace has opted in to restarts, and had not opted in before the update.
+type namespaceOptedInPredicate struct {
+	keys optInKeys
+}
+
+// Update implements UpdateEvent filter for validating that the namespace has just
+// opted in to restarts.
+func (p namespaceOptedInPredicate) Update(e event.UpdateEvent) bool {
+	return !p.keys.namespaceOptedIn(e.MetaOld) && p.keys.namespaceOptedIn(e.MetaNew)
+}
+
+func (namespaceOptedInPredicate) Create(e event.CreateEvent) bool {
+	return false
+}
+
+func (namespaceOptedInPredicate) Delete(e event.DeleteEvent) bool {
+	return false
+}
+
+func (namespaceOptedInPredicate) Generic(e event.GenericEvent) bool {
+	return false
+}
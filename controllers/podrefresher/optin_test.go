@@ -0,0 +1,161 @@
+package podrefresher
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Namespace opt-in", func() {
+	var (
+		annotated = &metav1.ObjectMeta{Name: "annotated", Annotations: map[string]string{allowRestartAnnotation: "true"}}
+		labeled   = &metav1.ObjectMeta{Name: "labeled", Labels: map[string]string{allowRestartAnnotation: "true"}}
+		disabled  = &metav1.ObjectMeta{Name: "disabled", Labels: map[string]string{allowRestartAnnotation: "false"}}
+		plain     = &metav1.ObjectMeta{Name: "plain"}
+		keys      = newOptInKeys("")
+	)
+
+	Context("When the namespace has opted in", func() {
+		It("Should opt in workloads in a namespace with the allow-restart annotation", func() {
+			allowed, reason := keys.optedIn(&metav1.ObjectMeta{}, nil, annotated)
+			Expect(allowed).To(BeTrue())
+			Expect(reason).To(Equal("namespace annotated is annotated with " + allowRestartAnnotation + "=true"))
+		})
+
+		It("Should opt in workloads in a namespace with the allow-restart label", func() {
+			allowed, reason := keys.optedIn(&metav1.ObjectMeta{}, nil, labeled)
+			Expect(allowed).To(BeTrue())
+			Expect(reason).To(Equal("namespace labeled is labeled with " + allowRestartAnnotation + "=true"))
+		})
+
+		It("Should not opt in workloads that opt out", func() {
+			allowed, reason := keys.optedIn(&metav1.ObjectMeta{Annotations: map[string]string{allowRestartAnnotation: "false"}}, nil, labeled)
+			Expect(allowed).To(BeFalse())
+			Expect(reason).To(Equal("the object is annotated with " + allowRestartAnnotation + "=false"))
+		})
+	})
+
+	Context("When the namespace has not opted in", func() {
+		It("Should not opt in workloads", func() {
+			for _, namespace := range []*metav1.ObjectMeta{disabled, plain} {
+				allowed, _ := keys.optedIn(&metav1.ObjectMeta{}, nil, namespace)
+				Expect(allowed).To(BeFalse())
+			}
+		})
+
+		It("Should still opt in annotated workloads", func() {
+			allowed, reason := keys.optedIn(&metav1.ObjectMeta{Annotations: map[string]string{allowRestartAnnotation: "true"}}, nil, plain)
+			Expect(allowed).To(BeTrue())
+			Expect(reason).To(Equal("the object is annotated with " + allowRestartAnnotation + "=true"))
+		})
+	})
+
+	Context("When a namespace is updated", func() {
+		It("Should only pass updates that opt the namespace in", func() {
+			Expect(namespaceOptedInPredicate{keys: keys}.Update(event.UpdateEvent{MetaOld: plain, MetaNew: labeled})).To(BeTrue())
+			Expect(namespaceOptedInPredicate{keys: keys}.Update(event.UpdateEvent{MetaOld: labeled, MetaNew: annotated})).To(BeFalse())
+			Expect(namespaceOptedInPredicate{keys: keys}.Update(event.UpdateEvent{MetaOld: annotated, MetaNew: plain})).To(BeFalse())
+		})
+	})
+
+	Context("When an opt-in annotation is configured", func() {
+		custom := "example.com/refresh"
+		keys := newOptInKeys(custom)
+
+		It("Should opt in objects with either annotation", func() {
+			allowed, reason := keys.optedIn(&metav1.ObjectMeta{Annotations: map[string]string{custom: "true"}}, nil, plain)
+			Expect(allowed).To(BeTrue())
+			Expect(reason).To(Equal("the object is annotated with " + custom + "=true"))
+
+			allowed, _ = keys.optedIn(&metav1.ObjectMeta{}, nil, annotated)
+			Expect(allowed).To(BeTrue())
+
+			allowed, reason = keys.optedIn(&metav1.ObjectMeta{}, nil, &metav1.ObjectMeta{Name: "custom", Labels: map[string]string{custom: "true"}})
+			Expect(allowed).To(BeTrue())
+			Expect(reason).To(Equal("namespace custom is labeled with " + custom + "=true"))
+		})
+
+		It("Should prefer the configured annotation", func() {
+			workload := &metav1.ObjectMeta{Annotations: map[string]string{custom: "false", allowRestartAnnotation: "true"}}
+			allowed, reason := keys.optedIn(workload, nil, plain)
+			Expect(allowed).To(BeFalse())
+			Expect(reason).To(Equal("the object is annotated with " + custom + "=false"))
+		})
+
+		It("Should only check the default annotation once", func() {
+			Expect(newOptInKeys(allowRestartAnnotation)).To(Equal(optInKeys{allowRestartAnnotation}))
+		})
+	})
+
+	Context("When a workload opts in", func() {
+		var (
+			c         client.Client
+			r         *PodRefreshReconciler
+			adapter   = adapterFor("Deployment")
+			namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}}
+			key       = types.NamespacedName{Namespace: "ns", Name: "web"}
+			secretKey = types.NamespacedName{Namespace: "ns", Name: "tls"}
+		)
+
+		getWorkload := func() *unstructured.Unstructured {
+			workload := newWorkload(adapter)
+			Expect(c.Get(context.TODO(), key, workload)).To(Succeed())
+			return workload
+		}
+
+		getSecret := func() *corev1.Secret {
+			secret := &corev1.Secret{}
+			Expect(c.Get(context.TODO(), secretKey, secret)).To(Succeed())
+			return secret
+		}
+
+		restarted := func() bool {
+			_, found, err := unstructured.NestedString(getWorkload().Object, "spec", "template", "metadata", "labels", timeRestartedLabel)
+			Expect(err).ToNot(HaveOccurred())
+			return found
+		}
+
+		BeforeEach(func() {
+			deploy := deploymentUsingSecrets("ns", "web", "tls")
+			deploy.SetAnnotations(map[string]string{allowRestartAnnotation: "true"})
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "tls"},
+				Data:       map[string][]byte{corev1.TLSCertKey: []byte("first")},
+			}
+
+			c = fake.NewFakeClientWithScheme(scheme.Scheme, deploy, secret, namespace)
+			r = &PodRefreshReconciler{Client: c, Log: logf.Log, EventRecorder: record.NewFakeRecorder(10), apiReader: c, scheduler: newRestartScheduler(0, 0)}
+		})
+
+		It("Should record the secrets it uses without restarting it", func() {
+			Expect(r.refreshWorkload(adapter, secretSource(getSecret()), getWorkload(), nil, namespace)).To(BeZero())
+			Expect(restarted()).To(BeFalse())
+			Expect(sourceRecorded("tls", getWorkload().GetAnnotations())).To(BeTrue())
+
+			Expect(r.refreshWorkload(adapter, secretSource(getSecret()), getWorkload(), nil, namespace)).To(BeZero())
+			Expect(restarted()).To(BeFalse())
+		})
+
+		It("Should restart it once a recorded secret changes", func() {
+			Expect(r.refreshWorkload(adapter, secretSource(getSecret()), getWorkload(), nil, namespace)).To(BeZero())
+
+			secret := getSecret()
+			secret.Data[corev1.TLSCertKey] = []byte("second")
+			Expect(c.Update(context.TODO(), secret)).To(Succeed())
+
+			Expect(r.refreshWorkload(adapter, secretSource(getSecret()), getWorkload(), nil, namespace)).To(BeZero())
+			Expect(restarted()).To(BeTrue())
+		})
+	})
+})
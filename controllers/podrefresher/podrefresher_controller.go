@@ -18,20 +18,30 @@ package podrefresher
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
-	appsv1 "k8s.io/api/apps/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"encoding/json"
 
 	"github.com/go-logr/logr"
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -47,8 +57,17 @@ const (
 
 var (
 	// Eventing helpers
-	refresh        = podRefresherEvent{reason: "PodRefresh", message: "Associated pods restarted as a cert-manager secret used by the object has changed."}
+	refresh        = podRefresherEvent{reason: "PodRefresh", message: "Associated pods restarted as a certificate used by the object has changed."}
 	refreshFailure = podRefresherEvent{reason: "PodRefreshFailure", message: "Unable to restart pods associated with object due to an API error."}
+	refreshPending = podRefresherEvent{reason: "PodRefreshPending", message: "A certificate used by the object has changed. Associated pods will be restarted at"}
+	reload         = podRefresherEvent{reason: "PodReload", message: "Associated pods reloaded as a certificate used by the object has changed."}
+	reloadFailure  = podRefresherEvent{reason: "PodReloadFailure", message: "Unable to reload pods associated with object, restarting them instead:"}
+	refreshAudit   = podRefresherEvent{reason: "PodRefreshAudit", message: "A certificate used by the object has changed. Associated pods would be restarted, but restarts are only audited."}
+	refreshBlocked = podRefresherEvent{reason: "PodRefreshBlocked", message: "Unable to restart pods associated with object for a changed certificate:"}
+	refreshIgnored = podRefresherEvent{reason: "PodRefreshIgnored", message: "A secret used by the object has changed, but the object only restarts on changes to keys the secret does not have."}
+
+	certificateExpiring = podRefresherEvent{reason: "CertificateExpiring", message: "A certificate used by the object is close to expiring and has not been renewed."}
+	certificateExpired  = podRefresherEvent{reason: "CertificateExpired", message: "A certificate used by the object has expired and has not been renewed."}
 )
 
 // PodRefreshReconciler reconciles a Secret object
@@ -57,6 +76,51 @@ type PodRefreshReconciler struct {
 	Log    logr.Logger
 	Scheme *runtime.Scheme
 	record.EventRecorder
+	// ExtraWorkloads are adapters for workload kinds refreshed in addition to the defaults.
+	ExtraWorkloads []WorkloadAdapter
+	// FingerprintKeys are the secret keys whose contents must change for a workload to be
+	// restarted. Defaults to DefaultFingerprintKeys.
+	FingerprintKeys []string
+	// RestartDebounce is how long to wait after a secret changes before restarting the workloads using it,
+	// unless set by the workload or its namespace. 0 restarts workloads immediately.
+	RestartDebounce time.Duration
+	// MaxConcurrentRestarts is the maximum number of workload rollouts started by the refresher
+	// that can be in progress across the cluster. 0 is unlimited.
+	MaxConcurrentRestarts int
+	// MaxConcurrentRestartsPerNamespace is the maximum number of workload rollouts started by the
+	// refresher that can be in progress in a namespace. 0 is unlimited.
+	MaxConcurrentRestartsPerNamespace int
+	// WatchConfigMaps refreshes workloads when ConfigMaps they use that are refresh sources, such as
+	// CA trust bundles, change. The manager caches every ConfigMap in the cluster if enabled.
+	WatchConfigMaps bool
+	// Audit reports the workloads that would be restarted with events and metrics without
+	// restarting them, unless set by the workload, its policy, or its namespace.
+	Audit bool
+	// AllowRestartAnnotation is an annotation that opts workloads and namespaces in to restarts
+	// when set to true, checked before the default allow-restart annotation.
+	AllowRestartAnnotation string
+	// RefreshStrategy is how workloads are refreshed, one of Restart, HTTPReload or PodAnnotation,
+	// unless set by the workload, its policy, or its namespace. Defaults to Restart.
+	RefreshStrategy string
+	// CertificateMetrics exports the validity, issuer and DNS names of the certificates in cert-manager
+	// issued secrets as metrics.
+	CertificateMetrics bool
+	// ExpiryWarningWindow is how long before the certificate in a secret expires that the workloads
+	// using it are warned with events. Each workload is warned once that the certificate is expiring,
+	// and once that it has expired. 0 disables the warnings.
+	ExpiryWarningWindow time.Duration
+
+	workloads      *workloadRegistry
+	workloadReader client.Reader
+	// apiReader reads refresh history ConfigMaps and the pods of workloads from the API server, as the
+	// manager's client would cache every ConfigMap and pod in the cluster.
+	apiReader client.Reader
+	retries   *retryTracker
+	scheduler *restartScheduler
+	// expiryWarnings are the workloads warned about the certificates in each secret.
+	expiryWarnings *expiryWarnings
+	// httpClient sends reload requests to pods.
+	httpClient *http.Client
 }
 
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
@@ -64,9 +128,18 @@ type PodRefreshReconciler struct {
 // +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=list;update;watch;
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=list;update;watch;
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=list;update;watch;
-
-// Reconcile watches for secrets and if a secret is a certmanager secret, it checks for deployments, statefulsets,
-// and daemonsets that may be using the secret and triggers a re-rollout of those objects.
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=list;watch;
+// +kubebuilder:rbac:groups=argoproj.io,resources=rollouts,verbs=list;update;watch;
+// +kubebuilder:rbac:groups=apps.openshift.io,resources=deploymentconfigs,verbs=list;update;watch;
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=list;watch;
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=list;patch;
+// +kubebuilder:rbac:groups=operators.redhat.io,resources=podrefreshpolicies,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=operators.redhat.io,resources=podrefreshpolicies/status,verbs=get;update;patch
+
+// Reconcile watches for secrets and if a secret is a certmanager secret, it checks for workloads that may be
+// using the secret and triggers a re-rollout of those objects.
 func (r *PodRefreshReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	_ = context.Background()
 	_ = r.Log.WithValues("secret", req.NamespacedName)
@@ -81,142 +154,488 @@ func (r *PodRefreshReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 			// Request object not found, could have been deleted after reconcile req.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			optedInWorkloads.DeleteLabelValues(req.Namespace, req.Name)
+			r.expiryWarnings.forget(req.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the req.
 		return reconcile.Result{}, err
 	}
 
-	// If secret doesn't have cert-manager annotations, stop reconciliing it. This is the failsafe to prevent
-	// a bounce on a resource that is not a cert-manager-related secret.
-	// DEPRECATED(2020-10-23): This exists as a safety precuation, but predicates should be filtering these out. Remove in the future.
-	ants := secret.GetAnnotations()
-	if _, ok := ants[issuerKindAnnotation]; !ok {
-		r.Log.Info("Secret is not a cert-manager issued certificate. Disregarding.", "Secret.Name", secret.GetName(), "Secret.Namespace", secret.GetNamespace())
+	// If secret isn't issued by cert-manager or selected as a refresh source, stop reconciling it. This is the
+	// failsafe to prevent a bounce on a resource that is not a certificate. Secret events are filtered by predicates,
+	// but requests mapped from workload events may name any secret the workload references.
+	selected, err := sourceSelected(r.Client, sourceKindSecret, secret)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !selected {
+		r.Log.Info("Secret is not a cert-manager issued certificate or a refresh source. Disregarding.", "Secret.Name", secret.GetName(), "Secret.Namespace", secret.GetNamespace())
 		return reconcile.Result{}, nil
 	}
-	r.Log.Info("Secret is a cert-manager issued certificate. Checking deployments/statefulsets/daemonsets using Secret.", "Secret.Name", secret.GetName(), "Secret.Namespace", secret.GetNamespace())
+	r.Log.Info("Secret is a refresh source. Checking workloads using Secret.", "Secret.Name", secret.GetName(), "Secret.Namespace", secret.GetNamespace())
 
-	// If Secret has been updated, try to find deployments in the same namespace that needs to be bounced.
-	r.Log.V(2).Info("Looking for deployments in namespace using certificate", "Secret.Name", secret.GetName(), "Secret.Namespace", secret.GetNamespace())
-	deployList := appsv1.DeploymentList{}
-	err = r.List(context.TODO(), &deployList, &client.ListOptions{Namespace: secret.GetNamespace()})
-	if err != nil {
-		r.Log.Error(err, "Error listing deployments", "req.Namespace", secret.GetNamespace())
-		return reconcile.Result{}, err
+	return r.refreshConsumers(secretSource(secret))
+}
+
+// refreshConsumers refreshes the workloads using the secret or ConfigMap src.
+func (r *PodRefreshReconciler) refreshConsumers(src refreshSource) (ctrl.Result, error) {
+	// Workloads that failed to refresh are retried with their own backoff. The request is requeued
+	// for the earliest retry, and workloads that were refreshed are not restarted again because their
+	// secretResourceVersionAnnotation is up to date.
+	refreshErrors := make([]refreshErrorData, 0)
+	var requeueAfter time.Duration
+	requeueAt := func(delay time.Duration) {
+		if requeueAfter == 0 || delay < requeueAfter {
+			requeueAfter = delay
+		}
 	}
 
-	// If Secret has been updated, try to find daemonsets in the same namespace that needs to be bounced.
-	r.Log.V(2).Info("Looking for daemonsets in namespace using certificate", "Secret.Name", secret.GetName(), "Secret.Namespace", secret.GetNamespace())
-	dsetList := appsv1.DaemonSetList{}
-	err = r.List(context.TODO(), &dsetList, &client.ListOptions{Namespace: secret.GetNamespace()})
-	if err != nil {
-		r.Log.Error(err, "Error listing daemonsets", "req.Namespace", secret.GetNamespace())
-		return reconcile.Result{}, err
+	// stop counting completed rollouts against the restart budgets before starting new ones.
+	for _, finished := range r.scheduler.release(r.workloadReader, time.Now(), r.Log) {
+		if finished.result == refreshCompleted {
+			refreshDelay.WithLabelValues(finished.namespace, finished.adapter.GroupVersionKind().Kind).Observe(time.Since(finished.changed).Seconds())
+		}
+		for _, secretName := range finished.secrets {
+			r.recordRefresh(finished.namespace, secretName, refreshRecord{
+				Kind:   finished.adapter.GroupVersionKind().Kind,
+				Name:   finished.name,
+				Time:   metav1.Now(),
+				Result: finished.result,
+			})
+		}
 	}
 
-	// If Secret has been updated, try to find statefulsets in the same namespace that needs to be bounced.
-	r.Log.V(2).Info("Looking for statefulset in namespace using certificate", "Secret.Name", secret.GetName(), "Secret.Namespace", secret.GetNamespace())
-	stsList := appsv1.StatefulSetList{}
-	err = r.List(context.TODO(), &stsList, &client.ListOptions{Namespace: secret.GetNamespace()})
-	if err != nil {
-		r.Log.Error(err, "Error listing statefulsets", "req.Namespace", secret.GetNamespace())
-		return reconcile.Result{}, err
+	// retries are tracked against the contents of the source, so that changes to its metadata
+	// don't reset the backoff of failed restarts.
+	fingerprint := src.fingerprint(r.fingerprintKeys())
+	optedInCount := 0
+
+	// every workload using a certificate close to expiring is warned, whether or not it has opted in to
+	// restarts, as application teams watch their workloads rather than cert-manager resources.
+	expiry := checkExpiry(src, r.ExpiryWarningWindow, time.Now())
+	if expiry.recheckAfter > 0 {
+		requeueAt(expiry.recheckAfter)
+	}
+	if expiry.warning == nil && src.secret != nil {
+		r.expiryWarnings.forget(types.NamespacedName{Namespace: src.GetNamespace(), Name: src.GetName()})
 	}
 
-	// Since we are not sending a requeue if a refresh fails, we log it instead.
-	refreshErrors := make([]refreshErrorData, 0)
-	var updateFailed bool
-
-	// Check deployments in the relevant namespace
-	for _, deploy := range deployList.Items {
-		r.Log.Info("Checking deployment for usage of certificate found in secret", "Secret", secret.GetName(), "Deployment", deploy.GetName(), "Namespace", secret.GetNamespace()) //debug make higher verbosity level
-		updatedAt := time.Now().Format("2006-1-2.1504")
-		if hasAllowRestartAnnotation(deploy.ObjectMeta) && usesSecret(secret, deploy.Spec.Template.Spec) && outdatedSecretInUse(secret.GetName(), secret.GetResourceVersion(), deploy.GetObjectMeta().GetAnnotations()) {
-			r.Event(&deploy, corev1.EventTypeNormal, refresh.reason, refresh.message)
-			r.Log.Info("Deployment makes use of secret and has opted-in", "Secret", secret.GetName(), "Deployment", deploy.GetName(), "Namespace", secret.GetNamespace())
-			updatedDeploy := deploy.DeepCopy()
-			updatedDeploy.ObjectMeta.Labels[timeRestartedLabel] = updatedAt
-			updatedDeploy.Spec.Template.ObjectMeta.Labels[timeRestartedLabel] = updatedAt
-			updateSecretRevisionAnnotation(secret.GetName(), secret.GetResourceVersion(), updatedDeploy.GetAnnotations())
-			r.Log.Info("Initiating refresh", "Secret", secret.GetName(), "Deployment", deploy.GetName(), "Namespace", secret.GetNamespace())
-			err := r.Update(context.TODO(), updatedDeploy)
+	for _, adapter := range r.workloads.adapters {
+		kind := adapter.GroupVersionKind().Kind
+
+		// Workloads are listed from the reference index of the source's kind, so only workloads referencing the source are returned.
+		r.Log.V(2).Info("Looking for workloads in namespace using certificate", "Kind", kind, src.kind+".Name", src.GetName(), src.kind+".Namespace", src.GetNamespace())
+		workloads := newWorkloadList(adapter)
+		err := r.workloadReader.List(context.TODO(), workloads, client.InNamespace(src.GetNamespace()), client.MatchingFields{src.referenceIndex(): src.GetName()})
+		if err != nil {
+			r.Log.Error(err, "Error listing workloads", "Kind", kind, "req.Namespace", src.GetNamespace())
+			return reconcile.Result{}, err
+		}
+
+		for i := range workloads.Items {
+			workload := &workloads.Items[i]
+			policy, namespace, err := r.workloadSettings(workload, src)
+			if err != nil {
+				r.Log.Error(err, "Error reading workload settings", "Kind", kind, "Name", workload.GetName(), "Namespace", workload.GetNamespace())
+				return reconcile.Result{}, err
+			}
+			if allowed, _ := r.optInKeys().optedIn(workload, policy, namespace); allowed {
+				optedInCount++
+			}
+
+			if expiry.warning != nil && r.expiryWarnings.warn(types.NamespacedName{Namespace: src.GetNamespace(), Name: src.GetName()}, expiry.notAfter, expiry.warning.reason, kind+"/"+workload.GetName()) {
+				r.Eventf(workload, corev1.EventTypeWarning, expiry.warning.reason, "%s Secret %s is valid until %s.", expiry.warning.message, src.GetName(), expiry.notAfter.UTC().Format(time.RFC3339))
+			}
+
+			key := retryKey(kind, workload.GetNamespace(), workload.GetName(), src.key())
+			if ready, wait := r.retries.ready(key, fingerprint, time.Now()); !ready {
+				r.Log.V(2).Info("Workload refresh is backing off", "Kind", kind, "Name", workload.GetName(), "RetryIn", wait.String())
+				requeueAt(wait)
+				continue
+			}
+
+			deferred, err := r.refreshWorkload(adapter, src, workload, policy, namespace)
 			if err != nil {
-				r.Event(&deploy, corev1.EventTypeWarning, refreshFailure.reason, refreshFailure.message)
-				r.Log.Error(err, "Unable to restart deployment.", "Deployment.Name", deploy.GetName())
-				refreshErrors = append(refreshErrors, refreshErrorData{kind: deploy.Kind, name: deploy.GetName(), namespace: deploy.GetNamespace(), errorMsg: err.Error()})
-				updateFailed = true
+				delay := r.retries.failed(key, fingerprint, time.Now())
+				restartFailures.WithLabelValues(workload.GetNamespace(), kind).Inc()
+				if _, blocked := err.(*blockedByBudgetError); blocked {
+					r.Eventf(workload, corev1.EventTypeWarning, refreshBlocked.reason, "%s %s.", refreshBlocked.message, err)
+				} else {
+					r.Event(workload, corev1.EventTypeWarning, refreshFailure.reason, refreshFailure.message)
+				}
+				r.Log.Error(err, "Unable to restart workload.", "Kind", kind, "Name", workload.GetName(), "RetryIn", delay.String())
+				refreshErrors = append(refreshErrors, refreshErrorData{kind: kind, name: workload.GetName(), namespace: workload.GetNamespace(), errorMsg: err.Error()})
+				r.recordRefresh(src.GetNamespace(), src.key(), refreshRecord{Kind: kind, Name: workload.GetName(), Time: metav1.Now(), Result: refreshFailed, Reason: err.Error()})
+				requeueAt(delay)
+				continue
+			}
+
+			r.retries.succeeded(key)
+			if deferred > 0 {
+				requeueAt(deferred)
 			}
 		}
 	}
 
-	// Check daemonsets in the relevant namespace
-	for _, dset := range dsetList.Items {
-		r.Log.Info("Checking Daemonset for usage of certificate found in secret", "Secret", secret.GetName(), "Daemonset", dset.GetName(), "Namespace", secret.GetNamespace()) //debug make higher verbosity level
-		updatedAt := time.Now().Format("2006-1-2.1504")
-		if hasAllowRestartAnnotation(dset.ObjectMeta) && usesSecret(secret, dset.Spec.Template.Spec) && outdatedSecretInUse(secret.GetName(), secret.GetResourceVersion(), dset.GetObjectMeta().GetAnnotations()) {
-			r.Log.Info("Daemonset makes use of secret and has opted-in", "Secret", secret.GetName(), "Daemonset", dset.GetName(), "Namespace", secret.GetNamespace())
-			updatedDset := dset.DeepCopy()
-			updatedDset.ObjectMeta.Labels[timeRestartedLabel] = updatedAt
-			updatedDset.Spec.Template.ObjectMeta.Labels[timeRestartedLabel] = updatedAt
-			updateSecretRevisionAnnotation(secret.GetName(), secret.GetResourceVersion(), updatedDset.GetAnnotations())
-			r.Log.Info("Initiating refresh", "Secret", secret.GetName(), "Daemonset", dset.GetName(), "Namespace", secret.GetNamespace())
-			err := r.Update(context.TODO(), updatedDset)
-			if err != nil {
-				r.Log.Error(err, "Unable to restart Daemonset.", "Daemonset.Name", dset.GetName())
-				r.Event(&dset, corev1.EventTypeWarning, refreshFailure.reason, refreshFailure.message)
-				refreshErrors = append(refreshErrors, refreshErrorData{kind: dset.Kind, name: dset.GetName(), namespace: dset.GetNamespace(), errorMsg: err.Error()})
-				updateFailed = true
+	optedInWorkloads.WithLabelValues(src.GetNamespace(), src.key()).Set(float64(optedInCount))
+
+	// rollouts started for the source are polled until they complete, so that their completion
+	// is recorded and they stop counting against the restart budgets.
+	if r.scheduler.inProgressFor(src.GetNamespace(), src.key()) {
+		requeueAt(restartPollInterval)
+	}
+
+	if len(refreshErrors) > 0 {
+		r.Log.Info("Resource(s) that opted-in to refreshes have failed to refresh and will be retried",
+			src.kind+".Name", src.GetName(),
+			src.kind+".Namespace", src.GetNamespace(),
+			"RequeueAfter", requeueAfter.String(),
+			"Error Message", refreshErrors)
+	}
+
+	r.Log.Info("Done Reconciling CertManager TLS Certificates")
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// refreshWorkload restarts the workload if it has opted in to restarts, uses the secret or ConfigMap src,
+// and has not already been restarted for the current contents of src. A restart is pending until the
+// workload's debounce has passed and its restart window is open. A restart that would exceed
+// the restart budgets, or disrupt pods protected by a PodDisruptionBudget, is deferred and the
+// delay before it should be attempted again is returned. Workloads with an in-place reload strategy have
+// their pods reloaded instead, and are restarted only if reloading fails. In audit mode, the restart is
+// only reported.
+func (r *PodRefreshReconciler) refreshWorkload(adapter WorkloadAdapter, src refreshSource, workload *unstructured.Unstructured, policy *operatorsv1alpha1.PodRefreshPolicy, namespace *corev1.Namespace) (time.Duration, error) {
+	kind := adapter.GroupVersionKind().Kind
+	r.Log.V(1).Info("Checking workload for usage of certificate found in source", src.kind, src.GetName(), "Kind", kind, "Name", workload.GetName(), "Namespace", src.GetNamespace())
+
+	template, found, err := adapter.PodTemplate(workload)
+	if err != nil || !found {
+		return 0, err
+	}
+
+	// settings annotated on the workload take precedence over the policy selecting it,
+	// which takes precedence over settings annotated on the namespace.
+	settings := []metav1.Object{workload, policyAnnotations(policy), namespace}
+
+	if !src.usedBy(template.Spec) {
+		return 0, nil
+	}
+
+	keys, selected := fingerprintKeysFor(r.fingerprintKeys(), settings...)
+	if selected && src.kind == sourceKindSecret && !hasAnyKey(src.data, keys) {
+		// the secret does not have the keys the workload restarts on, such as a secret without a CA
+		// certificate for a workload that restarts on CA changes, so its changes never restart it.
+		if allowed, _ := r.optInKeys().optedIn(workload, policy, namespace); allowed {
+			r.Eventf(workload, corev1.EventTypeWarning, refreshIgnored.reason, "%s Secret %s has none of the keys %s.", refreshIgnored.message, src.GetName(), strings.Join(keys, ", "))
+		}
+		return 0, nil
+	}
+
+	fingerprint := src.fingerprint(keys)
+
+	recorded := sourceRecorded(src.key(), workload.GetAnnotations())
+	if recorded && !outdatedSecretInUse(src.key(), src.GetResourceVersion(), fingerprint, workload.GetAnnotations()) {
+		return 0, nil
+	}
+
+	allowed, reason := r.optInKeys().optedIn(workload, policy, namespace)
+	if !allowed {
+		r.Log.V(2).Info("Workload makes use of source but has not opted-in", src.kind, src.GetName(), "Kind", kind, "Name", workload.GetName(), "Namespace", src.GetNamespace(), "Reason", reason)
+		return 0, nil
+	}
+
+	// a workload seen using the source for the first time, such as when it is created or opts in,
+	// is assumed to use its current contents, so they are recorded without restarting the workload.
+	if !recorded {
+		r.Log.V(1).Info("Recording source in use by workload", src.kind, src.GetName(), "Kind", kind, "Name", workload.GetName(), "Namespace", src.GetNamespace())
+		seen := workload.DeepCopy()
+		annotations := seen.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		updateSecretRevisionAnnotation(secretResourceVersionAnnotation, src.key(), src.GetResourceVersion(), annotations)
+		updateSecretRevisionAnnotation(secretFingerprintAnnotation, src.key(), fingerprint, annotations)
+		seen.SetAnnotations(annotations)
+		return 0, r.Update(context.TODO(), seen)
+	}
+
+	r.Log.V(1).Info("Workload makes use of source and has opted-in", src.kind, src.GetName(), "Kind", kind, "Name", workload.GetName(), "Namespace", src.GetNamespace(), "Reason", reason)
+	updated := workload.DeepCopy()
+	restarts, err := adapter.Restart(updated, time.Now().Format("2006-1-2.1504"))
+	if err != nil {
+		return 0, err
+	}
+
+	if !restarts {
+		r.Log.V(1).Info("Workload will use the updated source when it next creates pods", src.kind, src.GetName(), "Kind", kind, "Name", workload.GetName(), "Namespace", src.GetNamespace())
+		return 0, nil
+	}
+
+	if auditFor(r.Audit, settings...) {
+		r.Log.Info("Auditing refresh", src.kind, src.GetName(), "Kind", kind, "Name", workload.GetName(), "Namespace", src.GetNamespace())
+		r.Eventf(workload, corev1.EventTypeNormal, refreshAudit.reason, "%s Restarts would be allowed as %s.", refreshAudit.message, reason)
+		auditedRefreshes.WithLabelValues(workload.GetNamespace(), kind).Inc()
+		r.recordRefresh(src.GetNamespace(), src.key(), refreshRecord{Kind: kind, Name: workload.GetName(), Time: metav1.Now(), Result: refreshAudited, Reason: reason})
+		return 0, nil
+	}
+
+	restartPolicy, err := restartPolicyFor(r.RestartDebounce, settings...)
+	if err != nil {
+		return 0, err
+	}
+
+	// secrets changing while a restart is pending are added to the pending restart, so
+	// that the workload is restarted once for all of them.
+	now := time.Now()
+	pending := getPendingRestart(workload.GetAnnotations())
+	restartAt, changed := pending.schedule(src.key(), src.GetResourceVersion(), fingerprint, restartPolicy, now)
+	pending.recordUpdate(src.key(), secretUpdateTime(src))
+	if restartAt.After(now) {
+		if changed {
+			r.Log.Info("Scheduling refresh", src.kind, src.GetName(), "Kind", kind, "Name", workload.GetName(), "Namespace", src.GetNamespace(), "RestartAt", restartAt.String())
+			pendingWorkload := workload.DeepCopy()
+			annotations := pendingWorkload.GetAnnotations()
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			setPendingRestart(pending, annotations)
+			pendingWorkload.SetAnnotations(annotations)
+			if err := r.Update(context.TODO(), pendingWorkload); err != nil {
+				return 0, err
 			}
+			r.Eventf(workload, corev1.EventTypeNormal, refreshPending.reason, "%s %s, as %s.", refreshPending.message, pending.RestartAt.Format(time.RFC3339), reason)
 		}
+		return restartAt.Sub(now), nil
 	}
 
-	// Check statefulsets in the relevant namespace
-	for _, sts := range stsList.Items {
-		r.Log.Info("Checking Statefulset for usage of certificate found in secret", "Secret", secret.GetName(), "Statefulset", sts.GetName(), "Namespace", secret.GetNamespace()) //debug make higher verbosity level
-		updatedAt := time.Now().Format("2006-1-2.1504")
-		if hasAllowRestartAnnotation(sts.ObjectMeta) && usesSecret(secret, sts.Spec.Template.Spec) && outdatedSecretInUse(secret.GetName(), secret.GetResourceVersion(), sts.GetObjectMeta().GetAnnotations()) {
-			r.Log.Info("Statefulset makes use of secret and has opted-in", "Secret", secret.GetName(), "Statefulset", sts.GetName(), "Namespace", secret.GetNamespace())
-			updatedsts := sts.DeepCopy()
-			updatedsts.ObjectMeta.Labels[timeRestartedLabel] = updatedAt
-			updatedsts.Spec.Template.ObjectMeta.Labels[timeRestartedLabel] = updatedAt
-			updateSecretRevisionAnnotation(secret.GetName(), secret.GetResourceVersion(), updatedsts.GetAnnotations())
-			r.Log.Info("Initiating refresh", "Secret", secret.GetName(), "Statefulset", sts.GetName(), "Namespace", secret.GetNamespace())
-			err := r.Update(context.TODO(), updatedsts)
-			if err != nil {
-				r.Log.Error(err, "Unable to restart Statefulset.", "Statefulset.Name", sts.GetName())
-				r.Event(&sts, corev1.EventTypeWarning, refreshFailure.reason, refreshFailure.message)
-				refreshErrors = append(refreshErrors, refreshErrorData{kind: sts.Kind, name: sts.GetName(), namespace: sts.GetNamespace(), errorMsg: err.Error()})
-				updateFailed = true
+	strategy, err := refreshStrategyFor(r.RefreshStrategy, settings...)
+	if err != nil {
+		return 0, err
+	}
+
+	// workloads reloading their certificates in place are not restarted, unless reloading fails.
+	if strategy != restartStrategy {
+		pods, err := r.podsFor(workload, template)
+		if err == nil {
+			err = r.reload(strategy, pods, now)
+		}
+
+		if err == nil {
+			reloaded := workload.DeepCopy()
+			markRefreshed(reloaded, pending, now)
+			r.Log.Info("Reloading pods", "Strategy", strategy, src.kind, src.GetName(), "Kind", kind, "Name", workload.GetName(), "Namespace", src.GetNamespace())
+			if err := r.Update(context.TODO(), reloaded); err != nil {
+				return 0, err
+			}
+			r.Eventf(workload, corev1.EventTypeNormal, reload.reason, "%s Reloads are allowed as %s.", reload.message, reason)
+			for _, name := range pending.secretNames() {
+				r.recordRefresh(workload.GetNamespace(), name, refreshRecord{Kind: kind, Name: workload.GetName(), Time: metav1.Now(), Result: refreshReloaded, Reason: reason})
 			}
+			return 0, nil
 		}
+
+		r.Log.Error(err, "Unable to reload pods, restarting them instead", "Strategy", strategy, src.kind, src.GetName(), "Kind", kind, "Name", workload.GetName(), "Namespace", src.GetNamespace())
+		r.Eventf(workload, corev1.EventTypeWarning, reloadFailure.reason, "%s %s", reloadFailure.message, err)
 	}
 
-	// If updating anything failed
-	// TODO(komish): This requeues if _any_ of the refreshes fail, but this would cause a successful deployment to
-	// be restarted continuously. Need to requeue but with only the failed deployment.
-	if updateFailed {
-		r.Log.Info("Resource(s) that opted-in to refreshes have failed to refresh but the request will not be requeued",
-			"Secret.Name", secret.GetName(),
-			"Secret.Namespace", secret.GetNamespace(),
-			"Error Message", refreshErrors)
-		// return reconcile.Result{}, err // don't uncomment, see above.
+	key := rolloutKey(kind, workload.GetNamespace(), workload.GetName())
+	if !r.scheduler.admit(key, adapter, workload.GetNamespace(), workload.GetName(), policyRestartLimit(policy), now) {
+		r.Log.Info("Deferring refresh until other rollouts complete", src.kind, src.GetName(), "Kind", kind, "Name", workload.GetName(), "Namespace", src.GetNamespace())
+		return restartPollInterval, nil
 	}
 
-	r.Log.Info("Done Reconciling CertManager TLS Certificates")
+	pdb, err := r.blockingPodDisruptionBudget(workload, template)
+	if err != nil {
+		r.scheduler.cancel(key)
+		return 0, err
+	}
 
-	return ctrl.Result{}, nil
+	if pdb != "" {
+		r.scheduler.cancel(key)
+		// restarts that stay blocked fail, so that they are reported and retried with a backoff.
+		if blockedFor := r.scheduler.blockedByBudget(key, adapter, workload.GetNamespace(), workload.GetName(), now); blockedFor >= podDisruptionBudgetTimeout {
+			return 0, &blockedByBudgetError{podDisruptionBudget: pdb, blockedFor: blockedFor}
+		}
+		r.Log.Info("Deferring refresh until the PodDisruptionBudget allows disruptions", "PodDisruptionBudget", pdb, src.kind, src.GetName(), "Kind", kind, "Name", workload.GetName(), "Namespace", src.GetNamespace())
+		return restartPollInterval, nil
+	}
+	r.scheduler.unblocked(key)
+
+	markRefreshed(updated, pending, now)
+
+	r.Eventf(workload, corev1.EventTypeNormal, refresh.reason, "%s Restarts are allowed as %s.", refresh.message, reason)
+	r.Log.Info("Initiating refresh", src.kind, src.GetName(), "Kind", kind, "Name", workload.GetName(), "Namespace", src.GetNamespace())
+	if err := r.Update(context.TODO(), updated); err != nil {
+		r.scheduler.cancel(key)
+		return 0, err
+	}
+
+	secretNames := pending.secretNames()
+	restartsTriggered.WithLabelValues(workload.GetNamespace(), kind).Inc()
+	r.scheduler.started(key, adapter, workload.GetNamespace(), workload.GetName(), time.Now(), pending.firstUpdate(now), secretNames...)
+	for _, name := range secretNames {
+		r.recordRefresh(workload.GetNamespace(), name, refreshRecord{Kind: kind, Name: workload.GetName(), Time: metav1.Now(), Result: refreshRestarted, Reason: reason})
+	}
+	return 0, nil
+}
+
+// blockingPodDisruptionBudget returns the name of a PodDisruptionBudget in the workload's namespace
+// that the rollout of restarting the workload would violate, or an empty string if there is none.
+func (r *PodRefreshReconciler) blockingPodDisruptionBudget(workload *unstructured.Unstructured, template corev1.PodTemplateSpec) (string, error) {
+	unavailable, err := rolloutUnavailable(workload)
+	if err != nil {
+		return "", err
+	}
+
+	if unavailable < 1 {
+		return "", nil
+	}
+
+	pdbs := policyv1beta1.PodDisruptionBudgetList{}
+	if err := r.List(context.TODO(), &pdbs, client.InNamespace(workload.GetNamespace())); err != nil {
+		return "", err
+	}
+
+	return blockingPodDisruptionBudget(pdbs.Items, template.GetLabels(), unavailable), nil
+}
+
+// blockedByBudgetError is returned when the restart of a workload has been deferred by a
+// PodDisruptionBudget that allows too few disruptions for longer than the podDisruptionBudgetTimeout.
+type blockedByBudgetError struct {
+	podDisruptionBudget string
+	blockedFor          time.Duration
+}
+
+func (e *blockedByBudgetError) Error() string {
+	return fmt.Sprintf("PodDisruptionBudget %s has allowed too few disruptions for %s", e.podDisruptionBudget, e.blockedFor.Round(time.Second))
+}
+
+// markRefreshed records in the workload's annotations that it was refreshed at now for the secrets
+// of the pending restart, and clears the pending restart.
+func markRefreshed(workload *unstructured.Unstructured, pending pendingRestart, now time.Time) {
+	annotations := workload.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	for name, pendingSecret := range pending.Secrets {
+		updateSecretRevisionAnnotation(secretResourceVersionAnnotation, name, pendingSecret.ResourceVersion, annotations)
+		updateSecretRevisionAnnotation(secretFingerprintAnnotation, name, pendingSecret.Fingerprint, annotations)
+	}
+	delete(annotations, pendingRestartAnnotation)
+	annotations[lastRefreshedAnnotation] = now.UTC().Format(time.RFC3339)
+	workload.SetAnnotations(annotations)
+}
+
+// workloadSettings returns the policy selecting the workload and the source, if any, and the
+// workload's namespace, which configure how the workload is refreshed together with its annotations.
+func (r *PodRefreshReconciler) workloadSettings(workload metav1.Object, src refreshSource) (*operatorsv1alpha1.PodRefreshPolicy, *corev1.Namespace, error) {
+	namespace := &corev1.Namespace{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: workload.GetNamespace()}, namespace); err != nil {
+		return nil, nil, err
+	}
+
+	policies := operatorsv1alpha1.PodRefreshPolicyList{}
+	if err := r.List(context.TODO(), &policies, client.InNamespace(workload.GetNamespace())); err != nil {
+		return nil, nil, err
+	}
+
+	return policyFor(policies.Items, workload, src.policySubject()), namespace, nil
+}
+
+// fingerprintKeys returns the secret keys fingerprinted to detect that a secret has changed.
+func (r *PodRefreshReconciler) fingerprintKeys() []string {
+	if len(r.FingerprintKeys) == 0 {
+		return DefaultFingerprintKeys
+	}
+
+	return r.FingerprintKeys
+}
+
+// optInKeys returns the annotation keys that opt workloads and namespaces in to restarts.
+func (r *PodRefreshReconciler) optInKeys() optInKeys {
+	return newOptInKeys(r.AllowRestartAnnotation)
 }
 
 // SetupWithManager configures a controller owned by the manager mgr.
+// Workloads are watched so that their cache and indexes are populated when the
+// controller starts, and so that a workload opting in to restarts is refreshed for the
+// secrets it references. Workload kinds that are not served by the cluster are skipped.
+// ConfigMaps are refreshed by a second controller if WatchConfigMaps is set, and the status of
+// PodRefreshPolicies is reported by another. The certificates in the watched secrets are exported
+// as metrics if CertificateMetrics is set.
 func (r *PodRefreshReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&corev1.Secret{}).
-		WithEventFilter(predicate.And(
+	if _, err := parseRefreshStrategy(r.RefreshStrategy); err != nil {
+		return err
+	}
+
+	r.workloads = &workloadRegistry{}
+	for _, adapter := range newWorkloadRegistry(r.ExtraWorkloads...).adapters {
+		gvk := adapter.GroupVersionKind()
+		if _, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+			if meta.IsNoMatchError(err) {
+				r.Log.Info("Workload kind is not served by the cluster and will not be refreshed", "GroupVersionKind", gvk.String())
+				continue
+			}
+			return err
+		}
+		r.workloads.register(adapter)
+	}
+
+	r.retries = newRetryTracker(retryBaseDelay, retryMaxDelay)
+	r.expiryWarnings = newExpiryWarnings()
+	r.scheduler = newRestartScheduler(r.MaxConcurrentRestarts, r.MaxConcurrentRestartsPerNamespace)
+
+	// the manager's client reads unstructured objects from the API server, so workloads
+	// are read from the cache to make use of the secretReferenceIndex.
+	r.workloadReader = mgr.GetCache()
+	r.apiReader = mgr.GetAPIReader()
+	r.httpClient = newReloadClient()
+	if err := setupIndexes(mgr.GetFieldIndexer(), r.workloads); err != nil {
+		return err
+	}
+
+	bldr := ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Secret{}, builder.WithPredicates(
 			predicate.ResourceVersionChangedPredicate{},
-			isCertManagerIssuedTLSPredicate{},
-		)).
-		Complete(r)
+			refreshSourcePredicate{reader: r.Client, kind: sourceKindSecret},
+		))
+
+	for _, adapter := range r.workloads.adapters {
+		bldr = bldr.Watches(
+			&source.Kind{Type: newWorkload(adapter)},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: secretsForWorkload(adapter)},
+			builder.WithPredicates(optedInPredicate{keys: r.optInKeys()}))
+	}
+
+	bldr = bldr.Watches(
+		&source.Kind{Type: &corev1.Namespace{}},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.secretsInNamespace)},
+		builder.WithPredicates(namespaceOptedInPredicate{keys: r.optInKeys()}))
+
+	if err := bldr.Complete(r); err != nil {
+		return err
+	}
+
+	if r.CertificateMetrics {
+		if err := mgr.Add(newCertificateCollector(mgr.GetCache(), r.Log.WithName("certificate-metrics"))); err != nil {
+			return err
+		}
+	}
+
+	if r.WatchConfigMaps {
+		if err := (&configMapRefreshReconciler{PodRefreshReconciler: r}).setupWithManager(mgr); err != nil {
+			return err
+		}
+	}
+
+	return (&podRefreshPolicyStatusReconciler{
+		Client:         r.Client,
+		Log:            r.Log.WithName("policy-status"),
+		workloads:      r.workloads,
+		workloadReader: r.workloadReader,
+	}).setupWithManager(mgr)
 }
 
 // refreshErrorData represents some metadata about an error encountered while trying to
@@ -233,126 +652,98 @@ type podRefresherEvent struct {
 	message string
 }
 
-// hasAllowRestartAnnotation returns true if the object meta has opted into restarts
-// via inclusion of a restart annotation, and false if the annotation is either there and
-// isn't set to true, or isn't set at all.
-// TODO() allow for arbitrary annotations to be checked in addition to our default for
-// backwards compatibility.
-func hasAllowRestartAnnotation(metadata metav1.ObjectMeta) bool {
-	annotations := metadata.GetAnnotations()
-	val, ok := annotations[allowRestartAnnotation]
-	// It's possible the value of the annotation is false, so check that the annotation
-	// is both present and true.
-	if val == "true" && ok {
-		return ok
-	}
-
-	return false
-}
-
-// usesSecret returns true if podspec contains a volume that is sourced from
-// a secret whose name matches the secret parameter, and false if it does not.
-func usesSecret(secret *corev1.Secret, podspec corev1.PodSpec) bool {
-	vols := podspec.Volumes
-
-	for _, vol := range vols {
-		// VolumeSource.Secret is a pointer, if it's uninitialized it should be nil
-		if secretRef := vol.VolumeSource.Secret; secretRef != nil {
-			// return true if we get a match
-			if secretRef.SecretName == secret.GetName() {
-				return true
-			}
-		}
+// outdatedSecretInUse checks to see if the target's object metadata has an annotation
+// for the secret indicating the fingerprint of the secret the object was last bounced for.
+// If the fingerprint matches, then it's assumed the secret does not need to
+// to bounce and it saves the target object from a new rollout. Objects bounced before
+// fingerprints were recorded are checked against the last resource version they were
+// bounced for instead.
+func outdatedSecretInUse(secretName, resourceVersion, fingerprint string, a map[string]string) bool {
+	if recorded, ok := recordedSecretValue(secretFingerprintAnnotation, secretName, a); ok {
+		// the contents of the secret have changed since the last bounce.
+		return recorded != fingerprint
 	}
-	// We didn't find a volume from a secret that matched our input secret.
-	return false
-}
 
-// outdatedSecretInUse checks to see if the target's object metadata has an annotation
-// for the secret indicating the last resource version the object was bounced for.
-// If the resource version matches, then it's assumed the secret does not need to
-// to bounce and it saves the target object from a new rollout.
-func outdatedSecretInUse(secretName, resourceVersion string, a map[string]string) bool {
-	val, ok := a[secretResourceVersionAnnotation]
+	recorded, ok := recordedSecretValue(secretResourceVersionAnnotation, secretName, a)
 	if !ok {
-		// the resourceVersion annotation wasn't on the object at all so
-		// we have to assume the object needs a bounce.
+		// we didn't find an entry for this object for the secret that's being reconciled
+		// so we have to assume it needs updating and bounce the object.
 		return true
 	}
 
-	var rvmap map[string]string
-	err := json.Unmarshal([]byte(val), &rvmap)
-	if err != nil {
-		// it is expected that the value of this is a map. If it's not a map, we'll need
-		// to clear it out and start again. We don't clear it out here. We let the update
-		// logic handle that.
-		return true
-	}
+	// the resourceVersion on the secret does not match the resourceVersion for that secret that last
+	// triggered a bounce, so we need t bounce it now.
+	return recorded != resourceVersion
+}
+
+// sourceRecorded returns true if the fingerprint or resourceVersion the workload was last
+// refreshed for is recorded in its annotations a for the secret.
+func sourceRecorded(secretName string, a map[string]string) bool {
+	_, fingerprinted := recordedSecretValue(secretFingerprintAnnotation, secretName, a)
+	_, versioned := recordedSecretValue(secretResourceVersionAnnotation, secretName, a)
+	return fingerprinted || versioned
+}
 
-	secretResourceVersion, ok := rvmap[secretName]
+// recordedSecretValue returns the value recorded for a secret in the annotation, and false if
+// the annotation does not have an entry for the secret. The annotation is expected to be a map
+// of secret names to values. If it's not a map, we'll need to clear it out and start again. We
+// don't clear it out here. We let the update logic handle that.
+func recordedSecretValue(annotation, secretName string, a map[string]string) (string, bool) {
+	val, ok := a[annotation]
 	if !ok {
-		// we didn't find an entry for this object for the secret that's being reconciled
-		// so we have to assume it needs updating and bounce the object.
-		return true
+		return "", false
 	}
 
-	if secretResourceVersion != resourceVersion {
-		// the resourceVersion on the secret does not match the resourceVersion for that secret that last
-		// triggered a bounce, so we need t bounce it now.
-		return true
+	var m map[string]string
+	if err := json.Unmarshal([]byte(val), &m); err != nil {
+		return "", false
 	}
 
-	return false
+	recorded, ok := m[secretName]
+	return recorded, ok
 }
 
-// updateSecretRevisionAnnotation takes input annotations and updates the secret resource version annotation
-// with a new revision for a given secret. If the secret resourceVers map does not exist or is malformed, this
+// updateSecretRevisionAnnotation takes input annotations and updates the map of secret names to values
+// in the annotation with a new value for a given secret. If the map does not exist or is malformed, this
 // overwrites it entirely.
-func updateSecretRevisionAnnotation(secretName, resourceVers string, a map[string]string) {
-	var resourceVersMap map[string]string
-	val, ok := a[secretResourceVersionAnnotation]
-	unmarshalErr := json.Unmarshal([]byte(val), &resourceVersMap)
-	if !ok || unmarshalErr != nil {
+func updateSecretRevisionAnnotation(annotation, secretName, value string, a map[string]string) {
+	var m map[string]string
+	val, ok := a[annotation]
+	unmarshalErr := json.Unmarshal([]byte(val), &m)
+	if !ok || unmarshalErr != nil || m == nil {
 		// didn't find it or it wasn't in the proper format so we have to
 		// create it from scratch
-		d, _ := json.Marshal(map[string]string{secretName: resourceVers})
-		a[secretResourceVersionAnnotation] = string(d)
-		return
+		m = make(map[string]string)
 	}
 
-	resourceVersMap[secretName] = resourceVers
-	d, _ := json.Marshal(resourceVersMap)
-	a[secretResourceVersionAnnotation] = string(d)
-	return
+	m[secretName] = value
+	d, _ := json.Marshal(m)
+	a[annotation] = string(d)
+}
+
+// optedInPredicate implements a predicate passing workload create events, and update events where
+// the workload has opted in to restarts and had not opted in before the update, so that the sources
+// the workload uses are recorded before they next change.
+type optedInPredicate struct {
+	keys optInKeys
 }
 
-// isCertManagerIssuedTLSPredicate implements a predicate verifying secret object meta indicates a
-// cert-manager issued TLS certificate. This only applies to Create and Update events. Deletes
-// and Generics should not make it to the work queue.
-type isCertManagerIssuedTLSPredicate struct{}
-
-// Update implements default UpdateEvent filter for validating if object has the
-// `cert-manager.io/issuer-kind` which helps identify that it is cert-manager issued.
-// Intended to be used with secrets.
-func (isCertManagerIssuedTLSPredicate) Update(e event.UpdateEvent) bool {
-	a := e.MetaNew.GetAnnotations()
-	_, isCertManagerIssued := a[issuerKindAnnotation]
-	return isCertManagerIssued
+// Update implements UpdateEvent filter for validating that the workload has just
+// opted in to restarts.
+func (p optedInPredicate) Update(e event.UpdateEvent) bool {
+	_, optedInBefore := p.keys.annotated(e.MetaOld)
+	_, optedInAfter := p.keys.annotated(e.MetaNew)
+	return !optedInBefore && optedInAfter
 }
 
-// Create implements default CreateEvent filter for validating if object has the
-// `cert-manager.io/issuer-kind` which helps identify that it is cert-manager issued.
-// Intended to be used with secrets.
-func (isCertManagerIssuedTLSPredicate) Create(e event.CreateEvent) bool {
-	a := e.Meta.GetAnnotations()
-	_, isCertManagerIssued := a[issuerKindAnnotation]
-	return isCertManagerIssued
+func (optedInPredicate) Create(e event.CreateEvent) bool {
+	return true
 }
 
-func (isCertManagerIssuedTLSPredicate) Delete(e event.DeleteEvent) bool {
+func (optedInPredicate) Delete(e event.DeleteEvent) bool {
 	return false
 }
 
-func (isCertManagerIssuedTLSPredicate) Generic(e event.GenericEvent) bool {
+func (optedInPredicate) Generic(e event.GenericEvent) bool {
 	return false
 }
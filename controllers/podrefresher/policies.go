@@ -0,0 +1,100 @@
+package podrefresher
+
+import (
+	"sort"
+	"strings"
+
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// lastRefreshedAnnotation records when a workload was last refreshed, in RFC3339 format.
+const lastRefreshedAnnotation string = "certmanagerdeployment.redhat.io/last-refreshed"
+
+// selects returns true if the selector matches obj. An empty selector matches everything,
+// and an invalid selector matches nothing.
+func selects(selector metav1.LabelSelector, obj metav1.Object) bool {
+	s, err := metav1.LabelSelectorAsSelector(&selector)
+	if err != nil {
+		return false
+	}
+
+	return s.Matches(labels.Set(obj.GetLabels()))
+}
+
+// policyFor returns the policy selecting the workload, and the secret if it is not nil, or nil if
+// no policy does. If more than one policy selects them, the oldest policy is returned.
+func policyFor(policies []operatorsv1alpha1.PodRefreshPolicy, workload, secret metav1.Object) *operatorsv1alpha1.PodRefreshPolicy {
+	var matching []operatorsv1alpha1.PodRefreshPolicy
+	for _, policy := range policies {
+		if !selects(policy.Spec.WorkloadSelector, workload) {
+			continue
+		}
+
+		if secret != nil && !selects(policy.Spec.SecretSelector, secret) {
+			continue
+		}
+
+		matching = append(matching, policy)
+	}
+
+	if len(matching) == 0 {
+		return nil
+	}
+
+	sort.Slice(matching, func(i, j int) bool {
+		ti, tj := matching[i].GetCreationTimestamp(), matching[j].GetCreationTimestamp()
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return matching[i].GetName() < matching[j].GetName()
+	})
+
+	return &matching[0]
+}
+
+// policyAnnotations returns the settings of the policy as the workload annotations that configure
+// the same settings, so that they can be resolved together with annotations on the workload and its
+// namespace. A nil policy has no annotations.
+func policyAnnotations(policy *operatorsv1alpha1.PodRefreshPolicy) metav1.Object {
+	a := make(map[string]string)
+	if policy == nil {
+		return &metav1.ObjectMeta{Annotations: a}
+	}
+
+	if policy.Spec.Strategy.Type != "" {
+		a[refreshStrategyAnnotation] = strings.ToLower(string(policy.Spec.Strategy.Type))
+	}
+
+	if policy.Spec.Strategy.RefreshOn != "" {
+		a[restartOnAnnotation] = strings.ToLower(string(policy.Spec.Strategy.RefreshOn))
+	}
+
+	if policy.Spec.Window != nil {
+		a[restartWindowAnnotation] = policy.Spec.Window.Schedule
+		if policy.Spec.Window.Duration != nil {
+			a[restartWindowDurationAnnotation] = policy.Spec.Window.Duration.Duration.String()
+		}
+	}
+
+	if policy.Spec.Debounce != nil {
+		a[restartDebounceAnnotation] = policy.Spec.Debounce.Duration.String()
+	}
+
+	if policy.Spec.Audit {
+		a[auditAnnotation] = "true"
+	}
+
+	return &metav1.ObjectMeta{Annotations: a}
+}
+
+// policyRestartLimit returns the maximum number of rollouts in progress in the namespace set by the
+// policy, or 0 if the policy does not set one.
+func policyRestartLimit(policy *operatorsv1alpha1.PodRefreshPolicy) int {
+	if policy == nil || policy.Spec.MaxConcurrentRestarts == nil {
+		return 0
+	}
+
+	return int(*policy.Spec.MaxConcurrentRestarts)
+}
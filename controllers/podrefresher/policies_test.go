@@ -0,0 +1,143 @@
+package podrefresher
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+)
+
+var _ = Describe("PodRefreshPolicies", func() {
+	var (
+		policies []operatorsv1alpha1.PodRefreshPolicy
+		web      = &metav1.ObjectMeta{Name: "web", Labels: map[string]string{"app": "web"}}
+		worker   = &metav1.ObjectMeta{Name: "worker", Labels: map[string]string{"app": "worker"}}
+		tls      = &metav1.ObjectMeta{Name: "tls", Labels: map[string]string{"tier": "frontend"}}
+		internal = &metav1.ObjectMeta{Name: "internal", Labels: map[string]string{"tier": "backend"}}
+		created  = time.Date(2020, 11, 2, 0, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		policies = []operatorsv1alpha1.PodRefreshPolicy{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "newer", CreationTimestamp: metav1.NewTime(created.Add(time.Hour))},
+				Spec: operatorsv1alpha1.PodRefreshPolicySpec{
+					WorkloadSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "older", CreationTimestamp: metav1.NewTime(created)},
+				Spec: operatorsv1alpha1.PodRefreshPolicySpec{
+					WorkloadSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					SecretSelector:   metav1.LabelSelector{MatchLabels: map[string]string{"tier": "frontend"}},
+				},
+			},
+		}
+	})
+
+	Context("When selecting the policy for a workload", func() {
+		It("Should return the oldest policy selecting the workload and secret", func() {
+			Expect(policyFor(policies, web, tls).GetName()).To(Equal("older"))
+		})
+
+		It("Should skip policies that don't select the secret", func() {
+			Expect(policyFor(policies, web, internal).GetName()).To(Equal("newer"))
+		})
+
+		It("Should return nil if no policy selects the workload", func() {
+			Expect(policyFor(policies, worker, tls)).To(BeNil())
+		})
+
+		It("Should treat an empty workload selector as selecting all workloads", func() {
+			all := operatorsv1alpha1.PodRefreshPolicy{ObjectMeta: metav1.ObjectMeta{Name: "all"}}
+			Expect(policyFor([]operatorsv1alpha1.PodRefreshPolicy{all}, worker, tls).GetName()).To(Equal("all"))
+		})
+	})
+
+	Context("When a workload is selected by a policy", func() {
+		namespace := &metav1.ObjectMeta{Name: "ns"}
+
+		It("Should opt in the workload unless it opts out", func() {
+			allowed, reason := newOptInKeys("").optedIn(&metav1.ObjectMeta{}, &policies[0], namespace)
+			Expect(allowed).To(BeTrue())
+			Expect(reason).To(ContainSubstring("PodRefreshPolicy newer"))
+
+			allowed, _ = newOptInKeys("").optedIn(&metav1.ObjectMeta{Annotations: map[string]string{allowRestartAnnotation: "false"}}, &policies[0], namespace)
+			Expect(allowed).To(BeFalse())
+		})
+
+		It("Should only opt in annotated workloads without a policy", func() {
+			allowed, _ := newOptInKeys("").optedIn(&metav1.ObjectMeta{}, nil, namespace)
+			Expect(allowed).To(BeFalse())
+
+			allowed, _ = newOptInKeys("").optedIn(&metav1.ObjectMeta{Annotations: map[string]string{allowRestartAnnotation: "true"}}, nil, namespace)
+			Expect(allowed).To(BeTrue())
+		})
+	})
+
+	Context("When resolving the settings of a policy", func() {
+		var policy *operatorsv1alpha1.PodRefreshPolicy
+
+		BeforeEach(func() {
+			maxRestarts := int32(2)
+			policy = &operatorsv1alpha1.PodRefreshPolicy{Spec: operatorsv1alpha1.PodRefreshPolicySpec{
+				Strategy: operatorsv1alpha1.PodRefreshStrategy{RefreshOn: operatorsv1alpha1.LeafPodRefreshTrigger},
+				Window: &operatorsv1alpha1.PodRefreshWindow{
+					Schedule: "0 2 * * *",
+					Duration: &metav1.Duration{Duration: 2 * time.Hour},
+				},
+				Debounce:              &metav1.Duration{Duration: 5 * time.Minute},
+				MaxConcurrentRestarts: &maxRestarts,
+			}}
+		})
+
+		It("Should configure the restart window and debounce", func() {
+			restartPolicy, err := restartPolicyFor(0, policyAnnotations(policy))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restartPolicy.window).ToNot(BeNil())
+			Expect(restartPolicy.windowDuration).To(Equal(2 * time.Hour))
+			Expect(restartPolicy.debounce).To(Equal(5 * time.Minute))
+		})
+
+		It("Should configure the fingerprinted keys", func() {
+			keys, _ := fingerprintKeysFor(DefaultFingerprintKeys, policyAnnotations(policy))
+			Expect(keys).To(ConsistOf("tls.crt", "tls.key"))
+		})
+
+		It("Should be overridden by annotations on the workload", func() {
+			workload := &metav1.ObjectMeta{Annotations: map[string]string{restartOnAnnotation: restartOnCA}}
+			keys, _ := fingerprintKeysFor(DefaultFingerprintKeys, workload, policyAnnotations(policy))
+			Expect(keys).To(ConsistOf("ca.crt"))
+		})
+
+		It("Should override annotations on the namespace", func() {
+			namespace := &metav1.ObjectMeta{Annotations: map[string]string{restartDebounceAnnotation: "1m"}}
+			restartPolicy, err := restartPolicyFor(0, &metav1.ObjectMeta{}, policyAnnotations(policy), namespace)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restartPolicy.debounce).To(Equal(5 * time.Minute))
+		})
+
+		It("Should limit restarts in the namespace", func() {
+			Expect(policyRestartLimit(policy)).To(Equal(2))
+			Expect(policyRestartLimit(nil)).To(Equal(0))
+
+			scheduler := newRestartScheduler(0, 1)
+			scheduler.started(rolloutKey("Deployment", "ns", "web"), adapterFor("Deployment"), "ns", "web", time.Now(), time.Now())
+			Expect(scheduler.admit(rolloutKey("Deployment", "ns", "api"), adapterFor("Deployment"), "ns", "api", 0, time.Now())).To(BeFalse())
+			Expect(scheduler.admit(rolloutKey("Deployment", "ns", "api"), adapterFor("Deployment"), "ns", "api", policyRestartLimit(policy), time.Now())).To(BeTrue())
+		})
+	})
+
+	Context("When reporting when a workload was last refreshed", func() {
+		It("Should read the last refreshed annotation", func() {
+			workload := toUnstructured(deploymentUsingSecrets("ns", "web"))
+			Expect(lastRefreshed(workload)).To(BeNil())
+
+			workload.SetAnnotations(map[string]string{lastRefreshedAnnotation: "2020-11-02T02:00:00Z"})
+			Expect(lastRefreshed(workload).Time).To(BeTemporally("==", time.Date(2020, 11, 2, 2, 0, 0, 0, time.UTC)))
+		})
+	})
+})
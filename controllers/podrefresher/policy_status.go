@@ -0,0 +1,128 @@
+package podrefresher
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// podRefreshPolicyStatusReconciler reports the workloads selected by each PodRefreshPolicy,
+// and when they were last refreshed, in the policy's status.
+type podRefreshPolicyStatusReconciler struct {
+	client.Client
+	Log            logr.Logger
+	workloads      *workloadRegistry
+	workloadReader client.Reader
+}
+
+// Reconcile updates the status of a PodRefreshPolicy.
+func (r *podRefreshPolicyStatusReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	policy := &operatorsv1alpha1.PodRefreshPolicy{}
+	if err := r.Get(context.TODO(), req.NamespacedName, policy); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	var selected []operatorsv1alpha1.PodRefreshPolicyWorkload
+	for _, adapter := range r.workloads.adapters {
+		workloads := newWorkloadList(adapter)
+		if err := r.workloadReader.List(context.TODO(), workloads, client.InNamespace(policy.GetNamespace())); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		for _, workload := range workloads.Items {
+			if !selects(policy.Spec.WorkloadSelector, &workload) {
+				continue
+			}
+
+			selected = append(selected, operatorsv1alpha1.PodRefreshPolicyWorkload{
+				Kind:          adapter.GroupVersionKind().Kind,
+				Name:          workload.GetName(),
+				LastRefreshed: lastRefreshed(&workload),
+			})
+		}
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		if selected[i].Kind != selected[j].Kind {
+			return selected[i].Kind < selected[j].Kind
+		}
+		return selected[i].Name < selected[j].Name
+	})
+
+	status := operatorsv1alpha1.PodRefreshPolicyStatus{
+		ObservedGeneration: policy.GetGeneration(),
+		Workloads:          selected,
+	}
+
+	if reflect.DeepEqual(status, policy.Status) {
+		return reconcile.Result{}, nil
+	}
+
+	r.Log.V(2).Info("Updating PodRefreshPolicy status", "PodRefreshPolicy", req.NamespacedName, "Workloads", len(selected))
+	policy.Status = status
+	return reconcile.Result{}, r.Status().Update(context.TODO(), policy)
+}
+
+// lastRefreshed returns the time recorded in the workload's lastRefreshedAnnotation,
+// or nil if the workload has not been refreshed.
+func lastRefreshed(workload *unstructured.Unstructured) *metav1.Time {
+	val, ok := workload.GetAnnotations()[lastRefreshedAnnotation]
+	if !ok {
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return nil
+	}
+
+	refreshed := metav1.NewTime(t)
+	return &refreshed
+}
+
+// policiesInNamespace maps an object to reconcile requests for every PodRefreshPolicy in its namespace.
+func (r *podRefreshPolicyStatusReconciler) policiesInNamespace(o handler.MapObject) []reconcile.Request {
+	policies := operatorsv1alpha1.PodRefreshPolicyList{}
+	if err := r.List(context.TODO(), &policies, client.InNamespace(o.Meta.GetNamespace())); err != nil {
+		r.Log.Error(err, "Unable to list PodRefreshPolicies", "Namespace", o.Meta.GetNamespace())
+		return nil
+	}
+
+	reqs := make([]reconcile.Request, 0, len(policies.Items))
+	for _, policy := range policies.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: policy.GetName(), Namespace: policy.GetNamespace()}})
+	}
+
+	return reqs
+}
+
+// setupWithManager configures a controller owned by the manager mgr. Workloads are watched
+// so that the status of the policies in their namespace is kept up to date.
+func (r *podRefreshPolicyStatusReconciler) setupWithManager(mgr ctrl.Manager) error {
+	bldr := ctrl.NewControllerManagedBy(mgr).
+		For(&operatorsv1alpha1.PodRefreshPolicy{})
+
+	for _, adapter := range r.workloads.adapters {
+		bldr = bldr.Watches(
+			&source.Kind{Type: newWorkload(adapter)},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.policiesInNamespace)})
+	}
+
+	return bldr.Complete(r)
+}
@@ -0,0 +1,193 @@
+package podrefresher
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// refreshStrategyAnnotation is how a workload is refreshed, one of restartStrategy, httpReloadStrategy
+	// or podAnnotationStrategy. It can be set on the workload, or on its namespace. Defaults to the
+	// reconciler's RefreshStrategy.
+	refreshStrategyAnnotation string = "certmanagerdeployment.redhat.io/refresh-strategy"
+	// reloadEndpointAnnotation is the endpoint of a pod that reloads its certificates when it receives an
+	// HTTP POST, as a URL without a host, such as http://:8080/-/reload. The host is the pod's IP. Only http
+	// endpoints are supported, as the pod's IP is not in the names of the certificates it serves.
+	reloadEndpointAnnotation string = "certmanagerdeployment.redhat.io/reload-endpoint"
+	// reloadedAtAnnotation is updated on each pod of a workload refreshed with podAnnotationStrategy, with the
+	// time the pod was asked to reload in RFC3339 format.
+	reloadedAtAnnotation string = "certmanagerdeployment.redhat.io/reloaded-at"
+
+	// reloadTimeout is how long to wait for a pod to respond to a reload request.
+	reloadTimeout = 10 * time.Second
+)
+
+// refreshStrategy is how a workload is refreshed.
+type refreshStrategy string
+
+const (
+	// restartStrategy rolls out new pods for the workload.
+	restartStrategy refreshStrategy = "restart"
+	// httpReloadStrategy sends an HTTP POST to the reloadEndpointAnnotation of each pod of the workload.
+	httpReloadStrategy refreshStrategy = "httpreload"
+	// podAnnotationStrategy updates the reloadedAtAnnotation of each pod of the workload.
+	podAnnotationStrategy refreshStrategy = "podannotation"
+)
+
+// refreshStrategyFor returns the refresh strategy for a workload from the annotation on the first of
+// objs that has it, falling back to strategy. objs are typically the workload followed by its namespace.
+func refreshStrategyFor(strategy string, objs ...metav1.Object) (refreshStrategy, error) {
+	val, ok := annotationFor(refreshStrategyAnnotation, objs...)
+	if !ok {
+		return parseRefreshStrategy(strategy)
+	}
+
+	parsed, err := parseRefreshStrategy(val)
+	if err != nil {
+		return restartStrategy, fmt.Errorf("invalid %s annotation %q", refreshStrategyAnnotation, val)
+	}
+
+	return parsed, nil
+}
+
+// parseRefreshStrategy returns the refresh strategy named by val, ignoring case. An empty val is the
+// restartStrategy.
+func parseRefreshStrategy(val string) (refreshStrategy, error) {
+	if val == "" {
+		return restartStrategy, nil
+	}
+
+	switch strategy := refreshStrategy(strings.ToLower(val)); strategy {
+	case restartStrategy, httpReloadStrategy, podAnnotationStrategy:
+		return strategy, nil
+	default:
+		return restartStrategy, fmt.Errorf("invalid refresh strategy %q", val)
+	}
+}
+
+// reloadURL returns the URL to send a reload request to for the pod, from its reloadEndpointAnnotation.
+func reloadURL(pod *corev1.Pod) (string, error) {
+	val, ok := pod.GetAnnotations()[reloadEndpointAnnotation]
+	if !ok {
+		return "", fmt.Errorf("pod %s has no %s annotation", pod.GetName(), reloadEndpointAnnotation)
+	}
+
+	endpoint, err := url.Parse(val)
+	if err != nil || endpoint.Port() == "" {
+		return "", fmt.Errorf("pod %s has an invalid %s annotation %q", pod.GetName(), reloadEndpointAnnotation, val)
+	}
+
+	switch endpoint.Scheme {
+	case "":
+		endpoint.Scheme = "http"
+	case "http":
+	default:
+		return "", fmt.Errorf("pod %s has a %s annotation %q with unsupported scheme %q", pod.GetName(), reloadEndpointAnnotation, val, endpoint.Scheme)
+	}
+	endpoint.Host = net.JoinHostPort(pod.Status.PodIP, endpoint.Port())
+
+	return endpoint.String(), nil
+}
+
+// podsFor returns the running pods of the workload, matched by the labels of its pod template.
+func (r *PodRefreshReconciler) podsFor(workload *unstructured.Unstructured, template corev1.PodTemplateSpec) ([]corev1.Pod, error) {
+	if len(template.GetLabels()) == 0 {
+		return nil, fmt.Errorf("the pod template of %s has no labels to find its pods with", workload.GetName())
+	}
+
+	pods := corev1.PodList{}
+	if err := r.apiReader.List(context.TODO(), &pods, client.InNamespace(workload.GetNamespace()), client.MatchingLabels(template.GetLabels())); err != nil {
+		return nil, err
+	}
+
+	running := make([]corev1.Pod, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		if pod.GetDeletionTimestamp() != nil || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		running = append(running, pod)
+	}
+
+	return running, nil
+}
+
+// reload asks each of the pods to reload their certificates with the strategy at now. Pods created after
+// the secrets changed use the new certificates, so pods that are not running are not reloaded.
+func (r *PodRefreshReconciler) reload(strategy refreshStrategy, pods []corev1.Pod, now time.Time) error {
+	for i := range pods {
+		pod := &pods[i]
+		var err error
+		switch strategy {
+		case httpReloadStrategy:
+			err = r.reloadHTTP(pod)
+		case podAnnotationStrategy:
+			err = r.reloadAnnotation(pod, now)
+		default:
+			err = fmt.Errorf("unsupported reload strategy %q", strategy)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reloadHTTP sends an HTTP POST to the reload endpoint of the pod.
+func (r *PodRefreshReconciler) reloadHTTP(pod *corev1.Pod) error {
+	target, err := reloadURL(pod)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.httpClient.Post(target, "text/plain", nil)
+	if err != nil {
+		return fmt.Errorf("unable to reload pod %s: %s", pod.GetName(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unable to reload pod %s: %s responded %s", pod.GetName(), target, resp.Status)
+	}
+
+	return nil
+}
+
+// reloadAnnotation updates the reloadedAtAnnotation of the pod to now.
+func (r *PodRefreshReconciler) reloadAnnotation(pod *corev1.Pod, now time.Time) error {
+	patch := client.MergeFrom(pod.DeepCopy())
+	annotations := pod.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[reloadedAtAnnotation] = now.UTC().Format(time.RFC3339)
+	pod.SetAnnotations(annotations)
+
+	if err := r.Patch(context.TODO(), pod, patch); err != nil {
+		return fmt.Errorf("unable to annotate pod %s: %s", pod.GetName(), err)
+	}
+
+	return nil
+}
+
+// newReloadClient returns the HTTP client used to send reload requests to pods. Redirects are not
+// followed, so that a pod cannot have the operator send requests to other hosts.
+func newReloadClient() *http.Client {
+	return &http.Client{
+		Timeout: reloadTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
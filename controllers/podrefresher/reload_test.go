@@ -0,0 +1,197 @@
+package podrefresher
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+)
+
+var _ = Describe("In-place reloads", func() {
+	now := time.Date(2020, 11, 2, 2, 0, 0, 0, time.UTC)
+
+	pod := func(name string, phase corev1.PodPhase, annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, Labels: map[string]string{"app": "web"}, Annotations: annotations},
+			Status:     corev1.PodStatus{Phase: phase, PodIP: "127.0.0.1"},
+		}
+	}
+
+	Context("When resolving the refresh strategy", func() {
+		It("Should default to restarting", func() {
+			Expect(refreshStrategyFor("", &metav1.ObjectMeta{})).To(Equal(restartStrategy))
+		})
+
+		It("Should use the strategy of the first object with the annotation", func() {
+			workload := &metav1.ObjectMeta{Annotations: map[string]string{refreshStrategyAnnotation: "HTTPReload"}}
+			namespace := &metav1.ObjectMeta{Annotations: map[string]string{refreshStrategyAnnotation: "podannotation"}}
+			Expect(refreshStrategyFor("", workload, namespace)).To(Equal(httpReloadStrategy))
+			Expect(refreshStrategyFor("", &metav1.ObjectMeta{}, namespace)).To(Equal(podAnnotationStrategy))
+		})
+
+		It("Should use the strategy of the policy", func() {
+			policy := &operatorsv1alpha1.PodRefreshPolicy{Spec: operatorsv1alpha1.PodRefreshPolicySpec{
+				Strategy: operatorsv1alpha1.PodRefreshStrategy{Type: operatorsv1alpha1.PodAnnotationPodRefreshStrategyType},
+			}}
+			Expect(refreshStrategyFor("", &metav1.ObjectMeta{}, policyAnnotations(policy))).To(Equal(podAnnotationStrategy))
+		})
+
+		It("Should fall back to the default strategy", func() {
+			Expect(refreshStrategyFor("HTTPReload", &metav1.ObjectMeta{})).To(Equal(httpReloadStrategy))
+
+			namespace := &metav1.ObjectMeta{Annotations: map[string]string{refreshStrategyAnnotation: "restart"}}
+			Expect(refreshStrategyFor("HTTPReload", &metav1.ObjectMeta{}, namespace)).To(Equal(restartStrategy))
+		})
+
+		It("Should reject unknown strategies", func() {
+			_, err := refreshStrategyFor("", &metav1.ObjectMeta{Annotations: map[string]string{refreshStrategyAnnotation: "signal"}})
+			Expect(err).To(HaveOccurred())
+
+			_, err = parseRefreshStrategy("signal")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When building the reload URL of a pod", func() {
+		It("Should send the request to the pod's IP", func() {
+			p := pod("web", corev1.PodRunning, map[string]string{reloadEndpointAnnotation: "http://:8080/-/reload"})
+			Expect(reloadURL(p)).To(Equal("http://127.0.0.1:8080/-/reload"))
+
+			p = pod("web", corev1.PodRunning, map[string]string{reloadEndpointAnnotation: "//:8080/-/reload"})
+			Expect(reloadURL(p)).To(Equal("http://127.0.0.1:8080/-/reload"))
+		})
+
+		It("Should reject endpoints that are not http", func() {
+			_, err := reloadURL(pod("web", corev1.PodRunning, map[string]string{reloadEndpointAnnotation: "https://:8443/-/reload"}))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should require the endpoint to have a port", func() {
+			_, err := reloadURL(pod("web", corev1.PodRunning, map[string]string{reloadEndpointAnnotation: "http:///-/reload"}))
+			Expect(err).To(HaveOccurred())
+
+			_, err = reloadURL(pod("web", corev1.PodRunning, nil))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When reloading pods", func() {
+		var (
+			server   *httptest.Server
+			status   int
+			requests int
+			endpoint string
+		)
+
+		BeforeEach(func() {
+			status, requests = http.StatusOK, 0
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.Method == http.MethodPost && req.URL.Path == "/-/reload" {
+					requests++
+				}
+				w.WriteHeader(status)
+			}))
+
+			u, err := url.Parse(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			_, port, _ := net.SplitHostPort(u.Host)
+			endpoint = "http://:" + port + "/-/reload"
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Should only reload running pods of the workload", func() {
+			c := fake.NewFakeClientWithScheme(scheme.Scheme,
+				pod("web-1", corev1.PodRunning, map[string]string{reloadEndpointAnnotation: endpoint}),
+				pod("web-2", corev1.PodPending, nil),
+			)
+			r := &PodRefreshReconciler{Client: c, Log: logf.Log, apiReader: c, httpClient: newReloadClient()}
+
+			pods, err := r.podsFor(toUnstructured(deploymentUsingSecrets("ns", "web")), corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pods).To(HaveLen(1))
+
+			Expect(r.reload(httpReloadStrategy, pods, now)).To(Succeed())
+			Expect(requests).To(Equal(1))
+		})
+
+		It("Should fail if a pod does not reload", func() {
+			status = http.StatusInternalServerError
+			r := &PodRefreshReconciler{Log: logf.Log, httpClient: newReloadClient()}
+			p := pod("web-1", corev1.PodRunning, map[string]string{reloadEndpointAnnotation: endpoint})
+			Expect(r.reload(httpReloadStrategy, []corev1.Pod{*p}, now)).ToNot(Succeed())
+		})
+
+		It("Should not follow redirects", func() {
+			redirected := 0
+			target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				redirected++
+			}))
+			defer target.Close()
+
+			redirecting := httptest.NewServer(http.RedirectHandler(target.URL+"/-/reload", http.StatusTemporaryRedirect))
+			defer redirecting.Close()
+
+			u, err := url.Parse(redirecting.URL)
+			Expect(err).ToNot(HaveOccurred())
+			_, port, _ := net.SplitHostPort(u.Host)
+
+			r := &PodRefreshReconciler{Log: logf.Log, httpClient: newReloadClient()}
+			p := pod("web-1", corev1.PodRunning, map[string]string{reloadEndpointAnnotation: "http://:" + port + "/-/reload"})
+			Expect(r.reload(httpReloadStrategy, []corev1.Pod{*p}, now)).ToNot(Succeed())
+			Expect(redirected).To(BeZero())
+		})
+
+		It("Should refuse to find pods of a template without labels", func() {
+			r := &PodRefreshReconciler{Log: logf.Log}
+			_, err := r.podsFor(toUnstructured(deploymentUsingSecrets("ns", "web")), corev1.PodTemplateSpec{})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should annotate pods with the reload time", func() {
+			c := fake.NewFakeClientWithScheme(scheme.Scheme, pod("web-1", corev1.PodRunning, nil))
+			r := &PodRefreshReconciler{Client: c, Log: logf.Log, apiReader: c}
+
+			Expect(r.reload(podAnnotationStrategy, []corev1.Pod{*pod("web-1", corev1.PodRunning, nil)}, now)).To(Succeed())
+
+			reloaded := &corev1.Pod{}
+			Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: "web-1"}, reloaded)).To(Succeed())
+			Expect(reloaded.GetAnnotations()).To(HaveKeyWithValue(reloadedAtAnnotation, "2020-11-02T02:00:00Z"))
+		})
+	})
+
+	Context("When a workload is refreshed", func() {
+		It("Should record the pending secrets and clear the pending restart", func() {
+			workload := toUnstructured(deploymentUsingSecrets("ns", "web"))
+			pending := getPendingRestart(nil)
+			pending.schedule("tls", "2", "abc", restartPolicy{}, now)
+			annotations := map[string]string{}
+			setPendingRestart(pending, annotations)
+			workload.SetAnnotations(annotations)
+
+			markRefreshed(workload, pending, now)
+			Expect(workload.GetAnnotations()).ToNot(HaveKey(pendingRestartAnnotation))
+			Expect(workload.GetAnnotations()).To(HaveKeyWithValue(lastRefreshedAnnotation, "2020-11-02T02:00:00Z"))
+			fingerprint, ok := recordedSecretValue(secretFingerprintAnnotation, "tls", workload.GetAnnotations())
+			Expect(ok).To(BeTrue())
+			Expect(fingerprint).To(Equal("abc"))
+		})
+	})
+})
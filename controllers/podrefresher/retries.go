@@ -0,0 +1,98 @@
+package podrefresher
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// retryBaseDelay is the delay before the first retry of a failed workload restart.
+	retryBaseDelay = 5 * time.Second
+	// retryMaxDelay is the maximum delay between retries of a failed workload restart.
+	retryMaxDelay = 10 * time.Minute
+)
+
+// retryTracker tracks failed workload restarts so that each workload is retried with its
+// own exponential backoff. Workloads that were restarted successfully are not tracked, as
+// the secretResourceVersionAnnotation prevents them from being restarted again.
+type retryTracker struct {
+	mu          sync.Mutex
+	rateLimiter workqueue.RateLimiter
+	retries     map[string]retry
+}
+
+// retry is the state of a failed restart of a workload for a secret.
+type retry struct {
+	// fingerprint is the fingerprint of the secret the restart failed for.
+	fingerprint string
+	// notBefore is the time before which the restart is not retried.
+	notBefore time.Time
+}
+
+// newRetryTracker returns a retryTracker backing off from baseDelay up to maxDelay.
+func newRetryTracker(baseDelay, maxDelay time.Duration) *retryTracker {
+	return &retryTracker{
+		rateLimiter: workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+		retries:     make(map[string]retry),
+	}
+}
+
+// retryKey returns the key tracking restarts of a workload for a secret.
+func retryKey(kind, namespace, name, secretName string) string {
+	return kind + "/" + namespace + "/" + name + "/" + secretName
+}
+
+// ready returns true if a restart for key and the secret fingerprint can be attempted at now,
+// and otherwise the time remaining until it can be attempted. A restart that failed for previous
+// contents of the secret does not delay a restart for new contents.
+func (t *retryTracker) ready(key, fingerprint string, now time.Time) (bool, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r, ok := t.retries[key]
+	if !ok {
+		return true, 0
+	}
+
+	if r.fingerprint != fingerprint {
+		t.forget(key)
+		return true, 0
+	}
+
+	if wait := r.notBefore.Sub(now); wait > 0 {
+		return false, wait
+	}
+
+	return true, 0
+}
+
+// failed records a failed restart for key and the secret fingerprint at now, and returns
+// the delay before it is retried.
+func (t *retryTracker) failed(key, fingerprint string, now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if r, ok := t.retries[key]; ok && r.fingerprint != fingerprint {
+		t.forget(key)
+	}
+
+	delay := t.rateLimiter.When(key)
+	t.retries[key] = retry{fingerprint: fingerprint, notBefore: now.Add(delay)}
+	return delay
+}
+
+// succeeded stops tracking retries for key.
+func (t *retryTracker) succeeded(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.forget(key)
+}
+
+// forget stops tracking retries for key. Callers must hold t.mu.
+func (t *retryTracker) forget(key string) {
+	t.rateLimiter.Forget(key)
+	delete(t.retries, key)
+}
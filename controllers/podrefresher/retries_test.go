@@ -0,0 +1,77 @@
+package podrefresher
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Workload restart retries", func() {
+	var (
+		tracker *retryTracker
+		now     time.Time
+		key     = retryKey("Deployment", "ns", "web", "tls")
+	)
+
+	BeforeEach(func() {
+		tracker = newRetryTracker(time.Second, 4*time.Second)
+		now = time.Now()
+	})
+
+	Context("When a workload has not failed to restart", func() {
+		It("Should be ready", func() {
+			ready, _ := tracker.ready(key, "1", now)
+			Expect(ready).To(BeTrue())
+		})
+	})
+
+	Context("When a workload fails to restart repeatedly", func() {
+		It("Should back off exponentially up to the maximum delay", func() {
+			Expect(tracker.failed(key, "1", now)).To(Equal(time.Second))
+			Expect(tracker.failed(key, "1", now)).To(Equal(2 * time.Second))
+			Expect(tracker.failed(key, "1", now)).To(Equal(4 * time.Second))
+			Expect(tracker.failed(key, "1", now)).To(Equal(4 * time.Second))
+		})
+
+		It("Should not be ready until the delay has passed", func() {
+			tracker.failed(key, "1", now)
+
+			ready, wait := tracker.ready(key, "1", now.Add(500*time.Millisecond))
+			Expect(ready).To(BeFalse())
+			Expect(wait).To(Equal(500 * time.Millisecond))
+
+			ready, _ = tracker.ready(key, "1", now.Add(time.Second))
+			Expect(ready).To(BeTrue())
+		})
+
+		It("Should not delay other workloads", func() {
+			tracker.failed(key, "1", now)
+			ready, _ := tracker.ready(retryKey("Deployment", "ns", "api", "tls"), "1", now)
+			Expect(ready).To(BeTrue())
+		})
+	})
+
+	Context("When the secret changes after a failed restart", func() {
+		It("Should be ready and reset the backoff", func() {
+			tracker.failed(key, "1", now)
+			tracker.failed(key, "1", now)
+
+			ready, _ := tracker.ready(key, "2", now)
+			Expect(ready).To(BeTrue())
+			Expect(tracker.failed(key, "2", now)).To(Equal(time.Second))
+		})
+	})
+
+	Context("When a workload restarts after failing", func() {
+		It("Should reset the backoff", func() {
+			tracker.failed(key, "1", now)
+			tracker.failed(key, "1", now)
+			tracker.succeeded(key)
+
+			ready, _ := tracker.ready(key, "1", now)
+			Expect(ready).To(BeTrue())
+			Expect(tracker.failed(key, "1", now)).To(Equal(time.Second))
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package podrefresher
+
+import (
+	"sync"
+
+	"github.com/go-logr/logr"
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Runner starts and stops the pod refresher while the operator is running, as configured by the
+// CertManagerDeployment. The refresher runs in a manager of its own, so that its controllers,
+// watches and caches are torn down when it is stopped. Metrics are served, and leader election
+// is held, by the operator's manager.
+type Runner struct {
+	// Config connects the refresher's manager to the cluster.
+	Config *rest.Config
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+	// NewReconciler returns the refresher to run in mgr, configured by the operator's flags. The
+	// settings configured by the CertManagerDeployment are applied to it before it is set up.
+	NewReconciler func(mgr ctrl.Manager) *PodRefreshReconciler
+
+	mu      sync.Mutex
+	running *runningRefresher
+}
+
+// runningRefresher is a pod refresher started by a Runner.
+type runningRefresher struct {
+	config operatorsv1alpha1.PodRefresherConfig
+	// stop is closed to stop the refresher's manager.
+	stop chan struct{}
+	// done is closed when the refresher's manager has stopped.
+	done chan struct{}
+}
+
+// Apply starts or stops the pod refresher so that it runs as configured by config. The refresher
+// is stopped if config is nil or not enabled, and restarted if it is running with a different
+// configuration.
+func (r *Runner) Apply(config *operatorsv1alpha1.PodRefresherConfig) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enabled := config != nil && config.Enabled
+	if r.running != nil {
+		if enabled && r.running.config == *config {
+			return nil
+		}
+
+		r.Log.Info("Stopping pod refresher")
+		close(r.running.stop)
+		<-r.running.done
+		r.running = nil
+	}
+
+	if !enabled {
+		return nil
+	}
+
+	return r.start(*config)
+}
+
+// start sets up a pod refresher configured by config in a new manager, and starts the manager.
+func (r *Runner) start(config operatorsv1alpha1.PodRefresherConfig) error {
+	// the strategy is checked before connecting to the cluster, so that an invalid configuration
+	// is reported without starting anything.
+	if _, err := parseRefreshStrategy(string(config.Strategy)); err != nil {
+		return err
+	}
+
+	mgr, err := ctrl.NewManager(r.Config, ctrl.Options{
+		Scheme:             r.Scheme,
+		MetricsBindAddress: "0",
+	})
+	if err != nil {
+		return err
+	}
+
+	reconciler := r.NewReconciler(mgr)
+	reconciler.AllowRestartAnnotation = config.OptInAnnotation
+	reconciler.RefreshStrategy = string(config.Strategy)
+	if err := reconciler.SetupWithManager(mgr); err != nil {
+		return err
+	}
+
+	running := &runningRefresher{config: config, stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		err := mgr.Start(running.stop)
+		close(running.done)
+		if err != nil {
+			r.Log.Error(err, "Pod refresher stopped unexpectedly")
+		}
+
+		// a refresher that stopped on its own is started again when the configuration is next applied.
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.running == running {
+			r.running = nil
+		}
+	}()
+
+	r.Log.Info("Starting pod refresher", "OptInAnnotation", config.OptInAnnotation, "Strategy", config.Strategy)
+	r.running = running
+	return nil
+}
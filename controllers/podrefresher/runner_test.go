@@ -0,0 +1,50 @@
+package podrefresher
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+)
+
+var _ = Describe("Pod refresher runner", func() {
+	var r *Runner
+
+	BeforeEach(func() {
+		r = &Runner{Config: &rest.Config{Host: "https://127.0.0.1:0"}, Log: logf.Log}
+	})
+
+	It("Should not start a refresher that is not configured or not enabled", func() {
+		Expect(r.Apply(nil)).To(Succeed())
+		Expect(r.Apply(&operatorsv1alpha1.PodRefresherConfig{Strategy: "Signal"})).To(Succeed())
+		Expect(r.running).To(BeNil())
+	})
+
+	It("Should reject invalid strategies before connecting to the cluster", func() {
+		err := r.Apply(&operatorsv1alpha1.PodRefresherConfig{Enabled: true, Strategy: "Signal"})
+		Expect(err).To(MatchError(ContainSubstring(`invalid refresh strategy "Signal"`)))
+		Expect(r.running).To(BeNil())
+	})
+
+	It("Should stop a running refresher when it is disabled", func() {
+		running := &runningRefresher{
+			config: operatorsv1alpha1.PodRefresherConfig{Enabled: true},
+			stop:   make(chan struct{}),
+			done:   make(chan struct{}),
+		}
+		go func() {
+			<-running.stop
+			close(running.done)
+		}()
+		r.running = running
+
+		Expect(r.Apply(&operatorsv1alpha1.PodRefresherConfig{Enabled: true})).To(Succeed())
+		Expect(r.running).To(Equal(running))
+
+		Expect(r.Apply(&operatorsv1alpha1.PodRefresherConfig{Enabled: false})).To(Succeed())
+		Expect(r.running).To(BeNil())
+		Expect(running.done).To(BeClosed())
+	})
+})
@@ -0,0 +1,349 @@
+package podrefresher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// restartPollInterval is how long a deferred restart waits before it is attempted again.
+	restartPollInterval = 15 * time.Second
+	// rolloutTimeout is how long a restarted workload counts against the restart budgets
+	// if its rollout does not complete.
+	rolloutTimeout = 15 * time.Minute
+	// podDisruptionBudgetTimeout is how long a restart is deferred by a PodDisruptionBudget that allows
+	// fewer disruptions than the workload's rollout makes before the refresh is reported as failed.
+	podDisruptionBudgetTimeout = time.Hour
+)
+
+// restartScheduler limits the number of workload rollouts started by the pod refresher that are in
+// progress at once, both across the cluster and within each namespace. A rollout counts against the
+// budgets from the time the workload is restarted until the workload reports that the rollout completed.
+type restartScheduler struct {
+	mu sync.Mutex
+	// maxConcurrent is the maximum number of rollouts in progress across the cluster. 0 is unlimited.
+	maxConcurrent int
+	// maxPerNamespace is the maximum number of rollouts in progress in a namespace. 0 is unlimited.
+	maxPerNamespace int
+	inFlight        map[string]rollout
+	// blocked are the restarts of workloads deferred by a PodDisruptionBudget.
+	blocked map[string]blockedRestart
+}
+
+// rollout is a workload rollout started by the pod refresher.
+type rollout struct {
+	adapter   WorkloadAdapter
+	namespace string
+	name      string
+	started   time.Time
+	// changed is when the first of the changed secrets the workload was restarted for was updated.
+	changed time.Time
+	// secrets are the names of the changed secrets the workload was restarted for.
+	secrets []string
+	// reserved is true while the workload is admitted but its restart has not started yet.
+	reserved bool
+}
+
+// blockedRestart is the restart of a workload deferred by a PodDisruptionBudget.
+type blockedRestart struct {
+	adapter   WorkloadAdapter
+	namespace string
+	name      string
+	// since is when the restart was first deferred.
+	since time.Time
+}
+
+// finishedRollout is a rollout that no longer counts against the restart budgets, and its result.
+type finishedRollout struct {
+	rollout
+	result refreshResult
+}
+
+// newRestartScheduler returns a restartScheduler with the given budgets. A budget of 0 is unlimited.
+func newRestartScheduler(maxConcurrent, maxPerNamespace int) *restartScheduler {
+	return &restartScheduler{
+		maxConcurrent:   maxConcurrent,
+		maxPerNamespace: maxPerNamespace,
+		inFlight:        make(map[string]rollout),
+		blocked:         make(map[string]blockedRestart),
+	}
+}
+
+// rolloutKey returns the key of a workload's rollout.
+func rolloutKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// admit returns true and reserves a place in the budgets for the rollout of a workload in namespace
+// if it can be started without exceeding them. A workload whose previous rollout is in progress is
+// not admitted. If namespaceLimit is greater than 0, it replaces the budget for the namespace.
+// An admitted workload must be either started or cancelled.
+func (s *restartScheduler) admit(key string, adapter WorkloadAdapter, namespace, name string, namespaceLimit int, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.inFlight[key]; ok {
+		return false
+	}
+
+	if s.maxConcurrent > 0 && len(s.inFlight) >= s.maxConcurrent {
+		return false
+	}
+
+	maxPerNamespace := s.maxPerNamespace
+	if namespaceLimit > 0 {
+		maxPerNamespace = namespaceLimit
+	}
+
+	if maxPerNamespace > 0 {
+		inNamespace := 0
+		for _, r := range s.inFlight {
+			if r.namespace == namespace {
+				inNamespace++
+			}
+		}
+		if inNamespace >= maxPerNamespace {
+			return false
+		}
+	}
+
+	s.inFlight[key] = rollout{adapter: adapter, namespace: namespace, name: name, started: now, reserved: true}
+	return true
+}
+
+// cancel releases the place reserved for a workload that was admitted but not restarted.
+func (s *restartScheduler) cancel(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.inFlight[key]; ok && r.reserved {
+		delete(s.inFlight, key)
+	}
+}
+
+// started records that the rollout of a workload was started at now, for the secrets that changed at changed.
+func (s *restartScheduler) started(key string, adapter WorkloadAdapter, namespace, name string, now, changed time.Time, secrets ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inFlight[key] = rollout{adapter: adapter, namespace: namespace, name: name, started: now, changed: changed, secrets: secrets}
+	delete(s.blocked, key)
+}
+
+// blockedByBudget records that the restart of a workload was deferred by a PodDisruptionBudget at now,
+// and returns how long its restarts have been deferred since they were first blocked.
+func (s *restartScheduler) blockedByBudget(key string, adapter WorkloadAdapter, namespace, name string, now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.blocked[key]
+	if !ok {
+		s.blocked[key] = blockedRestart{adapter: adapter, namespace: namespace, name: name, since: now}
+		return 0
+	}
+
+	return now.Sub(b.since)
+}
+
+// unblocked forgets that the restarts of a workload were deferred by a PodDisruptionBudget.
+func (s *restartScheduler) unblocked(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.blocked, key)
+}
+
+// inProgressFor returns true if a rollout started for the secret in namespace is in progress.
+func (s *restartScheduler) inProgressFor(namespace, secretName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.inFlight {
+		if r.namespace != namespace {
+			continue
+		}
+		for _, name := range r.secrets {
+			if name == secretName {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// release stops counting rollouts that have completed, whose workload no longer exists, or that
+// have been in progress longer than the rolloutTimeout at now. The rollouts that completed or
+// timed out are returned. Deferred restarts of workloads that no longer exist are forgotten.
+func (s *restartScheduler) release(reader client.Reader, now time.Time, log logr.Logger) []finishedRollout {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, b := range s.blocked {
+		err := reader.Get(context.TODO(), types.NamespacedName{Namespace: b.namespace, Name: b.name}, newWorkload(b.adapter))
+		if apierrors.IsNotFound(err) {
+			delete(s.blocked, key)
+		}
+	}
+
+	var finished []finishedRollout
+	for key, r := range s.inFlight {
+		if r.reserved {
+			// a reservation that was never started or cancelled is dropped rather than held forever.
+			if now.Sub(r.started) > rolloutTimeout {
+				delete(s.inFlight, key)
+			}
+			continue
+		}
+
+		if now.Sub(r.started) > rolloutTimeout {
+			log.Info("Workload rollout did not complete in time and no longer counts against restart budgets", "Rollout", key)
+			delete(s.inFlight, key)
+			finished = append(finished, finishedRollout{rollout: r, result: refreshTimedOut})
+			continue
+		}
+
+		workload := newWorkload(r.adapter)
+		err := reader.Get(context.TODO(), types.NamespacedName{Namespace: r.namespace, Name: r.name}, workload)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				delete(s.inFlight, key)
+				continue
+			}
+			log.Error(err, "Unable to check workload rollout", "Rollout", key)
+			continue
+		}
+
+		complete, err := r.adapter.RolloutComplete(workload)
+		if err != nil {
+			log.Error(err, "Unable to check workload rollout", "Rollout", key)
+			continue
+		}
+
+		if complete {
+			log.V(2).Info("Workload rollout completed", "Rollout", key)
+			delete(s.inFlight, key)
+			finished = append(finished, finishedRollout{rollout: r, result: refreshCompleted})
+		}
+	}
+
+	return finished
+}
+
+// blockingPodDisruptionBudget returns the name of a PodDisruptionBudget selecting pods with podLabels
+// that currently allows fewer disruptions than the unavailable pods the workload's rollout makes at
+// once, or an empty string if there is none. Restarting a workload whose pods are protected by such a
+// budget is deferred until the budget allows enough disruptions, or reported as failed once it has
+// been deferred for the podDisruptionBudgetTimeout. A rollout that makes no pods unavailable, such as
+// that of a Deployment surging new pods before removing old ones, is never blocked.
+func blockingPodDisruptionBudget(pdbs []policyv1beta1.PodDisruptionBudget, podLabels map[string]string, unavailable int) string {
+	if unavailable < 1 {
+		return ""
+	}
+
+	for _, pdb := range pdbs {
+		if pdb.Spec.Selector == nil {
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(podLabels)) {
+			continue
+		}
+
+		if int(pdb.Status.DisruptionsAllowed) < unavailable {
+			return pdb.GetName()
+		}
+	}
+
+	return ""
+}
+
+// rolloutUnavailable returns the number of pods the rollout of the workload's update strategy can make
+// unavailable at once. Workloads of kinds whose strategy is not known are assumed to replace one pod at a time.
+func rolloutUnavailable(workload *unstructured.Unstructured) (int, error) {
+	switch workload.GetKind() {
+	case "Deployment":
+		replicas, found, err := unstructured.NestedInt64(workload.Object, "spec", "replicas")
+		if err != nil {
+			return 0, err
+		}
+		if !found {
+			replicas = 1
+		}
+
+		strategy, _, err := unstructured.NestedString(workload.Object, "spec", "strategy", "type")
+		if err != nil {
+			return 0, err
+		}
+		if strategy == string(appsv1.RecreateDeploymentStrategyType) {
+			return int(replicas), nil
+		}
+
+		return scaledRolloutValue(workload, int(replicas), false, "25%", "spec", "strategy", "rollingUpdate", "maxUnavailable")
+	case "DaemonSet":
+		strategy, _, err := unstructured.NestedString(workload.Object, "spec", "updateStrategy", "type")
+		if err != nil {
+			return 0, err
+		}
+		if strategy == string(appsv1.OnDeleteDaemonSetStrategyType) {
+			return 0, nil
+		}
+
+		scheduled, _, err := unstructured.NestedInt64(workload.Object, "status", "desiredNumberScheduled")
+		if err != nil {
+			return 0, err
+		}
+
+		return scaledRolloutValue(workload, int(scheduled), true, "1", "spec", "updateStrategy", "rollingUpdate", "maxUnavailable")
+	case "StatefulSet":
+		strategy, _, err := unstructured.NestedString(workload.Object, "spec", "updateStrategy", "type")
+		if err != nil {
+			return 0, err
+		}
+		if strategy == string(appsv1.OnDeleteStatefulSetStrategyType) {
+			return 0, nil
+		}
+
+		return 1, nil
+	default:
+		return 1, nil
+	}
+}
+
+// scaledRolloutValue returns the integer or percentage of total at fields of the workload, or of
+// defaultValue if it is not set.
+func scaledRolloutValue(workload *unstructured.Unstructured, total int, roundUp bool, defaultValue string, fields ...string) (int, error) {
+	value := intstr.Parse(defaultValue)
+
+	raw, found, err := unstructured.NestedFieldNoCopy(workload.Object, fields...)
+	if err != nil {
+		return 0, err
+	}
+	if found {
+		switch v := raw.(type) {
+		case int64:
+			value = intstr.FromInt(int(v))
+		case string:
+			value = intstr.FromString(v)
+		default:
+			return 0, fmt.Errorf("%s has unexpected type %T", strings.Join(fields, "."), raw)
+		}
+	}
+
+	return intstr.GetValueFromIntOrPercent(&value, total, roundUp)
+}
@@ -0,0 +1,314 @@
+package podrefresher
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// workloadReader is a client.Reader serving unstructured workloads by namespaced name.
+type workloadReader map[client.ObjectKey]*unstructured.Unstructured
+
+func (w workloadReader) Get(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+	workload, ok := w[key]
+	if !ok {
+		return apierrors.NewNotFound(schema.GroupResource{}, key.Name)
+	}
+
+	workload.DeepCopyInto(obj.(*unstructured.Unstructured))
+	return nil
+}
+
+func (w workloadReader) List(context.Context, runtime.Object, ...client.ListOption) error {
+	return nil
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+// deploymentWithRollout returns a deployment with 2 desired replicas at generation, whose
+// status reports the observedGeneration and the number of ready replicas.
+func deploymentWithRollout(name string, generation, observedGeneration int64, ready int32) *unstructured.Unstructured {
+	deploy := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Generation: generation},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: observedGeneration,
+			Replicas:           2,
+			UpdatedReplicas:    2,
+			ReadyReplicas:      ready,
+		},
+	}
+
+	return toUnstructured(deploy)
+}
+
+var _ = Describe("Restart scheduler", func() {
+	var (
+		scheduler *restartScheduler
+		adapter   = adapterFor("Deployment")
+		now       = time.Now()
+	)
+
+	Context("When budgets are set", func() {
+		BeforeEach(func() {
+			scheduler = newRestartScheduler(2, 1)
+		})
+
+		It("Should admit one rollout per namespace", func() {
+			Expect(scheduler.admit(rolloutKey("Deployment", "a", "web"), adapter, "a", "web", 0, now)).To(BeTrue())
+			scheduler.started(rolloutKey("Deployment", "a", "web"), adapter, "a", "web", now, now)
+
+			Expect(scheduler.admit(rolloutKey("Deployment", "a", "api"), adapter, "a", "api", 0, now)).To(BeFalse())
+			Expect(scheduler.admit(rolloutKey("Deployment", "b", "web"), adapter, "b", "web", 0, now)).To(BeTrue())
+		})
+
+		It("Should reserve a place for admitted rollouts until they are cancelled", func() {
+			Expect(scheduler.admit(rolloutKey("Deployment", "a", "web"), adapter, "a", "web", 0, now)).To(BeTrue())
+			Expect(scheduler.admit(rolloutKey("Deployment", "a", "api"), adapter, "a", "api", 0, now)).To(BeFalse())
+
+			scheduler.cancel(rolloutKey("Deployment", "a", "web"))
+			Expect(scheduler.admit(rolloutKey("Deployment", "a", "api"), adapter, "a", "api", 0, now)).To(BeTrue())
+		})
+
+		It("Should not cancel rollouts that were started", func() {
+			Expect(scheduler.admit(rolloutKey("Deployment", "a", "web"), adapter, "a", "web", 0, now)).To(BeTrue())
+			scheduler.started(rolloutKey("Deployment", "a", "web"), adapter, "a", "web", now, now)
+
+			scheduler.cancel(rolloutKey("Deployment", "a", "web"))
+			Expect(scheduler.inFlight).To(HaveKey(rolloutKey("Deployment", "a", "web")))
+		})
+
+		It("Should not exceed the global budget", func() {
+			scheduler.started(rolloutKey("Deployment", "a", "web"), adapter, "a", "web", now, now)
+			scheduler.started(rolloutKey("Deployment", "b", "web"), adapter, "b", "web", now, now)
+
+			Expect(scheduler.admit(rolloutKey("Deployment", "c", "web"), adapter, "c", "web", 0, now)).To(BeFalse())
+		})
+
+		It("Should not admit a workload whose rollout is in progress", func() {
+			scheduler = newRestartScheduler(0, 0)
+			scheduler.started(rolloutKey("Deployment", "a", "web"), adapter, "a", "web", now, now)
+
+			Expect(scheduler.admit(rolloutKey("Deployment", "a", "web"), adapter, "a", "web", 0, now)).To(BeFalse())
+			Expect(scheduler.admit(rolloutKey("Deployment", "a", "api"), adapter, "a", "api", 0, now)).To(BeTrue())
+		})
+
+		It("Should report the secrets rollouts were started for", func() {
+			scheduler.started(rolloutKey("Deployment", "a", "web"), adapter, "a", "web", now, now, "tls", "ca")
+
+			Expect(scheduler.inProgressFor("a", "ca")).To(BeTrue())
+			Expect(scheduler.inProgressFor("a", "internal")).To(BeFalse())
+			Expect(scheduler.inProgressFor("b", "tls")).To(BeFalse())
+		})
+	})
+
+	Context("When budgets are unlimited", func() {
+		It("Should admit every workload whose rollout is not in progress", func() {
+			scheduler = newRestartScheduler(0, 0)
+			for _, name := range []string{"web", "api", "worker"} {
+				Expect(scheduler.admit(rolloutKey("Deployment", "a", name), adapter, "a", name, 0, now)).To(BeTrue())
+				scheduler.started(rolloutKey("Deployment", "a", name), adapter, "a", name, now, now)
+			}
+		})
+	})
+
+	Context("When rollouts are in progress", func() {
+		BeforeEach(func() {
+			scheduler = newRestartScheduler(0, 1)
+			for _, name := range []string{"complete", "progressing", "unready", "deleted", "stuck"} {
+				started := now
+				if name == "stuck" {
+					started = now.Add(-2 * rolloutTimeout)
+				}
+				scheduler.started(rolloutKey("Deployment", "ns", name), adapter, "ns", name, started, started)
+			}
+		})
+
+		It("Should release completed, deleted and timed out rollouts", func() {
+			reader := workloadReader{
+				{Namespace: "ns", Name: "complete"}:    deploymentWithRollout("complete", 2, 2, 2),
+				{Namespace: "ns", Name: "progressing"}: deploymentWithRollout("progressing", 2, 1, 2),
+				{Namespace: "ns", Name: "unready"}:     deploymentWithRollout("unready", 2, 2, 1),
+				{Namespace: "ns", Name: "stuck"}:       deploymentWithRollout("stuck", 2, 1, 0),
+			}
+			finished := scheduler.release(reader, now, logf.Log)
+
+			results := make(map[string]refreshResult)
+			for _, f := range finished {
+				results[f.name] = f.result
+			}
+			Expect(results).To(Equal(map[string]refreshResult{"complete": refreshCompleted, "stuck": refreshTimedOut}))
+
+			Expect(scheduler.inFlight).To(HaveLen(2))
+			Expect(scheduler.inFlight).To(HaveKey(rolloutKey("Deployment", "ns", "progressing")))
+			Expect(scheduler.inFlight).To(HaveKey(rolloutKey("Deployment", "ns", "unready")))
+		})
+
+		It("Should not release reservations until they time out", func() {
+			scheduler = newRestartScheduler(0, 0)
+			Expect(scheduler.admit(rolloutKey("Deployment", "ns", "complete"), adapter, "ns", "complete", 0, now)).To(BeTrue())
+			reader := workloadReader{{Namespace: "ns", Name: "complete"}: deploymentWithRollout("complete", 2, 2, 2)}
+
+			Expect(scheduler.release(reader, now, logf.Log)).To(BeEmpty())
+			Expect(scheduler.inFlight).To(HaveLen(1))
+
+			Expect(scheduler.release(reader, now.Add(2*rolloutTimeout), logf.Log)).To(BeEmpty())
+			Expect(scheduler.inFlight).To(BeEmpty())
+		})
+
+		It("Should forget blocked restarts of deleted workloads", func() {
+			scheduler.blockedByBudget(rolloutKey("Deployment", "ns", "progressing"), adapter, "ns", "progressing", now)
+			scheduler.blockedByBudget(rolloutKey("Deployment", "ns", "deleted"), adapter, "ns", "deleted", now)
+			reader := workloadReader{{Namespace: "ns", Name: "progressing"}: deploymentWithRollout("progressing", 2, 1, 2)}
+
+			scheduler.release(reader, now, logf.Log)
+			Expect(scheduler.blocked).To(HaveLen(1))
+			Expect(scheduler.blocked).To(HaveKey(rolloutKey("Deployment", "ns", "progressing")))
+		})
+	})
+
+	Context("When checking whether a rollout is complete", func() {
+		It("Should require the observed generation to match the generation", func() {
+			Expect(adapter.RolloutComplete(deploymentWithRollout("web", 2, 1, 2))).To(BeFalse())
+			Expect(adapter.RolloutComplete(deploymentWithRollout("web", 2, 2, 2))).To(BeTrue())
+		})
+
+		It("Should require every desired pod of a daemonset to be ready", func() {
+			dset := &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Status:     appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberReady: 2},
+			}
+			Expect(adapterFor("DaemonSet").RolloutComplete(toUnstructured(dset))).To(BeFalse())
+
+			dset.Status.NumberReady = 3
+			Expect(adapterFor("DaemonSet").RolloutComplete(toUnstructured(dset))).To(BeTrue())
+		})
+
+		It("Should read the observed generation of Argo Rollouts from a string", func() {
+			rollout := &unstructured.Unstructured{Object: map[string]interface{}{
+				"kind": "Rollout",
+				"status": map[string]interface{}{
+					"observedGeneration": "1",
+					"replicas":           int64(2),
+					"readyReplicas":      int64(2),
+					"updatedReplicas":    int64(2),
+				},
+			}}
+			rollout.SetGeneration(2)
+			Expect(adapterFor("Rollout").RolloutComplete(rollout)).To(BeFalse())
+
+			rollout.SetGeneration(1)
+			Expect(adapterFor("Rollout").RolloutComplete(rollout)).To(BeTrue())
+
+			Expect(unstructured.SetNestedField(rollout.Object, "5d8b9c7f4", "status", "observedGeneration")).To(Succeed())
+			Expect(adapterFor("Rollout").RolloutComplete(rollout)).To(BeTrue())
+
+			Expect(unstructured.SetNestedField(rollout.Object, int64(1), "status", "readyReplicas")).To(Succeed())
+			Expect(adapterFor("Rollout").RolloutComplete(rollout)).To(BeFalse())
+		})
+
+		It("Should treat workloads without an observed generation as complete", func() {
+			rollout := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "Rollout"}}
+			Expect(adapterFor("Rollout").RolloutComplete(rollout)).To(BeTrue())
+		})
+	})
+
+	Context("When pods are protected by PodDisruptionBudgets", func() {
+		var pdbs []policyv1beta1.PodDisruptionBudget
+
+		BeforeEach(func() {
+			pdbs = []policyv1beta1.PodDisruptionBudget{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "web"},
+					Spec:       policyv1beta1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+					Status:     policyv1beta1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "api"},
+					Spec:       policyv1beta1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}},
+					Status:     policyv1beta1.PodDisruptionBudgetStatus{DisruptionsAllowed: 1},
+				},
+			}
+		})
+
+		It("Should block restarts of pods whose budget allows too few disruptions", func() {
+			Expect(blockingPodDisruptionBudget(pdbs, map[string]string{"app": "web"}, 1)).To(Equal("web"))
+			Expect(blockingPodDisruptionBudget(pdbs, map[string]string{"app": "api"}, 2)).To(Equal("api"))
+		})
+
+		It("Should not block restarts of pods whose budget allows disruptions", func() {
+			Expect(blockingPodDisruptionBudget(pdbs, map[string]string{"app": "api"}, 1)).To(BeEmpty())
+		})
+
+		It("Should not block restarts of pods without a budget", func() {
+			Expect(blockingPodDisruptionBudget(pdbs, map[string]string{"app": "worker"}, 1)).To(BeEmpty())
+		})
+
+		It("Should not block rollouts that make no pods unavailable", func() {
+			Expect(blockingPodDisruptionBudget(pdbs, map[string]string{"app": "web"}, 0)).To(BeEmpty())
+		})
+
+		It("Should not count a single replica deployment surging a new pod as a disruption", func() {
+			deploy := &appsv1.Deployment{TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}, Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(1)}}
+			Expect(rolloutUnavailable(toUnstructured(deploy))).To(Equal(0))
+
+			deploy.Spec.Replicas = int32Ptr(8)
+			Expect(rolloutUnavailable(toUnstructured(deploy))).To(Equal(2))
+
+			maxUnavailable := intstr.FromInt(3)
+			deploy.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RollingUpdateDeploymentStrategyType, RollingUpdate: &appsv1.RollingUpdateDeployment{MaxUnavailable: &maxUnavailable}}
+			Expect(rolloutUnavailable(toUnstructured(deploy))).To(Equal(3))
+
+			deploy.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+			Expect(rolloutUnavailable(toUnstructured(deploy))).To(Equal(8))
+		})
+
+		It("Should count the pods other workloads replace at once", func() {
+			sset := &appsv1.StatefulSet{TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"}}
+			Expect(rolloutUnavailable(toUnstructured(sset))).To(Equal(1))
+
+			sset.Spec.UpdateStrategy.Type = appsv1.OnDeleteStatefulSetStrategyType
+			Expect(rolloutUnavailable(toUnstructured(sset))).To(Equal(0))
+
+			dset := &appsv1.DaemonSet{TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"}, Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3}}
+			Expect(rolloutUnavailable(toUnstructured(dset))).To(Equal(1))
+
+			rollout := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "Rollout"}}
+			Expect(rolloutUnavailable(rollout)).To(Equal(1))
+		})
+
+		It("Should track how long restarts have been blocked until they are unblocked or started", func() {
+			scheduler := newRestartScheduler(0, 0)
+			key := rolloutKey("Deployment", "ns", "web")
+			now := time.Now()
+
+			Expect(scheduler.blockedByBudget(key, adapter, "ns", "web", now)).To(BeZero())
+			Expect(scheduler.blockedByBudget(key, adapter, "ns", "web", now.Add(podDisruptionBudgetTimeout))).To(Equal(podDisruptionBudgetTimeout))
+
+			scheduler.unblocked(key)
+			Expect(scheduler.blockedByBudget(key, adapter, "ns", "web", now.Add(2*podDisruptionBudgetTimeout))).To(BeZero())
+
+			scheduler.started(key, adapterFor("Deployment"), "ns", "web", now, now)
+			Expect(scheduler.blocked).To(BeEmpty())
+		})
+
+		It("Should describe restarts that stayed blocked", func() {
+			err := &blockedByBudgetError{podDisruptionBudget: "web", blockedFor: 61*time.Minute + 500*time.Millisecond}
+			Expect(err.Error()).To(Equal("PodDisruptionBudget web has allowed too few disruptions for 1h1m1s"))
+		})
+	})
+})
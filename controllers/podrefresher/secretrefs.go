@@ -0,0 +1,117 @@
+package podrefresher
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// usesSecret returns true if podspec references a secret whose name matches the secret
+// parameter, and false if it does not. See secretsReferencedBy for the references considered.
+func usesSecret(secret *corev1.Secret, podspec corev1.PodSpec) bool {
+	_, ok := secretsReferencedBy(podspec)[secret.GetName()]
+	return ok
+}
+
+// secretsReferencedBy returns the names of all secrets referenced by podspec, which includes
+// secret volumes, secrets projected into volumes, and secrets consumed as environment variables
+// through env or envFrom by containers, init containers, and ephemeral containers.
+func secretsReferencedBy(podspec corev1.PodSpec) map[string]struct{} {
+	names := make(map[string]struct{})
+
+	for _, vol := range podspec.Volumes {
+		// VolumeSource.Secret is a pointer, if it's uninitialized it should be nil
+		if secretRef := vol.VolumeSource.Secret; secretRef != nil {
+			names[secretRef.SecretName] = struct{}{}
+		}
+
+		if projected := vol.VolumeSource.Projected; projected != nil {
+			for _, source := range projected.Sources {
+				if source.Secret != nil {
+					names[source.Secret.Name] = struct{}{}
+				}
+			}
+		}
+	}
+
+	for _, container := range podspec.InitContainers {
+		addEnvSecretReferences(names, container.Env, container.EnvFrom)
+	}
+
+	for _, container := range podspec.Containers {
+		addEnvSecretReferences(names, container.Env, container.EnvFrom)
+	}
+
+	for _, container := range podspec.EphemeralContainers {
+		addEnvSecretReferences(names, container.Env, container.EnvFrom)
+	}
+
+	// an empty name is never a valid reference.
+	delete(names, "")
+	return names
+}
+
+// addEnvSecretReferences adds the names of secrets referenced by env and envFrom to names.
+func addEnvSecretReferences(names map[string]struct{}, env []corev1.EnvVar, envFrom []corev1.EnvFromSource) {
+	for _, e := range env {
+		if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
+			names[e.ValueFrom.SecretKeyRef.Name] = struct{}{}
+		}
+	}
+
+	for _, e := range envFrom {
+		if e.SecretRef != nil {
+			names[e.SecretRef.Name] = struct{}{}
+		}
+	}
+}
+
+// configMapsReferencedBy returns the names of all ConfigMaps referenced by podspec, which includes
+// ConfigMap volumes, ConfigMaps projected into volumes, and ConfigMaps consumed as environment
+// variables through env or envFrom by containers, init containers, and ephemeral containers.
+func configMapsReferencedBy(podspec corev1.PodSpec) map[string]struct{} {
+	names := make(map[string]struct{})
+
+	for _, vol := range podspec.Volumes {
+		if configMapRef := vol.VolumeSource.ConfigMap; configMapRef != nil {
+			names[configMapRef.Name] = struct{}{}
+		}
+
+		if projected := vol.VolumeSource.Projected; projected != nil {
+			for _, source := range projected.Sources {
+				if source.ConfigMap != nil {
+					names[source.ConfigMap.Name] = struct{}{}
+				}
+			}
+		}
+	}
+
+	for _, container := range podspec.InitContainers {
+		addEnvConfigMapReferences(names, container.Env, container.EnvFrom)
+	}
+
+	for _, container := range podspec.Containers {
+		addEnvConfigMapReferences(names, container.Env, container.EnvFrom)
+	}
+
+	for _, container := range podspec.EphemeralContainers {
+		addEnvConfigMapReferences(names, container.Env, container.EnvFrom)
+	}
+
+	// an empty name is never a valid reference.
+	delete(names, "")
+	return names
+}
+
+// addEnvConfigMapReferences adds the names of ConfigMaps referenced by env and envFrom to names.
+func addEnvConfigMapReferences(names map[string]struct{}, env []corev1.EnvVar, envFrom []corev1.EnvFromSource) {
+	for _, e := range env {
+		if e.ValueFrom != nil && e.ValueFrom.ConfigMapKeyRef != nil {
+			names[e.ValueFrom.ConfigMapKeyRef.Name] = struct{}{}
+		}
+	}
+
+	for _, e := range envFrom {
+		if e.ConfigMapRef != nil {
+			names[e.ConfigMapRef.Name] = struct{}{}
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package podrefresher
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Secret reference discovery", func() {
+	var (
+		secret  = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "tls-secret"}}
+		podspec corev1.PodSpec
+	)
+
+	BeforeEach(func() {
+		podspec = corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "web"}},
+		}
+	})
+
+	Context("When the pod does not reference the secret", func() {
+		It("Should not report the secret as used", func() {
+			podspec.Volumes = []corev1.Volume{
+				{
+					Name: "other",
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{SecretName: "other-secret"},
+					},
+				},
+				{
+					Name: "config",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: secret.GetName()},
+						},
+					},
+				},
+			}
+			Expect(usesSecret(secret, podspec)).To(BeFalse())
+		})
+	})
+
+	Context("When the secret is mounted as a volume", func() {
+		It("Should report the secret as used", func() {
+			podspec.Volumes = []corev1.Volume{
+				{
+					Name: "tls",
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{SecretName: secret.GetName()},
+					},
+				},
+			}
+			Expect(usesSecret(secret, podspec)).To(BeTrue())
+		})
+	})
+
+	Context("When the secret is a projected volume source", func() {
+		It("Should report the secret as used", func() {
+			podspec.Volumes = []corev1.Volume{
+				{
+					Name: "bundle",
+					VolumeSource: corev1.VolumeSource{
+						Projected: &corev1.ProjectedVolumeSource{
+							Sources: []corev1.VolumeProjection{
+								{
+									ConfigMap: &corev1.ConfigMapProjection{
+										LocalObjectReference: corev1.LocalObjectReference{Name: "ca-bundle"},
+									},
+								},
+								{
+									Secret: &corev1.SecretProjection{
+										LocalObjectReference: corev1.LocalObjectReference{Name: secret.GetName()},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(usesSecret(secret, podspec)).To(BeTrue())
+		})
+	})
+
+	Context("When a container consumes a secret key with env", func() {
+		It("Should report the secret as used", func() {
+			podspec.Containers[0].Env = []corev1.EnvVar{
+				{Name: "PLAIN", Value: "value"},
+				{
+					Name: "TLS_KEY",
+					ValueFrom: &corev1.EnvVarSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: secret.GetName()},
+							Key:                  "tls.key",
+						},
+					},
+				},
+			}
+			Expect(usesSecret(secret, podspec)).To(BeTrue())
+		})
+	})
+
+	Context("When a container consumes a secret with envFrom", func() {
+		It("Should report the secret as used", func() {
+			podspec.Containers[0].EnvFrom = []corev1.EnvFromSource{
+				{
+					SecretRef: &corev1.SecretEnvSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: secret.GetName()},
+					},
+				},
+			}
+			Expect(usesSecret(secret, podspec)).To(BeTrue())
+		})
+	})
+
+	Context("When only an init container consumes the secret", func() {
+		It("Should report the secret as used with env", func() {
+			podspec.InitContainers = []corev1.Container{
+				{
+					Name: "init",
+					Env: []corev1.EnvVar{
+						{
+							Name: "TLS_CRT",
+							ValueFrom: &corev1.EnvVarSource{
+								SecretKeyRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: secret.GetName()},
+									Key:                  "tls.crt",
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(usesSecret(secret, podspec)).To(BeTrue())
+		})
+
+		It("Should report the secret as used with envFrom", func() {
+			podspec.InitContainers = []corev1.Container{
+				{
+					Name: "init",
+					EnvFrom: []corev1.EnvFromSource{
+						{
+							SecretRef: &corev1.SecretEnvSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: secret.GetName()},
+							},
+						},
+					},
+				},
+			}
+			Expect(usesSecret(secret, podspec)).To(BeTrue())
+		})
+	})
+
+	Context("When a pod references several secrets", func() {
+		It("Should return every referenced secret", func() {
+			podspec.Volumes = []corev1.Volume{
+				{
+					Name: "tls",
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{SecretName: "volume-secret"},
+					},
+				},
+			}
+			podspec.Containers[0].EnvFrom = []corev1.EnvFromSource{
+				{
+					SecretRef: &corev1.SecretEnvSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "envfrom-secret"},
+					},
+				},
+			}
+			podspec.InitContainers = []corev1.Container{
+				{
+					Name: "init",
+					Env: []corev1.EnvVar{
+						{
+							Name: "TOKEN",
+							ValueFrom: &corev1.EnvVarSource{
+								SecretKeyRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "env-secret"},
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(secretsReferencedBy(podspec)).To(And(
+				HaveLen(3),
+				HaveKey("volume-secret"),
+				HaveKey("envfrom-secret"),
+				HaveKey("env-secret"),
+			))
+		})
+	})
+})
@@ -0,0 +1,198 @@
+package podrefresher
+
+import (
+	"context"
+	"sort"
+
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+const (
+	// refreshSourceLabel selects a secret or ConfigMap as a source of certificates whose changes refresh the
+	// workloads using it when set to true, for secrets that are not issued by cert-manager and for trust bundles.
+	refreshSourceLabel string = "certmanagerdeployment.redhat.io/refresh-source"
+
+	// sourceKindSecret is the kind of refresh sources that are secrets.
+	sourceKindSecret = "Secret"
+	// sourceKindConfigMap is the kind of refresh sources that are ConfigMaps.
+	sourceKindConfigMap = "ConfigMap"
+
+	// configMapKeyPrefix prefixes the names of ConfigMaps in the keys sources are recorded under. Secret
+	// names can't contain underscores, so ConfigMaps never share a key with a secret of the same name.
+	configMapKeyPrefix = "configmap_"
+)
+
+// refreshSource is a secret or ConfigMap whose changes refresh the workloads using it.
+type refreshSource struct {
+	metav1.Object
+	// kind is sourceKindSecret or sourceKindConfigMap.
+	kind string
+	// data is the contents of the source.
+	data map[string][]byte
+	// secret is the source if it is a secret, and nil otherwise.
+	secret *corev1.Secret
+}
+
+// secretSource returns the secret as a refreshSource.
+func secretSource(secret *corev1.Secret) refreshSource {
+	return refreshSource{Object: secret, kind: sourceKindSecret, data: secret.Data, secret: secret}
+}
+
+// configMapSource returns the ConfigMap as a refreshSource, with its data and binary data merged.
+func configMapSource(configMap *corev1.ConfigMap) refreshSource {
+	data := make(map[string][]byte, len(configMap.Data)+len(configMap.BinaryData))
+	for key, value := range configMap.Data {
+		data[key] = []byte(value)
+	}
+	for key, value := range configMap.BinaryData {
+		data[key] = value
+	}
+
+	return refreshSource{Object: configMap, kind: sourceKindConfigMap, data: data}
+}
+
+// key returns the name the source is recorded under in workload annotations, retries, metrics and
+// the refresh history. Secrets are recorded under their name for compatibility with workloads
+// restarted before ConfigMaps were supported.
+func (s refreshSource) key() string {
+	if s.kind == sourceKindConfigMap {
+		return configMapKeyPrefix + s.GetName()
+	}
+
+	return s.GetName()
+}
+
+// referenceIndex returns the cache field index mapping sources of this kind to the workloads using them.
+func (s refreshSource) referenceIndex() string {
+	if s.kind == sourceKindConfigMap {
+		return configMapReferenceIndex
+	}
+
+	return secretReferenceIndex
+}
+
+// usedBy returns true if podspec references the source.
+func (s refreshSource) usedBy(podspec corev1.PodSpec) bool {
+	if s.kind == sourceKindConfigMap {
+		_, ok := configMapsReferencedBy(podspec)[s.GetName()]
+		return ok
+	}
+
+	return usesSecret(s.secret, podspec)
+}
+
+// policySubject returns the object matched against the secret selectors of policies, which is nil
+// for ConfigMaps as secret selectors don't apply to them.
+func (s refreshSource) policySubject() metav1.Object {
+	if s.secret == nil {
+		return nil
+	}
+
+	return s.secret
+}
+
+// fingerprint returns the fingerprint of the keys of the source. ConfigMaps are trust bundles whose
+// keys vary, so all of their keys are fingerprinted. Secrets that have none of the keys, such as TLS
+// secrets that are not issued by cert-manager, also have all of their keys fingerprinted.
+func (s refreshSource) fingerprint(keys []string) string {
+	if s.kind == sourceKindConfigMap || !hasAnyKey(s.data, keys) {
+		keys = make([]string, 0, len(s.data))
+		for key := range s.data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+
+	return dataFingerprint(s.data, keys)
+}
+
+// hasAnyKey returns true if data has any of the keys.
+func hasAnyKey(data map[string][]byte, keys []string) bool {
+	for _, key := range keys {
+		if _, ok := data[key]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isRefreshSource returns true if changes to the secret or ConfigMap obj of the kind refresh the
+// workloads using it. Secrets issued by cert-manager are always sources. Other secrets and ConfigMaps
+// are sources if they have the refreshSourceLabel, or are selected by a policy in their namespace. Only
+// policies with a secret selector select secrets that are not issued by cert-manager, and only policies
+// with a ConfigMap selector select ConfigMaps.
+func isRefreshSource(kind string, obj metav1.Object, policies []operatorsv1alpha1.PodRefreshPolicy) bool {
+	if _, isCertManagerIssued := obj.GetAnnotations()[issuerKindAnnotation]; isCertManagerIssued && kind == sourceKindSecret {
+		return true
+	}
+
+	if obj.GetLabels()[refreshSourceLabel] == "true" {
+		return true
+	}
+
+	for _, policy := range policies {
+		switch kind {
+		case sourceKindSecret:
+			selector := policy.Spec.SecretSelector
+			if len(selector.MatchLabels) > 0 || len(selector.MatchExpressions) > 0 {
+				if selects(selector, obj) {
+					return true
+				}
+			}
+		case sourceKindConfigMap:
+			if policy.Spec.ConfigMapSelector != nil && selects(*policy.Spec.ConfigMapSelector, obj) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// sourceSelected returns true if changes to the secret or ConfigMap obj of the kind refresh the
+// workloads using it, reading the policies in its namespace with the reader.
+func sourceSelected(reader client.Reader, kind string, obj metav1.Object) (bool, error) {
+	policies := operatorsv1alpha1.PodRefreshPolicyList{}
+	if err := reader.List(context.TODO(), &policies, client.InNamespace(obj.GetNamespace())); err != nil {
+		return false, err
+	}
+
+	return isRefreshSource(kind, obj, policies.Items), nil
+}
+
+// refreshSourcePredicate implements a predicate passing create and update events of secrets or
+// ConfigMaps that are refresh sources. Deletes and Generics should not make it to the work queue.
+type refreshSourcePredicate struct {
+	reader client.Reader
+	kind   string
+}
+
+// selected returns true if obj is a refresh source, and false if it isn't or the policies selecting
+// sources could not be read.
+func (p refreshSourcePredicate) selected(obj metav1.Object) bool {
+	selected, err := sourceSelected(p.reader, p.kind, obj)
+	return err == nil && selected
+}
+
+// Update implements UpdateEvent filter for validating that the updated object is a refresh source.
+func (p refreshSourcePredicate) Update(e event.UpdateEvent) bool {
+	return p.selected(e.MetaNew)
+}
+
+// Create implements CreateEvent filter for validating that the created object is a refresh source.
+func (p refreshSourcePredicate) Create(e event.CreateEvent) bool {
+	return p.selected(e.Meta)
+}
+
+func (refreshSourcePredicate) Delete(e event.DeleteEvent) bool {
+	return false
+}
+
+func (refreshSourcePredicate) Generic(e event.GenericEvent) bool {
+	return false
+}
@@ -0,0 +1,138 @@
+package podrefresher
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+)
+
+var _ = Describe("Refresh sources", func() {
+	var (
+		issued    = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "issued", Annotations: map[string]string{issuerKindAnnotation: "Issuer"}}}
+		vault     = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "vault", Labels: map[string]string{"issuer": "vault"}}}
+		labeled   = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "labeled", Labels: map[string]string{refreshSourceLabel: "true"}}}
+		bundle    = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "bundle", Labels: map[string]string{"trust": "bundle"}}}
+		allIssued = operatorsv1alpha1.PodRefreshPolicy{}
+	)
+
+	Context("When selecting secrets", func() {
+		It("Should select secrets issued by cert-manager", func() {
+			Expect(isRefreshSource(sourceKindSecret, issued, nil)).To(BeTrue())
+		})
+
+		It("Should select secrets with the refresh source label", func() {
+			Expect(isRefreshSource(sourceKindSecret, labeled, nil)).To(BeTrue())
+		})
+
+		It("Should select other secrets only with a policy's secret selector", func() {
+			Expect(isRefreshSource(sourceKindSecret, vault, []operatorsv1alpha1.PodRefreshPolicy{allIssued})).To(BeFalse())
+
+			policy := operatorsv1alpha1.PodRefreshPolicy{Spec: operatorsv1alpha1.PodRefreshPolicySpec{
+				SecretSelector: metav1.LabelSelector{MatchLabels: map[string]string{"issuer": "vault"}},
+			}}
+			Expect(isRefreshSource(sourceKindSecret, vault, []operatorsv1alpha1.PodRefreshPolicy{policy})).To(BeTrue())
+		})
+	})
+
+	Context("When selecting ConfigMaps", func() {
+		It("Should not select ConfigMaps by default", func() {
+			Expect(isRefreshSource(sourceKindConfigMap, bundle, []operatorsv1alpha1.PodRefreshPolicy{allIssued})).To(BeFalse())
+		})
+
+		It("Should not treat the cert-manager annotation as selecting ConfigMaps", func() {
+			annotated := bundle.DeepCopy()
+			annotated.SetAnnotations(map[string]string{issuerKindAnnotation: "Issuer"})
+			Expect(isRefreshSource(sourceKindConfigMap, annotated, nil)).To(BeFalse())
+		})
+
+		It("Should select ConfigMaps with a policy's ConfigMap selector", func() {
+			policy := operatorsv1alpha1.PodRefreshPolicy{Spec: operatorsv1alpha1.PodRefreshPolicySpec{
+				ConfigMapSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"trust": "bundle"}},
+			}}
+			Expect(isRefreshSource(sourceKindConfigMap, bundle, []operatorsv1alpha1.PodRefreshPolicy{policy})).To(BeTrue())
+		})
+	})
+
+	Context("When recording sources", func() {
+		It("Should keep secrets and ConfigMaps with the same name apart", func() {
+			secret := secretSource(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "ca"}})
+			configMap := configMapSource(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "ca"}})
+			Expect(secret.key()).To(Equal("ca"))
+			Expect(configMap.key()).To(Equal("configmap_ca"))
+			Expect(secret.referenceIndex()).To(Equal(secretReferenceIndex))
+			Expect(configMap.referenceIndex()).To(Equal(configMapReferenceIndex))
+		})
+	})
+
+	Context("When fingerprinting sources", func() {
+		It("Should fingerprint every key of a ConfigMap", func() {
+			configMap := &corev1.ConfigMap{
+				Data:       map[string]string{"ca-bundle.crt": "bundle"},
+				BinaryData: map[string][]byte{"extra.der": []byte("der")},
+			}
+			before := configMapSource(configMap).fingerprint(DefaultFingerprintKeys)
+
+			configMap.BinaryData["extra.der"] = []byte("rotated")
+			Expect(configMapSource(configMap).fingerprint(DefaultFingerprintKeys)).ToNot(Equal(before))
+		})
+
+		It("Should fingerprint every key of a secret without the fingerprinted keys", func() {
+			secret := &corev1.Secret{Data: map[string][]byte{"certificate": []byte("a")}}
+			before := secretSource(secret).fingerprint(DefaultFingerprintKeys)
+
+			secret.Data["certificate"] = []byte("b")
+			Expect(secretSource(secret).fingerprint(DefaultFingerprintKeys)).ToNot(Equal(before))
+		})
+
+		It("Should only fingerprint the keys of a secret with them", func() {
+			secret := &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: []byte("a"), "other": []byte("a")}}
+			Expect(secretSource(secret).fingerprint(DefaultFingerprintKeys)).To(Equal(dataFingerprint(secret.Data, DefaultFingerprintKeys)))
+		})
+	})
+
+	Context("When finding the workloads using a ConfigMap", func() {
+		var deploy *appsv1.Deployment
+
+		BeforeEach(func() {
+			deploy = deploymentUsingSecrets("ns", "web", "tls")
+			deploy.Spec.Template.Spec.Volumes = append(deploy.Spec.Template.Spec.Volumes, corev1.Volume{
+				Name: "bundle",
+				VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+					{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "bundle"}}},
+				}}},
+			})
+			deploy.Spec.Template.Spec.Containers = []corev1.Container{{
+				Name: "web",
+				Env: []corev1.EnvVar{{
+					Name: "CA",
+					ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "ca"},
+					}},
+				}},
+			}}
+		})
+
+		It("Should find ConfigMaps referenced by volumes and environment variables", func() {
+			Expect(configMapsReferencedBy(deploy.Spec.Template.Spec)).To(Equal(map[string]struct{}{"bundle": {}, "ca": {}}))
+			Expect(configMapSource(bundle).usedBy(deploy.Spec.Template.Spec)).To(BeTrue())
+		})
+
+		It("Should not confuse ConfigMaps with secrets of the same name", func() {
+			Expect(configMapSource(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "tls"}}).usedBy(deploy.Spec.Template.Spec)).To(BeFalse())
+			Expect(indexConfigMapReferences(adapterFor("Deployment"))(toUnstructured(deploy))).To(Equal([]string{"bundle", "ca"}))
+		})
+
+		It("Should request a reconcile for each referenced ConfigMap", func() {
+			obj := toUnstructured(deploy)
+			reqs := configMapsForWorkload(adapterFor("Deployment"))(handler.MapObject{Meta: obj, Object: obj})
+			Expect(reqs).To(HaveLen(2))
+			Expect(reqs[0].NamespacedName).To(Equal(types.NamespacedName{Namespace: "ns", Name: "bundle"}))
+		})
+	})
+})
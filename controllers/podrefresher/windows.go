@@ -0,0 +1,208 @@
+package podrefresher
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// restartWindowAnnotation is a cron expression for the start of the windows in which a workload
+	// can be restarted. It can be set on the workload, or on its namespace.
+	restartWindowAnnotation string = "certmanagerdeployment.redhat.io/restart-window"
+	// restartWindowDurationAnnotation is the duration of each restart window. Defaults to defaultRestartWindowDuration.
+	restartWindowDurationAnnotation string = "certmanagerdeployment.redhat.io/restart-window-duration"
+	// restartDebounceAnnotation is how long to wait after a secret used by a workload changes before
+	// restarting the workload, so that secrets changing together cause a single restart. It can be
+	// set on the workload, or on its namespace.
+	restartDebounceAnnotation string = "certmanagerdeployment.redhat.io/restart-debounce"
+	// pendingRestartAnnotation records the changed secrets a workload is waiting to be restarted for,
+	// and when the restart is scheduled.
+	pendingRestartAnnotation string = "certmanagerdeployment.redhat.io/pending-restart"
+
+	// defaultRestartWindowDuration is the duration of each restart window if it is not set.
+	defaultRestartWindowDuration = time.Hour
+)
+
+// restartPolicy is when a workload can be restarted.
+type restartPolicy struct {
+	// window is the schedule of the start of each restart window. If nil, the workload can be restarted at any time.
+	window cron.Schedule
+	// windowDuration is how long each restart window lasts.
+	windowDuration time.Duration
+	// debounce is how long to wait after a secret changes before restarting.
+	debounce time.Duration
+}
+
+// restartPolicyFor returns the restart policy for a workload from the annotations on the first of
+// objs that has each annotation, falling back to the debounce. objs are typically the workload
+// followed by its namespace.
+func restartPolicyFor(debounce time.Duration, objs ...metav1.Object) (restartPolicy, error) {
+	policy := restartPolicy{windowDuration: defaultRestartWindowDuration, debounce: debounce}
+
+	if val, ok := annotationFor(restartWindowAnnotation, objs...); ok {
+		window, err := cron.ParseStandard(val)
+		if err != nil {
+			return policy, fmt.Errorf("invalid %s annotation %q: %s", restartWindowAnnotation, val, err)
+		}
+		policy.window = window
+	}
+
+	if val, ok := annotationFor(restartWindowDurationAnnotation, objs...); ok {
+		d, err := time.ParseDuration(val)
+		if err != nil || d <= 0 {
+			return policy, fmt.Errorf("invalid %s annotation %q", restartWindowDurationAnnotation, val)
+		}
+		policy.windowDuration = d
+	}
+
+	if val, ok := annotationFor(restartDebounceAnnotation, objs...); ok {
+		d, err := time.ParseDuration(val)
+		if err != nil || d < 0 {
+			return policy, fmt.Errorf("invalid %s annotation %q", restartDebounceAnnotation, val)
+		}
+		policy.debounce = d
+	}
+
+	return policy, nil
+}
+
+// annotationFor returns the value of the annotation from the first object that has it.
+func annotationFor(annotation string, objs ...metav1.Object) (string, bool) {
+	for _, obj := range objs {
+		if obj == nil {
+			continue
+		}
+
+		if val, ok := obj.GetAnnotations()[annotation]; ok {
+			return val, true
+		}
+	}
+
+	return "", false
+}
+
+// nextAllowed returns t if t is in a restart window, or otherwise the start of the next restart window.
+func (p restartPolicy) nextAllowed(t time.Time) time.Time {
+	if p.window == nil {
+		return t
+	}
+
+	// the window containing t is the first window starting after t - windowDuration, if it starts by t.
+	if start := p.window.Next(t.Add(-p.windowDuration)); !start.After(t) {
+		return t
+	}
+
+	return p.window.Next(t)
+}
+
+// pendingRestart is a restart of a workload that is waiting for its debounce to pass or for its restart window.
+type pendingRestart struct {
+	// Secrets are the changed secrets the workload will be restarted for, keyed by name.
+	Secrets map[string]pendingSecret `json:"secrets"`
+	// DebounceUntil is when the debounce following the last secret change ends.
+	DebounceUntil metav1.Time `json:"debounceUntil"`
+	// RestartAt is when the workload is scheduled to be restarted.
+	RestartAt metav1.Time `json:"restartAt"`
+}
+
+// pendingSecret is a secret a workload is waiting to be restarted for.
+type pendingSecret struct {
+	ResourceVersion string `json:"resourceVersion"`
+	Fingerprint     string `json:"fingerprint"`
+	// UpdatedAt is when the secret was updated.
+	UpdatedAt *metav1.Time `json:"updatedAt,omitempty"`
+}
+
+// getPendingRestart returns the pending restart recorded in the annotations. An empty
+// pending restart is returned if none is recorded or the annotation is malformed.
+func getPendingRestart(a map[string]string) pendingRestart {
+	var pending pendingRestart
+	if val, ok := a[pendingRestartAnnotation]; ok {
+		_ = json.Unmarshal([]byte(val), &pending)
+	}
+
+	if pending.Secrets == nil {
+		pending.Secrets = make(map[string]pendingSecret)
+	}
+
+	return pending
+}
+
+// setPendingRestart records the pending restart in the annotations.
+func setPendingRestart(pending pendingRestart, a map[string]string) {
+	d, _ := json.Marshal(pending)
+	a[pendingRestartAnnotation] = string(d)
+}
+
+// schedule adds the secret to the pending restart at now, and returns when the workload should be restarted
+// and true if the pending restart changed. Adding a secret that was not already pending with the same
+// fingerprint extends the debounce. The restart is scheduled for the end of the debounce, or the start of
+// the next restart window after it.
+func (p *pendingRestart) schedule(secretName, resourceVersion, fingerprint string, policy restartPolicy, now time.Time) (time.Time, bool) {
+	changed := false
+	if pending, ok := p.Secrets[secretName]; !ok || pending.Fingerprint != fingerprint {
+		p.Secrets[secretName] = pendingSecret{ResourceVersion: resourceVersion, Fingerprint: fingerprint}
+		p.DebounceUntil = metav1.NewTime(now.Add(policy.debounce))
+		changed = true
+	}
+
+	restartAt := now
+	if p.DebounceUntil.After(now) {
+		restartAt = p.DebounceUntil.Time
+	}
+	restartAt = policy.nextAllowed(restartAt)
+
+	if scheduled := ceilSecond(restartAt); !scheduled.Equal(p.RestartAt.Time) {
+		p.RestartAt = metav1.NewTime(scheduled)
+		changed = true
+	}
+
+	return restartAt, changed
+}
+
+// recordUpdate records when the pending secret was updated, unless it is already recorded.
+func (p *pendingRestart) recordUpdate(secretName string, updated time.Time) {
+	if pending, ok := p.Secrets[secretName]; ok && pending.UpdatedAt == nil {
+		t := metav1.NewTime(updated)
+		pending.UpdatedAt = &t
+		p.Secrets[secretName] = pending
+	}
+}
+
+// firstUpdate returns when the first of the pending secrets was updated, or now if no update times
+// are recorded.
+func (p *pendingRestart) firstUpdate(now time.Time) time.Time {
+	first := now
+	for _, pending := range p.Secrets {
+		if pending.UpdatedAt != nil && pending.UpdatedAt.Time.Before(first) {
+			first = pending.UpdatedAt.Time
+		}
+	}
+
+	return first
+}
+
+// secretNames returns the names of the pending secrets in order.
+func (p *pendingRestart) secretNames() []string {
+	names := make([]string, 0, len(p.Secrets))
+	for name := range p.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ceilSecond rounds t up to the second, as metav1.Time is serialized with second precision.
+func ceilSecond(t time.Time) time.Time {
+	if truncated := t.Truncate(time.Second); truncated.Before(t) {
+		return truncated.Add(time.Second)
+	}
+
+	return t
+}
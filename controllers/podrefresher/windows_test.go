@@ -0,0 +1,145 @@
+package podrefresher
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Restart windows and debounce", func() {
+	var (
+		workload  *metav1.ObjectMeta
+		namespace *metav1.ObjectMeta
+		// 2020-11-02 is a Monday.
+		monday = time.Date(2020, 11, 2, 1, 30, 0, 0, time.Local)
+	)
+
+	BeforeEach(func() {
+		workload = &metav1.ObjectMeta{Annotations: map[string]string{}}
+		namespace = &metav1.ObjectMeta{Annotations: map[string]string{}}
+	})
+
+	Context("When no restart policy is annotated", func() {
+		It("Should allow restarts at any time with the default debounce", func() {
+			policy, err := restartPolicyFor(time.Minute, workload, namespace)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policy.debounce).To(Equal(time.Minute))
+			Expect(policy.nextAllowed(monday)).To(Equal(monday))
+		})
+	})
+
+	Context("When a restart window is annotated", func() {
+		BeforeEach(func() {
+			namespace.Annotations[restartWindowAnnotation] = "0 2 * * *"
+		})
+
+		It("Should allow restarts only during the window", func() {
+			policy, err := restartPolicyFor(0, workload, namespace)
+			Expect(err).ToNot(HaveOccurred())
+
+			twoAM := time.Date(2020, 11, 2, 2, 0, 0, 0, time.Local)
+			Expect(policy.nextAllowed(monday)).To(Equal(twoAM))
+			Expect(policy.nextAllowed(twoAM.Add(30 * time.Minute))).To(Equal(twoAM.Add(30 * time.Minute)))
+			Expect(policy.nextAllowed(twoAM.Add(time.Hour))).To(Equal(twoAM.Add(24 * time.Hour)))
+		})
+
+		It("Should use the annotated window duration", func() {
+			namespace.Annotations[restartWindowDurationAnnotation] = "3h"
+			policy, err := restartPolicyFor(0, workload, namespace)
+			Expect(err).ToNot(HaveOccurred())
+
+			fourAM := time.Date(2020, 11, 2, 4, 0, 0, 0, time.Local)
+			Expect(policy.nextAllowed(fourAM)).To(Equal(fourAM))
+		})
+
+		It("Should prefer the window annotated on the workload", func() {
+			workload.Annotations[restartWindowAnnotation] = "0 1 * * *"
+			policy, err := restartPolicyFor(0, workload, namespace)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policy.nextAllowed(monday)).To(Equal(monday))
+		})
+
+		It("Should reject invalid annotations", func() {
+			workload.Annotations[restartWindowAnnotation] = "every night"
+			_, err := restartPolicyFor(0, workload, namespace)
+			Expect(err).To(HaveOccurred())
+
+			workload.Annotations[restartWindowAnnotation] = "0 1 * * *"
+			workload.Annotations[restartDebounceAnnotation] = "soon"
+			_, err = restartPolicyFor(0, workload, namespace)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When secrets change while a restart is pending", func() {
+		var (
+			pending pendingRestart
+			policy  restartPolicy
+		)
+
+		BeforeEach(func() {
+			pending = getPendingRestart(nil)
+			policy = restartPolicy{debounce: 5 * time.Minute}
+		})
+
+		It("Should restart immediately without a debounce or window", func() {
+			restartAt, _ := pending.schedule("tls", "1", "abc", restartPolicy{}, monday)
+			Expect(restartAt).To(Equal(monday))
+		})
+
+		It("Should extend the debounce for each changed secret", func() {
+			restartAt, changed := pending.schedule("tls", "1", "abc", policy, monday)
+			Expect(changed).To(BeTrue())
+			Expect(restartAt).To(Equal(monday.Add(5 * time.Minute)))
+
+			restartAt, changed = pending.schedule("ca", "1", "def", policy, monday.Add(2*time.Minute))
+			Expect(changed).To(BeTrue())
+			Expect(restartAt).To(Equal(monday.Add(7 * time.Minute)))
+			Expect(pending.Secrets).To(HaveLen(2))
+		})
+
+		It("Should not extend the debounce for a secret that is already pending", func() {
+			pending.schedule("tls", "1", "abc", policy, monday)
+			restartAt, changed := pending.schedule("tls", "2", "abc", policy, monday.Add(2*time.Minute))
+			Expect(changed).To(BeFalse())
+			Expect(restartAt).To(Equal(monday.Add(5 * time.Minute)))
+		})
+
+		It("Should wait for the restart window after the debounce", func() {
+			window, _ := restartPolicyFor(0, &metav1.ObjectMeta{Annotations: map[string]string{
+				restartWindowAnnotation:   "0 2 * * *",
+				restartDebounceAnnotation: "5m",
+			}})
+			restartAt, _ := pending.schedule("tls", "1", "abc", window, monday)
+			Expect(restartAt).To(Equal(time.Date(2020, 11, 2, 2, 0, 0, 0, time.Local)))
+		})
+
+		It("Should be recorded in the workload's annotations", func() {
+			pending.schedule("tls", "1", "abc", policy, monday)
+			annotations := map[string]string{}
+			setPendingRestart(pending, annotations)
+
+			recorded := getPendingRestart(annotations)
+			Expect(recorded.Secrets).To(Equal(pending.Secrets))
+			Expect(recorded.RestartAt.Time).To(BeTemporally("==", monday.Add(5*time.Minute)))
+		})
+
+		It("Should keep when each secret was first updated", func() {
+			pending.schedule("tls", "1", "abc", policy, monday)
+			pending.recordUpdate("tls", monday.Add(-time.Minute))
+			pending.recordUpdate("tls", monday)
+			pending.schedule("ca", "1", "def", policy, monday)
+			pending.recordUpdate("ca", monday.Add(-30*time.Second))
+
+			Expect(pending.Secrets["tls"].UpdatedAt.Time).To(Equal(monday.Add(-time.Minute)))
+			Expect(pending.firstUpdate(monday)).To(Equal(monday.Add(-time.Minute)))
+		})
+
+		It("Should use now as the first update if none is recorded", func() {
+			pending.schedule("tls", "1", "abc", policy, monday)
+			Expect(pending.firstUpdate(monday)).To(Equal(monday))
+		})
+	})
+})
@@ -0,0 +1,252 @@
+package podrefresher
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// WorkloadAdapter describes a kind of workload whose pods may consume secrets, and how
+// the workload is restarted so that its pods use a changed secret. Workloads are handled
+// as unstructured objects so that adapters can be written for kinds defined by CRDs.
+type WorkloadAdapter interface {
+	// GroupVersionKind returns the kind of workload handled by the adapter.
+	GroupVersionKind() schema.GroupVersionKind
+	// PodTemplate returns the pod template of the workload, and false if the workload
+	// does not have one.
+	PodTemplate(workload *unstructured.Unstructured) (corev1.PodTemplateSpec, bool, error)
+	// Restart modifies the workload so that its pods are replaced when it is updated, recording
+	// restartedAt on the workload. It returns false if the workload's pods pick up a changed
+	// secret without being restarted, in which case the workload is not modified.
+	Restart(workload *unstructured.Unstructured, restartedAt string) (bool, error)
+	// RolloutComplete returns true if the workload has finished rolling out its latest pod template.
+	RolloutComplete(workload *unstructured.Unstructured) (bool, error)
+}
+
+// podTemplateAdapter is a WorkloadAdapter for kinds that keep their pod template at a fixed
+// path in the object, and are restarted by changing a label in the pod template.
+type podTemplateAdapter struct {
+	gvk          schema.GroupVersionKind
+	templatePath []string
+	restarts     bool
+}
+
+// GroupVersionKind implements WorkloadAdapter.
+func (a podTemplateAdapter) GroupVersionKind() schema.GroupVersionKind {
+	return a.gvk
+}
+
+// PodTemplate implements WorkloadAdapter.
+func (a podTemplateAdapter) PodTemplate(workload *unstructured.Unstructured) (corev1.PodTemplateSpec, bool, error) {
+	var template corev1.PodTemplateSpec
+	raw, found, err := unstructured.NestedMap(workload.Object, a.templatePath...)
+	if err != nil || !found {
+		return template, false, err
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &template); err != nil {
+		return template, false, err
+	}
+
+	return template, true, nil
+}
+
+// Restart implements WorkloadAdapter by setting the timeRestartedLabel on the
+// workload and its pod template.
+func (a podTemplateAdapter) Restart(workload *unstructured.Unstructured, restartedAt string) (bool, error) {
+	if !a.restarts {
+		return false, nil
+	}
+
+	labelPath := append(append([]string{}, a.templatePath...), "metadata", "labels", timeRestartedLabel)
+	if err := unstructured.SetNestedField(workload.Object, restartedAt, labelPath...); err != nil {
+		return false, err
+	}
+
+	labels := workload.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[timeRestartedLabel] = restartedAt
+	workload.SetLabels(labels)
+
+	return true, nil
+}
+
+// replicaStatusFields are pairs of status fields of workload kinds that report the desired
+// number of pods, and the number of pods that are ready or updated.
+var replicaStatusFields = [][2]string{
+	{"replicas", "readyReplicas"},
+	{"replicas", "updatedReplicas"},
+	{"desiredNumberScheduled", "numberReady"},
+	{"desiredNumberScheduled", "updatedNumberScheduled"},
+}
+
+// observedGeneration returns the status.observedGeneration of a workload, whether the workload reports
+// one, and whether it can be compared to the generation. Argo Rollouts report it as a string, which
+// older releases set to a hash of the spec rather than the generation.
+func observedGeneration(workload *unstructured.Unstructured) (int64, bool, bool, error) {
+	value, found, err := unstructured.NestedFieldNoCopy(workload.Object, "status", "observedGeneration")
+	if err != nil || !found {
+		return 0, false, false, err
+	}
+
+	switch v := value.(type) {
+	case int64:
+		return v, true, true, nil
+	case string:
+		generation, err := strconv.ParseInt(v, 10, 64)
+		return generation, true, err == nil, nil
+	default:
+		return 0, true, false, fmt.Errorf("status.observedGeneration accessor error: %v is of the type %T, expected int64 or string", value, value)
+	}
+}
+
+// RolloutComplete implements WorkloadAdapter. The rollout is complete when the workload's
+// status.observedGeneration matches its generation, and every desired pod is ready and
+// updated. Workloads that do not report an observedGeneration are always complete, as
+// there is no way to tell that their rollout is in progress. Workloads whose observedGeneration
+// is not a generation are complete once every desired pod is ready and updated.
+func (a podTemplateAdapter) RolloutComplete(workload *unstructured.Unstructured) (bool, error) {
+	generation, found, comparable, err := observedGeneration(workload)
+	if err != nil {
+		return false, err
+	}
+
+	if !found {
+		return true, nil
+	}
+
+	if comparable && generation != workload.GetGeneration() {
+		return false, nil
+	}
+
+	for _, fields := range replicaStatusFields {
+		desired, found, err := unstructured.NestedInt64(workload.Object, "status", fields[0])
+		if err != nil || !found {
+			continue
+		}
+
+		// counts of zero are omitted from the status.
+		actual, _, _ := unstructured.NestedInt64(workload.Object, "status", fields[1])
+		if actual != desired {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// defaultWorkloadAdapters returns the adapters for the workload kinds supported by the pod refresher.
+// Kinds that are not served by the cluster are skipped when the controller is set up.
+func defaultWorkloadAdapters() []WorkloadAdapter {
+	return []WorkloadAdapter{
+		podTemplateAdapter{
+			gvk:          schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			templatePath: []string{"spec", "template"},
+			restarts:     true,
+		},
+		podTemplateAdapter{
+			gvk:          schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "DaemonSet"},
+			templatePath: []string{"spec", "template"},
+			restarts:     true,
+		},
+		podTemplateAdapter{
+			gvk:          schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+			templatePath: []string{"spec", "template"},
+			restarts:     true,
+		},
+		// CronJobs create new pods for each run, so they use a changed secret on their next run.
+		podTemplateAdapter{
+			gvk:          schema.GroupVersionKind{Group: "batch", Version: "v1beta1", Kind: "CronJob"},
+			templatePath: []string{"spec", "jobTemplate", "spec", "template"},
+			restarts:     false,
+		},
+		podTemplateAdapter{
+			gvk:          schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"},
+			templatePath: []string{"spec", "template"},
+			restarts:     true,
+		},
+		podTemplateAdapter{
+			gvk:          schema.GroupVersionKind{Group: "apps.openshift.io", Version: "v1", Kind: "DeploymentConfig"},
+			templatePath: []string{"spec", "template"},
+			restarts:     true,
+		},
+	}
+}
+
+// ParseWorkloadAdapter returns a WorkloadAdapter for a workload kind described in the format
+// group/version/Kind[:path.to.template]. The pod template path defaults to spec.template.
+// Workloads of the kind are restarted by changing a label in the pod template.
+func ParseWorkloadAdapter(s string) (WorkloadAdapter, error) {
+	kind, path := s, "spec.template"
+	if i := strings.Index(s, ":"); i >= 0 {
+		kind, path = s[:i], s[i+1:]
+	}
+
+	parts := strings.Split(kind, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("workload kind %q is not in the format group/version/Kind[:path.to.template]", s)
+	}
+
+	templatePath := strings.Split(path, ".")
+	for _, field := range templatePath {
+		if field == "" {
+			return nil, fmt.Errorf("workload kind %q has an invalid pod template path %q", s, path)
+		}
+	}
+
+	return podTemplateAdapter{
+		gvk:          schema.GroupVersionKind{Group: parts[0], Version: parts[1], Kind: parts[2]},
+		templatePath: templatePath,
+		restarts:     true,
+	}, nil
+}
+
+// workloadRegistry holds the adapters for the workload kinds handled by the pod refresher.
+type workloadRegistry struct {
+	adapters []WorkloadAdapter
+}
+
+// newWorkloadRegistry returns a registry of the default workload adapters and the extra adapters.
+// An extra adapter replaces a default adapter for the same kind.
+func newWorkloadRegistry(extra ...WorkloadAdapter) *workloadRegistry {
+	registry := &workloadRegistry{}
+	for _, adapter := range append(defaultWorkloadAdapters(), extra...) {
+		registry.register(adapter)
+	}
+
+	return registry
+}
+
+// register adds the adapter to the registry, replacing any adapter for the same kind.
+func (w *workloadRegistry) register(adapter WorkloadAdapter) {
+	for i, existing := range w.adapters {
+		if existing.GroupVersionKind() == adapter.GroupVersionKind() {
+			w.adapters[i] = adapter
+			return
+		}
+	}
+
+	w.adapters = append(w.adapters, adapter)
+}
+
+// newWorkload returns an empty workload of the adapter's kind.
+func newWorkload(adapter WorkloadAdapter) *unstructured.Unstructured {
+	workload := &unstructured.Unstructured{}
+	workload.SetGroupVersionKind(adapter.GroupVersionKind())
+	return workload
+}
+
+// newWorkloadList returns an empty list of workloads of the adapter's kind.
+func newWorkloadList(adapter WorkloadAdapter) *unstructured.UnstructuredList {
+	gvk := adapter.GroupVersionKind()
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	return list
+}
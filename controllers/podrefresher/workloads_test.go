@@ -0,0 +1,105 @@
+package podrefresher
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("Workload adapters", func() {
+	Context("When reading the pod template of a workload", func() {
+		It("Should find the pod template of a CronJob", func() {
+			template := deploymentUsingSecrets("ns", "web", "tls").Spec.Template
+			cronjob := &batchv1beta1.CronJob{}
+			cronjob.Spec.JobTemplate.Spec.Template = template
+
+			found, ok, err := adapterFor("CronJob").PodTemplate(toUnstructured(cronjob))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(found.Spec.Volumes).To(Equal(template.Spec.Volumes))
+		})
+
+		It("Should find the pod template of a CRD-based workload", func() {
+			rollout := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "argoproj.io/v1alpha1",
+				"kind":       "Rollout",
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"volumes": []interface{}{
+								map[string]interface{}{
+									"name":   "tls",
+									"secret": map[string]interface{}{"secretName": "tls"},
+								},
+							},
+						},
+					},
+				},
+			}}
+
+			found, ok, err := adapterFor("Rollout").PodTemplate(rollout)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(secretsReferencedBy(found.Spec)).To(HaveKey("tls"))
+		})
+	})
+
+	Context("When restarting a workload", func() {
+		It("Should label the workload and its pod template", func() {
+			workload := toUnstructured(&appsv1.Deployment{})
+			restarted, err := adapterFor("Deployment").Restart(workload, "2020-1-2.1504")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restarted).To(BeTrue())
+			Expect(workload.GetLabels()).To(HaveKeyWithValue(timeRestartedLabel, "2020-1-2.1504"))
+
+			label, _, _ := unstructured.NestedString(workload.Object, "spec", "template", "metadata", "labels", timeRestartedLabel)
+			Expect(label).To(Equal("2020-1-2.1504"))
+		})
+
+		It("Should not modify a CronJob", func() {
+			workload := toUnstructured(&batchv1beta1.CronJob{})
+			original := workload.DeepCopy()
+			restarted, err := adapterFor("CronJob").Restart(workload, "2020-1-2.1504")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restarted).To(BeFalse())
+			Expect(workload).To(Equal(original))
+		})
+	})
+
+	Context("When parsing a workload kind", func() {
+		It("Should default the pod template path", func() {
+			adapter, err := ParseWorkloadAdapter("example.com/v1/WebApp")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(adapter.GroupVersionKind()).To(Equal(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "WebApp"}))
+			Expect(adapter.(podTemplateAdapter).templatePath).To(Equal([]string{"spec", "template"}))
+		})
+
+		It("Should use the given pod template path", func() {
+			adapter, err := ParseWorkloadAdapter("example.com/v1/WebApp:spec.workload.template")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(adapter.(podTemplateAdapter).templatePath).To(Equal([]string{"spec", "workload", "template"}))
+		})
+
+		It("Should reject malformed kinds", func() {
+			for _, s := range []string{"WebApp", "v1/WebApp", "example.com/v1/", "example.com/v1/WebApp:spec..template"} {
+				_, err := ParseWorkloadAdapter(s)
+				Expect(err).To(HaveOccurred(), s)
+			}
+		})
+	})
+
+	Context("When registering extra workload kinds", func() {
+		It("Should add new kinds and replace default kinds", func() {
+			webapp, _ := ParseWorkloadAdapter("example.com/v1/WebApp")
+			deploy, _ := ParseWorkloadAdapter("apps/v1/Deployment:spec.other")
+			registry := newWorkloadRegistry(webapp, deploy)
+
+			Expect(registry.adapters).To(HaveLen(len(defaultWorkloadAdapters()) + 1))
+			Expect(registry.adapters).To(ContainElement(webapp))
+			Expect(registry.adapters[0]).To(Equal(deploy))
+		})
+	})
+})
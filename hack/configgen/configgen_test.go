@@ -0,0 +1,120 @@
+package main
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("parseHelp", func() {
+	help := `
+Some description of the binary.
+
+Usage:
+  controller [flags]
+
+Flags:
+      --cluster-resource-namespace string   Namespace to store resources owned by cluster scoped resources. (default "kube-system")
+      --enable-profiling                    Enable profiling for controller.
+      --feature-gates mapStringBool         A set of key=value pairs that describe feature gates
+                                            for alpha/experimental features.
+  -h, --help                                help for controller
+  -v, --v Level                             number for the log level verbosity
+`
+
+	It("Should parse flags, types, usages and defaults", func() {
+		flags, err := parseHelp(strings.NewReader(help))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(flags).To(Equal([]cliFlag{
+			{Name: "cluster-resource-namespace", Type: "string", Usage: "Namespace to store resources owned by cluster scoped resources.", Default: `"kube-system"`},
+			{Name: "enable-profiling", Usage: "Enable profiling for controller."},
+			{Name: "feature-gates", Type: "mapStringBool", Usage: "A set of key=value pairs that describe feature gates for alpha/experimental features."},
+			{Name: "v", Type: "Level", Usage: "number for the log level verbosity"},
+		}))
+	})
+
+	It("Should fail when no flags are present", func() {
+		_, err := parseHelp(strings.NewReader("Usage:\n  controller [flags]\n"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should fail when a flag is defined twice", func() {
+		_, err := parseHelp(strings.NewReader("      --v Level   one\n      --v Level   two\n"))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("fieldName", func() {
+	It("Should convert flag names to exported field names with initialisms", func() {
+		Expect(fieldName("acme-http01-solver-image")).To(Equal("ACMEHTTP01SolverImage"))
+		Expect(fieldName("kube-api-qps")).To(Equal("KubeAPIQPS"))
+		Expect(fieldName("log_file_max_size")).To(Equal("LogFileMaxSize"))
+		Expect(fieldName("v")).To(Equal("V"))
+	})
+})
+
+var _ = Describe("newComponentConfig", func() {
+	comp := component{name: "controller", typePrefix: "CertManagerController", defaultsFunc: "ConfigForController"}
+	flags := []cliFlag{
+		{Name: "v", Type: "Level"},
+		{Name: "leader-elect"},
+		{Name: "feature-gates", Type: "mapStringBool"},
+	}
+
+	It("Should map pflag types to Go types", func() {
+		cfg, err := newComponentConfig(comp, flags, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		gates, ok := cfg.fieldForFlag("feature-gates")
+		Expect(ok).To(BeTrue())
+		Expect(gates.Type.expr).To(Equal("map[string]bool"))
+
+		leaderElect, ok := cfg.fieldForFlag("leader-elect")
+		Expect(ok).To(BeTrue())
+		Expect(leaderElect.Type.expr).To(Equal("bool"))
+	})
+
+	It("Should fail on an unknown flag type", func() {
+		_, err := newComponentConfig(comp, []cliFlag{{Name: "foo", Type: "somethingNew"}}, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should fail when a default is set for a flag that does not exist", func() {
+		_, err := newComponentConfig(comp, flags, map[string]interface{}{"not-a-flag": true})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should fail when a default has the wrong type", func() {
+		_, err := newComponentConfig(comp, flags, map[string]interface{}{"leader-elect": float64(2)})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should allow string defaults for any flag to support environment variable references", func() {
+		_, err := newComponentConfig(comp, flags, map[string]interface{}{"v": "$(LOG_LEVEL)"})
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
+
+var _ = Describe("generator", func() {
+	It("Should render gofmt-compliant sources for every component", func() {
+		var configs []componentConfig
+		for _, comp := range components {
+			cfg, err := newComponentConfig(comp, []cliFlag{
+				{Name: "v", Type: "Level"},
+				{Name: "dns-names", Type: "strings"},
+				{Name: "lease-duration", Type: "duration"},
+			}, map[string]interface{}{"v": float64(2)})
+			Expect(err).ToNot(HaveOccurred())
+			configs = append(configs, cfg)
+		}
+
+		files, err := generator{version: "v0.0.0", configs: configs}.render()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(HaveKey("types/zz_generated.deepcopy.go"))
+		Expect(files).To(HaveKey("types/controller_types.go"))
+		Expect(files).To(HaveKey("defaults/defaults.go"))
+		Expect(string(files["types/zz_generated.deepcopy.go"])).To(ContainSubstring("*out = make([]string, len(*in))"))
+		Expect(string(files["defaults/defaults.go"])).To(ContainSubstring("  v: 2`"))
+	})
+})
@@ -0,0 +1,123 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command configgen generates the configuration types, deepcopy functions, and
+// defaults for a single version of cert-manager from the captured --help output of
+// each cert-manager component. It is invoked via go generate from the
+// controllers/configs package.
+//
+// The input directory is expected to contain controller.txt, webhook.txt, and
+// cainjector.txt (the output of running each binary with --help), as well as a
+// defaults.yaml containing the flags the operator passes to each component by default.
+//
+// Usage:
+//
+//	go run ./hack/configgen -flags hack/flags/v1.2.0 -out controllers/configs/v1_2_0
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+)
+
+// component describes a cert-manager binary for which configuration types are generated.
+type component struct {
+	// name is the component name as used by the configs package getters and in defaults.yaml.
+	name string
+	// typePrefix is prepended to Config and Flags to build the generated type names.
+	typePrefix string
+	// defaultsFunc is the name of the generated function returning the default configuration.
+	defaultsFunc string
+}
+
+var components = []component{
+	{name: "controller", typePrefix: "CertManagerController", defaultsFunc: "ConfigForController"},
+	{name: "webhook", typePrefix: "CertManagerWebhook", defaultsFunc: "ConfigForWebhook"},
+	{name: "cainjector", typePrefix: "CertManagerCAInjector", defaultsFunc: "ConfigForCAInjector"},
+}
+
+func main() {
+	var flagsDir, outDir string
+	flag.StringVar(&flagsDir, "flags", "", "Directory containing the captured --help output and defaults.yaml for a cert-manager version.")
+	flag.StringVar(&outDir, "out", "", "Directory in which the types and defaults packages are written.")
+	flag.Parse()
+
+	if flagsDir == "" || outDir == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flagsDir, outDir); err != nil {
+		fmt.Fprintf(os.Stderr, "configgen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// run reads the inputs from flagsDir and writes the generated packages to outDir.
+func run(flagsDir, outDir string) error {
+	version := filepath.Base(filepath.Clean(flagsDir))
+
+	rawDefaults, err := ioutil.ReadFile(filepath.Join(flagsDir, "defaults.yaml"))
+	if err != nil {
+		return err
+	}
+
+	var defaults map[string]map[string]interface{}
+	if err := yaml.Unmarshal(rawDefaults, &defaults); err != nil {
+		return fmt.Errorf("parsing defaults.yaml: %s", err)
+	}
+
+	gen := generator{version: version}
+	for _, comp := range components {
+		f, err := os.Open(filepath.Join(flagsDir, comp.name+".txt"))
+		if err != nil {
+			return err
+		}
+		flags, err := parseHelp(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("parsing %s.txt: %s", comp.name, err)
+		}
+
+		cfg, err := newComponentConfig(comp, flags, defaults[comp.name])
+		if err != nil {
+			return fmt.Errorf("component %s: %s", comp.name, err)
+		}
+		gen.configs = append(gen.configs, cfg)
+	}
+
+	files, err := gen.render()
+	if err != nil {
+		return err
+	}
+
+	for path, data := range files {
+		target := filepath.Join(outDir, path)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(target, data, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
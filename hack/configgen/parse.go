@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	// flagLinePattern matches a single flag as printed by pflag's FlagUsages, e.g.
+	//   -v, --v Level          number for the log level verbosity
+	//       --kubeconfig string   Paths to a kubeconfig.
+	// Boolean flags do not include a type.
+	flagLinePattern = regexp.MustCompile(`^\s+(?:-[A-Za-z0-9], )?--([A-Za-z0-9][A-Za-z0-9_.-]*)(?: ([A-Za-z0-9]+))?(?:\s{2,}(.*))?$`)
+
+	// defaultPattern matches the default value pflag appends to a flag's usage.
+	defaultPattern = regexp.MustCompile(`\s*\(default (.*)\)$`)
+)
+
+// helpFlag is the flag injected by cobra into every command. It is not configurable.
+const helpFlag = "help"
+
+// cliFlag is a single flag parsed from the --help output of a cert-manager component.
+type cliFlag struct {
+	// Name is the flag name without the leading dashes.
+	Name string
+	// Type is the pflag type name of the flag. Boolean flags have an empty type.
+	Type string
+	// Usage is the help text for the flag with the default value removed.
+	Usage string
+	// Default is the default value as printed by pflag, if any.
+	Default string
+}
+
+// parseHelp parses the output of a cobra/pflag command's --help and returns the
+// flags that were found in the order they appeared. Lines that are not indented are
+// treated as headings and ignored, as is the help flag. Indented lines that do not
+// start a new flag are treated as a continuation of the previous flag's usage.
+func parseHelp(r io.Reader) ([]cliFlag, error) {
+	var flags []cliFlag
+	seen := map[string]bool{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || !strings.HasPrefix(line, " ") {
+			continue
+		}
+
+		m := flagLinePattern.FindStringSubmatch(line)
+		if m == nil {
+			if len(flags) == 0 {
+				// indented text before any flags, such as the usage string.
+				continue
+			}
+			last := &flags[len(flags)-1]
+			last.Usage = strings.TrimSpace(last.Usage + " " + strings.TrimSpace(line))
+			continue
+		}
+
+		if seen[m[1]] {
+			return nil, fmt.Errorf("flag %q is defined more than once", m[1])
+		}
+		seen[m[1]] = true
+
+		flags = append(flags, cliFlag{Name: m[1], Type: m[2], Usage: strings.TrimSpace(m[3])})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// defaults are extracted after continuation lines have been joined.
+	res := make([]cliFlag, 0, len(flags))
+	for _, f := range flags {
+		if f.Name == helpFlag {
+			continue
+		}
+		if m := defaultPattern.FindStringSubmatch(f.Usage); m != nil {
+			f.Default = m[1]
+			f.Usage = strings.TrimSpace(defaultPattern.ReplaceAllString(f.Usage, ""))
+		}
+		res = append(res, f)
+	}
+
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no flags found")
+	}
+
+	return res, nil
+}
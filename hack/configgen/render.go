@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+// commentWidth is the width at which generated doc comments are wrapped.
+const commentWidth = 100
+
+// generator renders the generated files for all components of a single cert-manager version.
+type generator struct {
+	version string
+	configs []componentConfig
+}
+
+// render returns the formatted contents of each generated file keyed by its path
+// relative to the output directory.
+func (g generator) render() (map[string][]byte, error) {
+	files := map[string]string{}
+
+	files[filepath.Join("types", "groupversion_info.go")] = g.renderGroupVersionInfo()
+	for _, cfg := range g.configs {
+		files[filepath.Join("types", cfg.name+"_types.go")] = g.renderTypes(cfg)
+	}
+	files[filepath.Join("types", "zz_generated.deepcopy.go")] = g.renderDeepCopy()
+
+	defaults, err := g.renderDefaults()
+	if err != nil {
+		return nil, err
+	}
+	files[filepath.Join("defaults", "defaults.go")] = defaults
+
+	res := map[string][]byte{}
+	for path, src := range files {
+		formatted, err := format.Source([]byte(src))
+		if err != nil {
+			return nil, fmt.Errorf("formatting %s: %s\n%s", path, err, src)
+		}
+		res[path] = formatted
+	}
+
+	return res, nil
+}
+
+// header returns the generated code notice for a file produced from source.
+func (g generator) header(source string) string {
+	return fmt.Sprintf("// Code generated by hack/configgen from the cert-manager %s %s. DO NOT EDIT.\n\n", g.version, source)
+}
+
+func (g generator) renderGroupVersionInfo() string {
+	return g.header("flag definitions") + `// Package types contains the configuration types for the cert-manager ` + g.version + ` components.
+// The deepcopy functions for this package are produced by hack/configgen rather than controller-gen.
+// +kubebuilder:object:generate=false
+// +groupName=certmanagerconfigs.operators.redhat.io
+package types
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is group version used to register these objects
+	GroupVersion = schema.GroupVersion{Group: "certmanagerconfigs.operators.redhat.io", Version: "v1"}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+`
+}
+
+var typesTemplate = template.Must(template.New("types").Parse(`{{ .Header }}package types
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// {{ .Config.ConfigType }} is the configuration of the cert-manager {{ .Config.Name }} component.
+type {{ .Config.ConfigType }} struct {
+	metav1.TypeMeta ` + "`" + `json:",inline"` + "`" + `
+
+	Flags {{ .Config.FlagsType }} ` + "`" + `json:"flags"` + "`" + `
+}
+
+// {{ .Config.FlagsType }} contains the commandline flags accepted by the cert-manager {{ .Config.Name }} component.
+type {{ .Config.FlagsType }} struct {
+{{- range .Fields }}
+{{ .Comment }}
+	{{ .Name }} {{ .Type }} ` + "`" + `json:"{{ .Tag }}"` + "`" + `
+{{- end }}
+}
+`))
+
+type templateField struct {
+	Comment string
+	Name    string
+	Type    string
+	Tag     string
+}
+
+func (g generator) renderTypes(cfg componentConfig) string {
+	var fields []templateField
+	for _, f := range cfg.fields {
+		doc := fmt.Sprintf("%s maps to --%s. %s", f.Name, f.Flag.Name, describe(f.Flag))
+		fields = append(fields, templateField{
+			Comment: wrapComment(doc, "\t// "),
+			Name:    f.Name,
+			Type:    f.Type.expr,
+			Tag:     f.JSONTag,
+		})
+	}
+
+	var b bytes.Buffer
+	typesTemplate.Execute(&b, struct {
+		Header string
+		Config struct{ Name, ConfigType, FlagsType string }
+		Fields []templateField
+	}{
+		Header: g.header(cfg.name + " --help output"),
+		Config: struct{ Name, ConfigType, FlagsType string }{cfg.name, cfg.ConfigType(), cfg.FlagsType()},
+		Fields: fields,
+	})
+
+	return b.String()
+}
+
+// renderDeepCopy renders deepcopy functions equivalent to those produced by controller-gen
+// for every generated type in the package.
+func (g generator) renderDeepCopy() string {
+	type namedType struct {
+		name  string
+		write func(*strings.Builder)
+	}
+
+	var types []namedType
+	for _, cfg := range g.configs {
+		cfg := cfg
+		types = append(types, namedType{name: cfg.ConfigType(), write: func(b *strings.Builder) {
+			writeConfigDeepCopy(b, cfg)
+		}})
+		types = append(types, namedType{name: cfg.FlagsType(), write: func(b *strings.Builder) {
+			writeFlagsDeepCopy(b, cfg)
+		}})
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].name < types[j].name })
+
+	var b strings.Builder
+	b.WriteString(g.header("flag definitions"))
+	b.WriteString("package types\n\nimport (\n\truntime \"k8s.io/apimachinery/pkg/runtime\"\n)\n")
+	for _, t := range types {
+		t.write(&b)
+	}
+
+	return b.String()
+}
+
+func writeConfigDeepCopy(b *strings.Builder, cfg componentConfig) {
+	name := cfg.ConfigType()
+	flagsCopy := "out.Flags = in.Flags"
+	if cfg.flagsNeedDeepCopy() {
+		flagsCopy = "in.Flags.DeepCopyInto(&out.Flags)"
+	}
+
+	fmt.Fprintf(b, `
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *%[1]s) DeepCopyInto(out *%[1]s) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	%[2]s
+}
+`, name, flagsCopy)
+	writeDeepCopyFunc(b, name)
+	fmt.Fprintf(b, `
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *%[1]s) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+`, name)
+}
+
+func writeFlagsDeepCopy(b *strings.Builder, cfg componentConfig) {
+	name := cfg.FlagsType()
+	fmt.Fprintf(b, `
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *%[1]s) DeepCopyInto(out *%[1]s) {
+	*out = *in
+`, name)
+	for _, f := range cfg.fields {
+		switch f.Type.copy {
+		case copyStruct:
+			fmt.Fprintf(b, "\tout.%[1]s = in.%[1]s\n", f.Name)
+		case copySlice:
+			fmt.Fprintf(b, `	if in.%[1]s != nil {
+		in, out := &in.%[1]s, &out.%[1]s
+		*out = make(%[2]s, len(*in))
+		copy(*out, *in)
+	}
+`, f.Name, f.Type.expr)
+		case copyMap:
+			fmt.Fprintf(b, `	if in.%[1]s != nil {
+		in, out := &in.%[1]s, &out.%[1]s
+		*out = make(%[2]s, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+`, f.Name, f.Type.expr)
+		}
+	}
+	b.WriteString("}\n")
+	writeDeepCopyFunc(b, name)
+}
+
+func writeDeepCopyFunc(b *strings.Builder, name string) {
+	fmt.Fprintf(b, `
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new %[1]s.
+func (in *%[1]s) DeepCopy() *%[1]s {
+	if in == nil {
+		return nil
+	}
+	out := new(%[1]s)
+	in.DeepCopyInto(out)
+	return out
+}
+`, name)
+}
+
+// flagsNeedDeepCopy returns true if the flags type contains fields that
+// cannot be copied by assignment alone.
+func (c componentConfig) flagsNeedDeepCopy() bool {
+	for _, f := range c.fields {
+		if f.Type.copy == copySlice || f.Type.copy == copyMap {
+			return true
+		}
+	}
+	return false
+}
+
+// renderDefaults renders the defaults package, documenting each flag the operator sets
+// along with the upstream description and default of that flag.
+func (g generator) renderDefaults() (string, error) {
+	var b strings.Builder
+	b.WriteString(g.header("flag definitions and hack/flags/" + g.version + "/defaults.yaml"))
+	b.WriteString("package defaults\n")
+
+	for _, cfg := range g.configs {
+		flags, err := yaml.Marshal(cfg.defaults)
+		if err != nil {
+			return "", err
+		}
+		if len(cfg.defaults) == 0 {
+			flags = []byte("{}\n")
+		}
+
+		doc := []string{
+			fmt.Sprintf("// %s returns a default config for the %s component as a byte slice of YAML.", cfg.defaultsFunc, cfg.name),
+		}
+		if len(cfg.defaults) > 0 {
+			doc = append(doc, "//", "// The operator sets the following flags by default:", "//")
+		}
+		for _, key := range sortedKeys(cfg.defaults) {
+			f, _ := cfg.fieldForFlag(key)
+			doc = append(doc, "//   --"+key, wrapComment(describe(f.Flag), "//     "))
+		}
+
+		fmt.Fprintf(&b, "\n%s\nfunc %s() []byte {\n\treturn []byte(`apiVersion: %s\nkind: %s\nflags:\n%s`)\n}\n",
+			strings.Join(doc, "\n"),
+			cfg.defaultsFunc,
+			"certmanagerconfigs.operators.redhat.io/v1",
+			cfg.ConfigType(),
+			strings.TrimRight(indent(string(flags), "  "), "\n"),
+		)
+	}
+
+	return b.String(), nil
+}
+
+// describe returns the usage of f as a sentence, followed by its upstream default if it has one.
+func describe(f cliFlag) string {
+	usage := f.Usage
+	if !strings.HasSuffix(usage, ".") {
+		usage += "."
+	}
+	if f.Default != "" {
+		usage += fmt.Sprintf(" Upstream default: %s.", f.Default)
+	}
+	return usage
+}
+
+// wrapComment wraps text to commentWidth, prefixing each line with prefix.
+func wrapComment(text, prefix string) string {
+	var lines []string
+	line := prefix
+	for _, word := range strings.Fields(text) {
+		if line != prefix && len(line)+1+len(word) > commentWidth {
+			lines = append(lines, line)
+			line = prefix
+		}
+		if line != prefix {
+			line += " "
+		}
+		line += word
+	}
+	lines = append(lines, line)
+	return strings.Join(lines, "\n")
+}
+
+// indent prefixes every non-empty line of s with prefix.
+func indent(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = prefix + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// copyKind describes how a generated field must be handled by DeepCopyInto.
+type copyKind int
+
+const (
+	// copyValue fields are copied by the initial *out = *in assignment.
+	copyValue copyKind = iota
+	// copyStruct fields are value structs that are reassigned explicitly.
+	copyStruct
+	// copySlice fields require a new backing array.
+	copySlice
+	// copyMap fields require a new map.
+	copyMap
+)
+
+// goType is the Go representation of a pflag type.
+type goType struct {
+	expr string
+	copy copyKind
+	// elem is the element type for slices, or the value type for maps.
+	elem string
+	// yamlKind is used to sanity check values in defaults.yaml.
+	yamlKind string
+}
+
+// pflagTypes maps the pflag type names printed in --help output to Go types.
+// Types originating from klog are represented by their underlying or string
+// representation because klog does not export them in a usable way.
+var pflagTypes = map[string]goType{
+	"":                {expr: "bool", yamlKind: "bool"},
+	"bool":            {expr: "bool", yamlKind: "bool"},
+	"string":          {expr: "string", yamlKind: "string"},
+	"strings":         {expr: "[]string", copy: copySlice, elem: "string", yamlKind: "list"},
+	"stringSlice":     {expr: "[]string", copy: copySlice, elem: "string", yamlKind: "list"},
+	"stringArray":     {expr: "[]string", copy: copySlice, elem: "string", yamlKind: "list"},
+	"duration":        {expr: "metav1.Duration", copy: copyStruct, yamlKind: "string"},
+	"int":             {expr: "int", yamlKind: "number"},
+	"int32":           {expr: "int32", yamlKind: "number"},
+	"int64":           {expr: "int64", yamlKind: "number"},
+	"uint":            {expr: "uint", yamlKind: "number"},
+	"uint32":          {expr: "uint32", yamlKind: "number"},
+	"uint64":          {expr: "uint64", yamlKind: "number"},
+	"float32":         {expr: "float32", yamlKind: "number"},
+	"float":           {expr: "float64", yamlKind: "number"},
+	"float64":         {expr: "float64", yamlKind: "number"},
+	"mapStringBool":   {expr: "map[string]bool", copy: copyMap, elem: "bool", yamlKind: "map"},
+	"mapStringString": {expr: "map[string]string", copy: copyMap, elem: "string", yamlKind: "map"},
+	"stringToString":  {expr: "map[string]string", copy: copyMap, elem: "string", yamlKind: "map"},
+	// klog types
+	"Level":         {expr: "int32", yamlKind: "number"},
+	"severity":      {expr: "int32", yamlKind: "number"},
+	"moduleSpec":    {expr: "string", yamlKind: "string"},
+	"traceLocation": {expr: "string", yamlKind: "string"},
+}
+
+// initialisms are flag name segments that are rendered in upper case in field names.
+var initialisms = map[string]string{
+	"acme":   "ACME",
+	"api":    "API",
+	"ca":     "CA",
+	"cpu":    "CPU",
+	"dns":    "DNS",
+	"dns01":  "DNS01",
+	"http01": "HTTP01",
+	"id":     "ID",
+	"ip":     "IP",
+	"qps":    "QPS",
+	"stderr": "STDERR",
+	"tls":    "TLS",
+	"url":    "URL",
+}
+
+// field is a single generated struct field.
+type field struct {
+	Name    string
+	Type    goType
+	Flag    cliFlag
+	JSONTag string
+}
+
+// componentConfig is everything needed to render a single component's types and defaults.
+type componentConfig struct {
+	component
+	fields   []field
+	defaults map[string]interface{}
+}
+
+// ConfigType returns the name of the generated top-level configuration type.
+func (c componentConfig) ConfigType() string { return c.typePrefix + "Config" }
+
+// FlagsType returns the name of the generated flags type.
+func (c componentConfig) FlagsType() string { return c.typePrefix + "Flags" }
+
+// fieldForFlag returns the field for the flag with the given name, if any.
+func (c componentConfig) fieldForFlag(name string) (field, bool) {
+	for _, f := range c.fields {
+		if f.Flag.Name == name {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// newComponentConfig builds the componentConfig for comp, validating that every
+// flag has a known type and that all defaults refer to real flags with plausible values.
+func newComponentConfig(comp component, flags []cliFlag, defaults map[string]interface{}) (componentConfig, error) {
+	cfg := componentConfig{component: comp, defaults: defaults}
+	names := map[string]string{}
+
+	for _, f := range flags {
+		t, ok := pflagTypes[f.Type]
+		if !ok {
+			return cfg, fmt.Errorf("flag %q has unknown type %q; add it to pflagTypes", f.Name, f.Type)
+		}
+
+		name := fieldName(f.Name)
+		if other, exists := names[name]; exists {
+			return cfg, fmt.Errorf("flags %q and %q both produce field name %s", other, f.Name, name)
+		}
+		names[name] = f.Name
+
+		cfg.fields = append(cfg.fields, field{Name: name, Type: t, Flag: f, JSONTag: f.Name})
+	}
+
+	sort.Slice(cfg.fields, func(i, j int) bool { return cfg.fields[i].Flag.Name < cfg.fields[j].Flag.Name })
+
+	for _, key := range sortedKeys(defaults) {
+		f, ok := cfg.fieldForFlag(key)
+		if !ok {
+			return cfg, fmt.Errorf("defaults.yaml sets %q which is not a flag of this component", key)
+		}
+		if kind := yamlKindOf(defaults[key]); kind != f.Type.yamlKind {
+			// strings are allowed anywhere so that environment variable references such as
+			// $(POD_NAMESPACE) can be used.
+			if kind != "string" {
+				return cfg, fmt.Errorf("defaults.yaml sets %q to a %s but the flag expects a %s", key, kind, f.Type.yamlKind)
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// fieldName converts a flag name such as acme-http01-solver-image to a Go
+// field name such as ACMEHTTP01SolverImage.
+func fieldName(flagName string) string {
+	segments := strings.FieldsFunc(flagName, func(r rune) bool { return r == '-' || r == '_' || r == '.' })
+	var b strings.Builder
+	for _, s := range segments {
+		if v, ok := initialisms[strings.ToLower(s)]; ok {
+			b.WriteString(v)
+			continue
+		}
+		b.WriteString(strings.ToUpper(s[:1]) + s[1:])
+	}
+	return b.String()
+}
+
+// yamlKindOf returns the kind of a value decoded from defaults.yaml.
+func yamlKindOf(v interface{}) string {
+	switch v.(type) {
+	case bool:
+		return "bool"
+	case string:
+		return "string"
+	case float64, int, int64:
+		return "number"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -19,6 +19,8 @@ package main
 import (
 	"flag"
 	"os"
+	"strings"
+	"time"
 
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 
@@ -32,6 +34,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	operatorsv1alpha1 "github.com/komish/cmd-operator-dev/api/v1alpha1"
+	"github.com/komish/cmd-operator-dev/controllers/acmereaper"
 	"github.com/komish/cmd-operator-dev/controllers/certmanagerdeployment"
 	"github.com/komish/cmd-operator-dev/controllers/podrefresher"
 	// +kubebuilder:scaffold:imports
@@ -59,14 +62,59 @@ func init() {
 func main() {
 	controllerNamePodRefresher := "podrefresh-controller"
 	controllerNameCertManagerDeployment := "certmanagerdeployment-controller"
+	controllerNameACMEReaper := "acme-reaper"
 	var metricsAddr string
 	var enableLeaderElection bool
 	var enablePodRefreshController bool
+	var podRefresherExtraWorkloads string
+	var podRefresherFingerprintKeys string
+	var podRefresherRestartDebounce time.Duration
+	var podRefresherMaxConcurrentRestarts int
+	var podRefresherMaxConcurrentRestartsPerNamespace int
+	var podRefresherAudit bool
+	var podRefresherWatchConfigMaps bool
+	var podRefresherCertificateMetrics bool
+	var certificateDependencyReportAddr string
+	var podRefresherExpiryWarningWindow time.Duration
+	var enableACMEReaper bool
+	var acmeReaperMaxAge time.Duration
+	var acmeReaperDelete bool
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
-	flag.BoolVar(&enablePodRefreshController, "enable-pod-refresher", false, "Enables the Pod Refresher Controller.")
+	flag.BoolVar(&enablePodRefreshController, "enable-pod-refresher", false, "Enables the Pod Refresher Controller for the life of the operator. Otherwise, it is configured by spec.podRefresher of the CertManagerDeployment.")
+	flag.StringVar(&podRefresherExtraWorkloads, "pod-refresher-extra-workloads", "",
+		"Comma-separated workload kinds refreshed in addition to the defaults, in the format group/version/Kind[:path.to.template]. "+
+			"The manager must be granted list, watch, and update on these kinds.")
+	flag.StringVar(&podRefresherFingerprintKeys, "pod-refresher-fingerprint-keys", strings.Join(podrefresher.DefaultFingerprintKeys, ","),
+		"Comma-separated secret keys whose contents must change for the pod refresher to restart a workload.")
+	flag.DurationVar(&podRefresherRestartDebounce, "pod-refresher-restart-debounce", 0,
+		"How long the pod refresher waits after a secret changes before restarting the workloads using it, "+
+			"so that secrets changing together cause a single restart. Workloads and namespaces can override this with the "+
+			"certmanagerdeployment.redhat.io/restart-debounce annotation.")
+	flag.IntVar(&podRefresherMaxConcurrentRestarts, "pod-refresher-max-concurrent-restarts", 0,
+		"The maximum number of workload rollouts started by the pod refresher in progress across the cluster. Defaults to 0, which is unlimited.")
+	flag.IntVar(&podRefresherMaxConcurrentRestartsPerNamespace, "pod-refresher-max-concurrent-restarts-per-namespace", 0,
+		"The maximum number of workload rollouts started by the pod refresher in progress in a namespace. Defaults to 0, which is unlimited.")
+	flag.BoolVar(&podRefresherAudit, "pod-refresher-audit", false,
+		"Reports the workloads the pod refresher would restart with events and metrics, without restarting them.")
+	flag.BoolVar(&podRefresherWatchConfigMaps, "pod-refresher-watch-configmaps", false,
+		"Refreshes workloads when ConfigMaps they use that are labeled or selected by a PodRefreshPolicy change, such as CA trust bundles. "+
+			"Caches every ConfigMap in the cluster.")
+	flag.BoolVar(&podRefresherCertificateMetrics, "pod-refresher-certificate-metrics", false,
+		"Exports the validity, issuer and DNS names of the certificates in cert-manager issued secrets watched by the pod refresher as metrics.")
+	flag.DurationVar(&podRefresherExpiryWarningWindow, "pod-refresher-expiry-warning-window", 0,
+		"Records a Warning event once on each workload using a cert-manager issued secret when its certificate expires within this window, and once when it has expired. 0 disables the warnings.")
+	flag.BoolVar(&enableACMEReaper, "enable-acme-reaper", false,
+		"Enables the ACME reaper, which reports ACME Orders and Challenges that have failed or are retrying after errors.")
+	flag.DurationVar(&acmeReaperMaxAge, "acme-reaper-max-age", 24*time.Hour,
+		"How old an ACME Order or Challenge that has not succeeded must be for the ACME reaper to report or delete it.")
+	flag.BoolVar(&acmeReaperDelete, "acme-reaper-delete", false,
+		"Deletes the invalid or errored ACME Orders and Challenges reported by the ACME reaper.")
+	flag.StringVar(&certificateDependencyReportAddr, "certificate-dependency-report-addr", "",
+		"The address the JSON report of the workloads consuming each cert-manager issued secret binds to, served at "+podrefresher.DependencyReportPath+". "+
+			"The report is not authenticated, so bind it to an address only trusted clients can reach, such as 127.0.0.1:8081. Disabled if empty.")
 
 	flag.Parse()
 
@@ -84,31 +132,95 @@ func main() {
 		os.Exit(1)
 	}
 
+	var extraWorkloads []podrefresher.WorkloadAdapter
+	for _, kind := range strings.Split(podRefresherExtraWorkloads, ",") {
+		if kind == "" {
+			continue
+		}
+		adapter, err := podrefresher.ParseWorkloadAdapter(kind)
+		if err != nil {
+			setupLog.Error(err, "unable to parse pod refresher workload kind")
+			os.Exit(1)
+		}
+		extraWorkloads = append(extraWorkloads, adapter)
+	}
+
+	// newPodRefresher returns a pod refresher configured by the CLI that runs in the manager m.
+	newPodRefresher := func(m ctrl.Manager) *podrefresher.PodRefreshReconciler {
+		return &podrefresher.PodRefreshReconciler{
+			Client:                            m.GetClient(),
+			Log:                               ctrl.Log.WithName("controllers").WithName(controllerNamePodRefresher),
+			Scheme:                            m.GetScheme(),
+			EventRecorder:                     m.GetEventRecorderFor(controllerNamePodRefresher),
+			ExtraWorkloads:                    extraWorkloads,
+			FingerprintKeys:                   strings.Split(podRefresherFingerprintKeys, ","),
+			RestartDebounce:                   podRefresherRestartDebounce,
+			MaxConcurrentRestarts:             podRefresherMaxConcurrentRestarts,
+			MaxConcurrentRestartsPerNamespace: podRefresherMaxConcurrentRestartsPerNamespace,
+			Audit:                             podRefresherAudit,
+			WatchConfigMaps:                   podRefresherWatchConfigMaps,
+			CertificateMetrics:                podRefresherCertificateMetrics,
+			ExpiryWarningWindow:               podRefresherExpiryWarningWindow,
+		}
+	}
+
+	// The pod refresher controller was enabled via CLI, and runs for the life of the operator. Otherwise,
+	// it is started and stopped as configured by the CertManagerDeployment.
+	var podRefresherRunner certmanagerdeployment.PodRefresherRunner
+	if enablePodRefreshController {
+		setupLog.Info("Pod refresh controller is enabled")
+		if err = newPodRefresher(mgr).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", controllerNamePodRefresher)
+			os.Exit(1)
+		}
+	} else {
+		setupLog.Info("Pod refresh controller is managed by the CertManagerDeployment")
+		podRefresherRunner = &podrefresher.Runner{
+			Config:        mgr.GetConfig(),
+			Scheme:        mgr.GetScheme(),
+			Log:           ctrl.Log.WithName("controllers").WithName(controllerNamePodRefresher),
+			NewReconciler: newPodRefresher,
+		}
+	}
+
+	if certificateDependencyReportAddr != "" {
+		if err = mgr.Add(&podrefresher.DependencyReportServer{
+			Addr: certificateDependencyReportAddr,
+			Handler: &podrefresher.DependencyReportHandler{
+				Reader:         mgr.GetCache(),
+				Log:            ctrl.Log.WithName("certificate-dependency-report"),
+				ExtraWorkloads: extraWorkloads,
+			},
+		}); err != nil {
+			setupLog.Error(err, "unable to serve certificate dependency report")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&certmanagerdeployment.CertManagerDeploymentReconciler{
 		Client:        mgr.GetClient(),
 		Log:           ctrl.Log.WithName("controllers").WithName(controllerNameCertManagerDeployment),
 		Scheme:        mgr.GetScheme(),
 		EventRecorder: mgr.GetEventRecorderFor(controllerNameCertManagerDeployment),
+		PodRefresher:  podRefresherRunner,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CertManagerDeployment")
 		os.Exit(1)
 	}
-
-	// The pod refresher controller was enabled via CLI.
-	if enablePodRefreshController {
-		setupLog.Info("Pod refresh controller is enabled")
-		if err = (&podrefresher.PodRefreshReconciler{
+	// The ACME reaper was enabled via CLI.
+	if enableACMEReaper {
+		setupLog.Info("ACME reaper is enabled", "MaxAge", acmeReaperMaxAge.String(), "Delete", acmeReaperDelete)
+		if err = (&acmereaper.ACMEReaperReconciler{
 			Client:        mgr.GetClient(),
-			Log:           ctrl.Log.WithName("controllers").WithName(controllerNamePodRefresher),
+			Log:           ctrl.Log.WithName("controllers").WithName(controllerNameACMEReaper),
 			Scheme:        mgr.GetScheme(),
-			EventRecorder: mgr.GetEventRecorderFor(controllerNamePodRefresher),
+			EventRecorder: mgr.GetEventRecorderFor(controllerNameACMEReaper),
+			MaxAge:        acmeReaperMaxAge,
+			Delete:        acmeReaperDelete,
 		}).SetupWithManager(mgr); err != nil {
-			setupLog.Error(err, "unable to create controller", "controller", controllerNameCertManagerDeployment)
+			setupLog.Error(err, "unable to create controller", "controller", controllerNameACMEReaper)
 			os.Exit(1)
 		}
-	} else {
-		setupLog.Info("Pod refresh controller is disabled")
-
 	}
 	// +kubebuilder:scaffold:builder
 